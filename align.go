@@ -0,0 +1,50 @@
+package tlv
+
+import (
+	"fmt"
+	"io"
+)
+
+// ErrAlign is returned by an Encoder or Decoder whose Align is larger
+// than the largest supported alignment.
+var ErrAlign = fmt.Errorf("TLV alignment out of range")
+
+// maxAlign is the largest alignment supported by Encoder and Decoder.
+const maxAlign = 4096
+
+var zeroPad [maxAlign]byte
+
+// checkAlign returns ErrAlign if align is not supported. Alignments
+// of one or less mean no padding.
+func checkAlign(align int) error {
+	if align > maxAlign {
+		return ErrAlign
+	}
+	return nil
+}
+
+// padding returns the number of bytes needed after offset off to reach
+// a multiple of align, which has been checked with checkAlign.
+func padding(off int64, align int) int {
+	if align <= 1 {
+		return 0
+	}
+	return int((int64(align) - off%int64(align)) % int64(align))
+}
+
+// skipPadding consumes the padding after a record. A stream may end
+// without the final record's padding.
+func (dec *Decoder) skipPadding() error {
+	pad := padding(dec.r.n, dec.Align)
+	if pad == 0 {
+		return nil
+	}
+
+	n, err := io.CopyN(io.Discard, dec.r, int64(pad))
+	if err == io.EOF && n > 0 {
+		return ErrTLVRead
+	} else if err == io.EOF {
+		return nil
+	}
+	return err
+}
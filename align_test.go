@@ -0,0 +1,82 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestAlign(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(TagTest1, []byte("foo"))
+	tlvl.Add(TagTest2, nil)
+	tlvl.Add(TagTest3, []byte("gophers"))
+
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+	enc.Align = 4
+	if err := enc.EncodeList(tlvl); err != nil {
+		FailWithError(t, "TestAlign", err)
+	}
+
+	// 8+3 padded to 12, 8+0, then 8+7 padded to 16.
+	if buf.Len() != 36 {
+		FailWithError(t, "TestAlign",
+			fmt.Errorf("encoded %d bytes, expected 36", buf.Len()))
+	}
+	if !bytes.Equal(buf.Bytes()[11:12], []byte{0}) {
+		FailWithError(t, "TestAlign", fmt.Errorf("padding is not zero"))
+	}
+	_, length, _, err := ParseHeader(buf.Bytes())
+	if err != nil {
+		FailWithError(t, "TestAlign", err)
+	} else if length != 3 {
+		FailWithError(t, "TestAlign",
+			fmt.Errorf("length is %d, expected 3", length))
+	}
+
+	dec := NewDecoder(bytes.NewReader(buf.Bytes()))
+	dec.Align = 4
+	out, err := dec.DecodeList()
+	if err != nil {
+		FailWithError(t, "TestAlign", err)
+	} else if !CompareLists(out, tlvl, CompareRules{}).Equal() {
+		FailWithError(t, "TestAlign", noMatch)
+	}
+
+	// The final record's padding may be missing.
+	dec = NewDecoder(bytes.NewReader(buf.Bytes()[:buf.Len()-1]))
+	dec.Align = 4
+	if out, err = dec.DecodeList(); err != nil {
+		FailWithError(t, "TestAlign", err)
+	} else if out.Length() != 3 {
+		FailWithError(t, "TestAlign", noMatch)
+	}
+}
+
+func TestAlignRange(t *testing.T) {
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+	enc.Align = maxAlign + 1
+	if err := enc.Encode(newTLV(TagTest1, []byte("foo"))); err != ErrAlign {
+		FailWithError(t, "TestAlignRange",
+			fmt.Errorf("expected ErrAlign, got %v", err))
+	} else if buf.Len() != 0 {
+		FailWithError(t, "TestAlignRange",
+			fmt.Errorf("wrote %d bytes", buf.Len()))
+	}
+
+	enc.Align = maxAlign
+	if err := enc.Encode(newTLV(TagTest1, []byte("foo"))); err != nil {
+		FailWithError(t, "TestAlignRange", err)
+	} else if buf.Len() != maxAlign {
+		FailWithError(t, "TestAlignRange", noMatch)
+	}
+
+	dec := NewDecoder(bytes.NewReader(buf.Bytes()))
+	dec.Align = maxAlign + 1
+	if _, err := dec.Decode(); err != ErrAlign {
+		FailWithError(t, "TestAlignRange",
+			fmt.Errorf("expected ErrAlign, got %v", err))
+	}
+}
@@ -0,0 +1,193 @@
+package tlv
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrUnknownRange is returned when allocating from a range that has not
+// been declared; ErrRangeFull is returned when every tag in the range has
+// been allocated. ErrNotInRange is returned when reserving a tag that
+// does not lie within any declared range.
+var (
+	ErrUnknownRange = fmt.Errorf("tag range not declared")
+	ErrRangeFull    = fmt.Errorf("tag range exhausted")
+	ErrNotInRange   = fmt.Errorf("tag is outside the declared ranges")
+)
+
+// ErrLocked is returned when the lock on a schema file cannot be taken
+// within lockTimeout.
+var ErrLocked = fmt.Errorf("schema file is locked")
+
+// lockTimeout is how long an Allocator waits for another process to
+// release the lock on its schema file.
+const lockTimeout = 10 * time.Second
+
+// Type Allocator hands out tags from the ranges declared in a schema
+// file, recording each allocation in the file as it is made. Because the
+// schema file is the record of which tags are in use, allocations that
+// would collide with an existing tag or name are rejected. An Allocator
+// is safe for concurrent use, including by other processes sharing the
+// schema file: each allocation is made while holding a lock file next to
+// the schema file, named by adding ".lock" to its name, and from the
+// file's current contents. A lock file left behind by a process that
+// crashed must be removed by hand; until it is, allocations fail with
+// ErrLocked.
+type Allocator struct {
+	mu   sync.Mutex
+	path string
+	reg  *Registry
+}
+
+// OpenAllocator loads the schema file at path and returns an Allocator
+// for it.
+func OpenAllocator(path string) (*Allocator, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reg, err := ReadSchema(f)
+	if err != nil {
+		return nil, err
+	}
+	return &Allocator{path: path, reg: reg}, nil
+}
+
+// lock takes the lock on the schema file and reloads it, so that
+// allocations made by other processes are seen. The returned function
+// releases the lock.
+func (a *Allocator) lock() (unlock func(), err error) {
+	name := a.path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			f.Close()
+			break
+		} else if !os.IsExist(err) {
+			return nil, err
+		} else if time.Now().After(deadline) {
+			return nil, ErrLocked
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	unlock = func() { os.Remove(name) }
+
+	f, err := os.Open(a.path)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+	defer f.Close()
+
+	reg, err := ReadSchema(f)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+	a.reg = reg
+	return unlock, nil
+}
+
+// Allocate assigns name the lowest free tag in the named range, and
+// saves the allocation to the schema file.
+func (a *Allocator) Allocate(rangeName, name string) (tag int, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	unlock, err := a.lock()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	for _, r := range a.reg.ranges {
+		if r.Name != rangeName {
+			continue
+		}
+		for tag = r.Min; tag <= r.Max; tag++ {
+			if _, used := a.reg.names[tag]; !used {
+				return tag, a.register(tag, name)
+			}
+		}
+		return 0, ErrRangeFull
+	}
+	return 0, ErrUnknownRange
+}
+
+// Reserve assigns name a specific tag, which must lie within one of the
+// declared ranges, and saves the allocation to the schema file. It is
+// intended for recording tags that are already in use.
+func (a *Allocator) Reserve(tag int, name string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	unlock, err := a.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for _, r := range a.reg.ranges {
+		if r.Contains(tag) {
+			return a.register(tag, name)
+		}
+	}
+	return ErrNotInRange
+}
+
+func (a *Allocator) register(tag int, name string) error {
+	if err := a.reg.Register(tag, name); err != nil {
+		return err
+	}
+
+	if err := a.save(); err != nil {
+		delete(a.reg.names, tag)
+		delete(a.reg.tags, name)
+		return err
+	}
+	return nil
+}
+
+// save replaces the schema file, writing the new contents to a
+// temporary file first so that a failed write cannot lose allocations.
+// The file is left readable by all, as schema files are shared.
+func (a *Allocator) save() error {
+	tmp, err := ioutil.TempFile(filepath.Dir(a.path), ".schema")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err = tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	} else if err = a.reg.WriteSchema(tmp); err != nil {
+		tmp.Close()
+		return err
+	} else if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), a.path)
+}
+
+// Registry returns a copy of the registry in the schema file, reloaded
+// under the lock so that it reflects the allocations made so far by any
+// process. The copy shares nothing with the Allocator.
+func (a *Allocator) Registry() (*Registry, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	unlock, err := a.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return a.reg.clone(), nil
+}
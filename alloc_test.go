@@ -0,0 +1,160 @@
+package tlv
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestAllocator(t *testing.T) {
+	reg := NewRegistry()
+	reg.DeclareRange(Range{"core", 1, 3})
+	reg.DeclareRange(Range{"vendor", 100, 199})
+	reg.Register(2, "existing")
+
+	tmpFile, err := ioutil.TempFile("", "tlv_schema_")
+	if err != nil {
+		FailWithError(t, "TestAllocator", err)
+	}
+	tmpName := tmpFile.Name()
+	defer os.Remove(tmpName)
+	if err = reg.WriteSchema(tmpFile); err != nil {
+		FailWithError(t, "TestAllocator", err)
+	}
+	tmpFile.Close()
+
+	alloc, err := OpenAllocator(tmpName)
+	if err != nil {
+		FailWithError(t, "TestAllocator", err)
+	}
+
+	if tag, err := alloc.Allocate("core", "first"); err != nil {
+		FailWithError(t, "TestAllocator", err)
+	} else if tag != 1 {
+		FailWithError(t, "TestAllocator",
+			fmt.Errorf("allocated tag %d, expected 1", tag))
+	}
+	if tag, err := alloc.Allocate("core", "second"); err != nil {
+		FailWithError(t, "TestAllocator", err)
+	} else if tag != 3 {
+		FailWithError(t, "TestAllocator",
+			fmt.Errorf("allocated tag %d, expected 3", tag))
+	}
+	if _, err = alloc.Allocate("core", "third"); err != ErrRangeFull {
+		FailWithError(t, "TestAllocator",
+			fmt.Errorf("full range should not allocate"))
+	}
+	if _, err = alloc.Allocate("vendor", "first"); err != ErrDuplicateName {
+		FailWithError(t, "TestAllocator",
+			fmt.Errorf("duplicate name should not allocate"))
+	}
+	if _, err = alloc.Allocate("experimental", "x"); err != ErrUnknownRange {
+		FailWithError(t, "TestAllocator",
+			fmt.Errorf("undeclared range should not allocate"))
+	}
+	if err = alloc.Reserve(150, "acme-serial"); err != nil {
+		FailWithError(t, "TestAllocator", err)
+	}
+	if err = alloc.Reserve(150, "acme-model"); err != ErrDuplicateTag {
+		FailWithError(t, "TestAllocator",
+			fmt.Errorf("reserved tag should not be reused"))
+	}
+	if err = alloc.Reserve(500, "stray"); err != ErrNotInRange {
+		FailWithError(t, "TestAllocator",
+			fmt.Errorf("tag outside ranges should not be reserved"))
+	}
+
+	// The allocations must have been saved to the schema file.
+	reopened, err := OpenAllocator(tmpName)
+	if err != nil {
+		FailWithError(t, "TestAllocator", err)
+	}
+	saved, err := reopened.Registry()
+	if err != nil {
+		FailWithError(t, "TestAllocator", err)
+	}
+	for _, name := range []string{"existing", "first", "second", "acme-serial"} {
+		if _, ok := saved.Lookup(name); !ok {
+			FailWithError(t, "TestAllocator",
+				fmt.Errorf("allocation of %s was not saved", name))
+		}
+	}
+}
+
+func TestAllocatorShared(t *testing.T) {
+	reg := NewRegistry()
+	reg.DeclareRange(Range{"core", 1, 10})
+	reg.Register(10, "mode")
+	reg.SetDescription(10, "Operating mode")
+	reg.SetKind(10, "u8")
+	reg.SetEnum(10, map[byte]string{1: "on"})
+	reg.Constrain(10, Constraint{Required: true})
+
+	tmpFile, err := ioutil.TempFile("", "tlv_schema_")
+	if err != nil {
+		FailWithError(t, "TestAllocatorShared", err)
+	}
+	tmpName := tmpFile.Name()
+	defer os.Remove(tmpName)
+	if err = reg.WriteSchema(tmpFile); err != nil {
+		FailWithError(t, "TestAllocatorShared", err)
+	}
+	tmpFile.Close()
+
+	// Two Allocators, as if in two processes, must see each
+	// other's allocations.
+	first, err := OpenAllocator(tmpName)
+	if err != nil {
+		FailWithError(t, "TestAllocatorShared", err)
+	}
+	second, err := OpenAllocator(tmpName)
+	if err != nil {
+		FailWithError(t, "TestAllocatorShared", err)
+	}
+	if tag, err := first.Allocate("core", "a"); err != nil || tag != 1 {
+		FailWithError(t, "TestAllocatorShared",
+			fmt.Errorf("allocated tag %d (%v), expected 1", tag, err))
+	}
+	if tag, err := second.Allocate("core", "b"); err != nil || tag != 2 {
+		FailWithError(t, "TestAllocatorShared",
+			fmt.Errorf("allocated tag %d (%v), expected 2", tag, err))
+	}
+	if _, err = first.Allocate("core", "b"); err != ErrDuplicateName {
+		FailWithError(t, "TestAllocatorShared",
+			fmt.Errorf("expected ErrDuplicateName, got %v", err))
+	}
+
+	// The second Allocator's registry is reloaded, and is a full
+	// copy of the schema.
+	saved, err := second.Registry()
+	if err != nil {
+		FailWithError(t, "TestAllocatorShared", err)
+	} else if tag, ok := saved.Lookup("b"); !ok || tag != 2 {
+		FailWithError(t, "TestAllocatorShared", noMatch)
+	} else if desc, _ := saved.Description(10); desc != "Operating mode" {
+		FailWithError(t, "TestAllocatorShared", noMatch)
+	} else if saved.Kind(10) != "u8" || !saved.Constraint(10).Required {
+		FailWithError(t, "TestAllocatorShared", noMatch)
+	} else if name, err := saved.EnumName(10, 1); err != nil || name != "on" {
+		FailWithError(t, "TestAllocatorShared", noMatch)
+	}
+	saved.SetDescription(10, "changed")
+	if again, err := second.Registry(); err != nil {
+		FailWithError(t, "TestAllocatorShared", err)
+	} else if desc, _ := again.Description(10); desc != "Operating mode" {
+		FailWithError(t, "TestAllocatorShared",
+			fmt.Errorf("change to the copy reached the Allocator"))
+	}
+
+	if fi, err := os.Stat(tmpName); err != nil {
+		FailWithError(t, "TestAllocatorShared", err)
+	} else if fi.Mode().Perm() != 0644 {
+		FailWithError(t, "TestAllocatorShared",
+			fmt.Errorf("schema file has mode %v", fi.Mode().Perm()))
+	}
+	if _, err = os.Stat(tmpName + ".lock"); !os.IsNotExist(err) {
+		FailWithError(t, "TestAllocatorShared",
+			fmt.Errorf("lock file was not removed"))
+	}
+}
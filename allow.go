@@ -0,0 +1,52 @@
+package tlv
+
+// Type UnknownPolicy determines what a Decoder with an allowlist does
+// with records whose tags are not allowed.
+type UnknownPolicy int
+
+const (
+	// UnknownReject causes Decode to fail with ErrUnknownTag.
+	UnknownReject UnknownPolicy = iota
+
+	// UnknownCollect causes the record to be set aside, where it can
+	// be retrieved with the Decoder's Collected method, and decoding to
+	// continue with the next record.
+	UnknownCollect
+)
+
+// Allowed reports whether tag is registered or lies within one of the
+// registry's ranges.
+func (reg *Registry) Allowed(tag int) bool {
+	if _, ok := reg.names[tag]; ok {
+		return true
+	}
+	for _, r := range reg.ranges {
+		if r.Contains(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func (dec *Decoder) checkAllowed(rec TLV) error {
+	if (isControlTag(rec.Tag()) && !dec.Legacy) || dec.Allow.Allowed(rec.Tag()) {
+		return nil
+	} else if dec.Unknown != UnknownCollect {
+		return ErrUnknownTag
+	}
+
+	if dec.unknown == nil {
+		dec.unknown = New()
+	}
+	dec.unknown.records = append(dec.unknown.records, rec)
+	return errSkip
+}
+
+// Collected returns the records set aside by the Decoder because their
+// tags were not allowed.
+func (dec *Decoder) Collected() *TLVList {
+	if dec.unknown == nil {
+		return New()
+	}
+	return dec.unknown
+}
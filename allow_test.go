@@ -0,0 +1,46 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestAllow(t *testing.T) {
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+	for _, rec := range []TLV{
+		newTLV(TagTest1, []byte("known")),
+		newTLV(TagTest5, []byte("unknown")),
+		Tombstone(TagTest2),
+		newTLV(150, []byte("vendor")),
+		newTLV(TagTest3, []byte("known")),
+		newTLV(ReservedTagMax, []byte("unassigned")),
+	} {
+		if err := enc.Encode(rec); err != nil {
+			FailWithError(t, "TestAllow", err)
+		}
+	}
+	raw := buf.Bytes()
+
+	reg := testRegistry()
+	reg.DeclareRange(Range{"vendor", 100, 199})
+
+	dec := NewDecoder(bytes.NewReader(raw))
+	dec.Allow = reg
+	tlvl, err := dec.DecodeList()
+	if err != ErrUnknownTag {
+		FailWithError(t, "TestAllow",
+			fmt.Errorf("expected ErrUnknownTag, got %v", err))
+	}
+	checkTags(t, "TestAllow", tlvl, TagTest1)
+
+	dec = NewDecoder(bytes.NewReader(raw))
+	dec.Allow = reg
+	dec.Unknown = UnknownCollect
+	if tlvl, err = dec.DecodeList(); err != nil {
+		FailWithError(t, "TestAllow", err)
+	}
+	checkTags(t, "TestAllow", tlvl, TagTest1, TagTombstone, 150, TagTest3)
+	checkTags(t, "TestAllow", dec.Collected(), TagTest5, ReservedTagMax)
+}
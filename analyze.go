@@ -0,0 +1,112 @@
+package tlv
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// ErrOutlier is returned by Analyzer.Check when a record's length is far
+// outside the lengths previously seen for its tag.
+var ErrOutlier = fmt.Errorf("TLV record length is an outlier")
+
+// Type TagStats summarises the lengths of the records seen with a tag.
+// Buckets is a histogram of lengths by power of two: Buckets[0] counts
+// empty values, and Buckets[i] counts lengths in [2^(i-1), 2^i).
+type TagStats struct {
+	Count    int
+	Min, Max int
+	Mean     float64
+	StdDev   float64
+	Buckets  [65]int
+	Outliers int
+
+	m2 float64
+}
+
+func (ts *TagStats) add(length int) {
+	if ts.Count == 0 || length < ts.Min {
+		ts.Min = length
+	}
+	if length > ts.Max {
+		ts.Max = length
+	}
+	ts.Buckets[bits.Len64(uint64(length))]++
+
+	// Welford's method keeps the running variance numerically stable.
+	ts.Count++
+	delta := float64(length) - ts.Mean
+	ts.Mean += delta / float64(ts.Count)
+	ts.m2 += delta * (float64(length) - ts.Mean)
+	ts.StdDev = math.Sqrt(ts.m2 / float64(ts.Count))
+}
+
+// Type Analyzer builds per-tag histograms of the lengths of the records
+// in a stream and flags outliers: records whose lengths lie outside the
+// range seen so far for their tag, and more than Threshold standard
+// deviations from the mean. Outliers are counted but not added to the
+// histograms, so a misbehaving producer cannot widen the bounds it is
+// checked against.
+type Analyzer struct {
+	// Threshold is the number of standard deviations from the mean
+	// beyond which a length is an outlier. If zero, 4 is used.
+	Threshold float64
+
+	// MinSamples is the number of records that must be seen with a
+	// tag before its records can be flagged. If zero, 30 is used.
+	MinSamples int
+
+	stats map[int]*TagStats
+}
+
+// Observe adds a record to the analysis, reporting whether it is an
+// outlier.
+func (a *Analyzer) Observe(rec TLV) (outlier bool) {
+	if a.stats == nil {
+		a.stats = make(map[int]*TagStats)
+	}
+	ts := a.stats[rec.Tag()]
+	if ts == nil {
+		ts = new(TagStats)
+		a.stats[rec.Tag()] = ts
+	}
+
+	threshold, minSamples := a.Threshold, a.MinSamples
+	if threshold == 0 {
+		threshold = 4
+	}
+	if minSamples == 0 {
+		minSamples = 30
+	}
+
+	length := rec.Length()
+	if ts.Count >= minSamples && (length < ts.Min || length > ts.Max) &&
+		math.Abs(float64(length)-ts.Mean) > threshold*ts.StdDev {
+		ts.Outliers++
+		return true
+	}
+	ts.add(length)
+	return false
+}
+
+// Check observes a record, returning ErrOutlier if it is an outlier.
+func (a *Analyzer) Check(rec TLV) error {
+	if a.Observe(rec) {
+		return ErrOutlier
+	}
+	return nil
+}
+
+// Stats returns the statistics gathered for tag.
+func (a *Analyzer) Stats(tag int) (stats TagStats, ok bool) {
+	ts, ok := a.stats[tag]
+	if !ok {
+		return
+	}
+	return *ts, true
+}
+
+// Tags returns the number of distinct tags observed.
+func (a *Analyzer) Tags() int {
+	return len(a.stats)
+}
@@ -0,0 +1,46 @@
+package tlv
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestAnalyzer(t *testing.T) {
+	a := new(Analyzer)
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		rec := newTLV(TagTest1, make([]byte, 90+rnd.Intn(20)))
+		if err := a.Check(rec); err != nil {
+			FailWithError(t, "TestAnalyzer", err)
+		}
+	}
+
+	if err := a.Check(newTLV(TagTest1, make([]byte, 5000))); err != ErrOutlier {
+		FailWithError(t, "TestAnalyzer",
+			fmt.Errorf("expected ErrOutlier, got %v", err))
+	} else if err = a.Check(newTLV(TagTest1, make([]byte, 100))); err != nil {
+		FailWithError(t, "TestAnalyzer", err)
+	}
+
+	// A new tag has no history, so nothing about it is an outlier yet.
+	if err := a.Check(newTLV(TagTest2, make([]byte, 5000))); err != nil {
+		FailWithError(t, "TestAnalyzer", err)
+	}
+
+	stats, ok := a.Stats(TagTest1)
+	if !ok {
+		FailWithError(t, "TestAnalyzer", ErrTagNotFound)
+	} else if stats.Count != 1001 || stats.Outliers != 1 || stats.Max >= 110 {
+		FailWithError(t, "TestAnalyzer",
+			fmt.Errorf("bad stats: %+v", stats))
+	} else if stats.Buckets[7] != 1001 {
+		FailWithError(t, "TestAnalyzer",
+			fmt.Errorf("lengths should all fall in [64, 128)"))
+	} else if stats.Mean < 95 || stats.Mean > 105 {
+		FailWithError(t, "TestAnalyzer",
+			fmt.Errorf("bad mean %f", stats.Mean))
+	} else if a.Tags() != 2 {
+		FailWithError(t, "TestAnalyzer", noMatch)
+	}
+}
@@ -0,0 +1,111 @@
+package tlv
+
+import (
+	"encoding/base64"
+	"io"
+)
+
+// armorLine is the length of the lines written by NewArmorWriter, as
+// used by MIME.
+const armorLine = 76
+
+// lineWriter breaks the text written through it into lines.
+type lineWriter struct {
+	w   io.Writer
+	col int
+}
+
+func (lw *lineWriter) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > armorLine-lw.col {
+			chunk = chunk[:armorLine-lw.col]
+		}
+		var m int
+		m, err = lw.w.Write(chunk)
+		n += m
+		lw.col += m
+		if err != nil {
+			return
+		}
+		p = p[m:]
+
+		if lw.col == armorLine {
+			if _, err = lw.w.Write([]byte{'\n'}); err != nil {
+				return
+			}
+			lw.col = 0
+		}
+	}
+	return
+}
+
+type armorWriter struct {
+	enc io.WriteCloser
+	lw  *lineWriter
+}
+
+func (aw *armorWriter) Write(p []byte) (int, error) {
+	return aw.enc.Write(p)
+}
+
+func (aw *armorWriter) Close() error {
+	if err := aw.enc.Close(); err != nil {
+		return err
+	} else if aw.lw.col > 0 {
+		_, err = aw.lw.w.Write([]byte{'\n'})
+		return err
+	}
+	return nil
+}
+
+// NewArmorWriter returns a writer that encodes the bytes written to it
+// as standard base64 on w, in lines of 76 characters. It must be closed
+// to flush the final partial block. An Encoder can be layered on top of
+// it to stream records over a text-only channel.
+func NewArmorWriter(w io.Writer) io.WriteCloser {
+	lw := &lineWriter{w: w}
+	return &armorWriter{enc: base64.NewEncoder(base64.StdEncoding, lw), lw: lw}
+}
+
+// spaceSkipper drops whitespace from the text read through it.
+type spaceSkipper struct {
+	r io.Reader
+}
+
+func (ss spaceSkipper) Read(p []byte) (n int, err error) {
+	for n == 0 && err == nil {
+		var m int
+		m, err = ss.r.Read(p)
+		for _, c := range p[:m] {
+			if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+				p[n] = c
+				n++
+			}
+		}
+	}
+	return
+}
+
+// NewArmorReader returns a reader that decodes standard base64 read from
+// r, ignoring line breaks and other whitespace. A Decoder can be layered
+// on top of it to read records streamed with NewArmorWriter.
+func NewArmorReader(r io.Reader) io.Reader {
+	return base64.NewDecoder(base64.StdEncoding, spaceSkipper{r})
+}
+
+// WriteArmored writes the TLVList to w as base64 text.
+func WriteArmored(w io.Writer, recs *TLVList) error {
+	aw := NewArmorWriter(w)
+	if err := recs.Write(aw); err != nil {
+		return err
+	}
+	return aw.Close()
+}
+
+// ReadArmored reads a TLVList written as base64 text by WriteArmored.
+func ReadArmored(r io.Reader) (*TLVList, error) {
+	// The base64 decoder returns short reads, so the list is read with
+	// a Decoder rather than with Read.
+	return NewDecoder(NewArmorReader(r)).DecodeList()
+}
@@ -0,0 +1,60 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestArmor(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(TagTest1, []byte("gophers are everywhere!"))
+	tlvl.Add(TagTest2, make([]byte, 100))
+
+	buf := new(bytes.Buffer)
+	if err := WriteArmored(buf, tlvl); err != nil {
+		FailWithError(t, "TestArmor", err)
+	}
+	text := buf.String()
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		if len(line) > 76 {
+			FailWithError(t, "TestArmor",
+				fmt.Errorf("line too long: %d", len(line)))
+		}
+	}
+
+	rtlvl, err := ReadArmored(strings.NewReader(text))
+	if err != nil {
+		FailWithError(t, "TestArmor", err)
+	} else if CompareOrder(tlvl, rtlvl) != -1 {
+		FailWithError(t, "TestArmor", noMatch)
+	} else if rec, _ := rtlvl.Get(TagTest1); string(rec.Value()) != "gophers are everywhere!" {
+		FailWithError(t, "TestArmor", noMatch)
+	}
+
+	// Reads split at arbitrary points, such as in the middle of a line
+	// break, must not matter.
+	rtlvl, err = ReadArmored(&oneByteReader{strings.NewReader(strings.Replace(text, "\n", "\r\n", -1))})
+	if err != nil {
+		FailWithError(t, "TestArmor", err)
+	} else if rtlvl.Length() != 2 {
+		FailWithError(t, "TestArmor", noMatch)
+	}
+
+	if _, err = ReadArmored(strings.NewReader("not*base64")); err == nil {
+		FailWithError(t, "TestArmor",
+			fmt.Errorf("invalid base64 should fail"))
+	}
+}
+
+type oneByteReader struct {
+	r *strings.Reader
+}
+
+func (obr *oneByteReader) Read(p []byte) (int, error) {
+	if len(p) > 1 {
+		p = p[:1]
+	}
+	return obr.r.Read(p)
+}
@@ -0,0 +1,54 @@
+package tlv
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+// TagTimestamp is the tag of a timestamp record. In an append-only log,
+// a timestamp record marks the time at which the records following it,
+// up to the next timestamp record, were written. Its value is the time
+// as a 64-bit big-endian count of nanoseconds since the Unix epoch.
+const TagTimestamp = 0x7fffff06
+
+// Timestamp returns a timestamp record for t.
+func Timestamp(t time.Time) TLV {
+	var value [8]byte
+	binary.BigEndian.PutUint64(value[:], uint64(t.UnixNano()))
+	return newTLV(TagTimestamp, value[:])
+}
+
+// IsTimestamp reports whether rec is a timestamp record, and if so,
+// returns the time it holds.
+func IsTimestamp(rec TLV) (t time.Time, ok bool) {
+	if rec.Tag() != TagTimestamp || rec.Length() != 8 {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(binary.BigEndian.Uint64(rec.Value()))), true
+}
+
+// ReadAsOf decodes an append-only log of records, tombstones, and
+// timestamp records, returning the state of the log as of t: the
+// records written at or before t that had not been deleted by then.
+// Records preceding the first timestamp record are taken to have been
+// written before any time. Decoding stops at the first timestamp after
+// t, so timestamps must not decrease through the log.
+func (dec *Decoder) ReadAsOf(t time.Time) (recs *TLVList, err error) {
+	recs = New()
+	for {
+		var tlv TLV
+		if tlv, err = dec.Decode(); err != nil {
+			break
+		}
+
+		if ts, ok := IsTimestamp(tlv); ok {
+			if ts.After(t) {
+				break
+			}
+			continue
+		}
+		recs.records = append(recs.records, tlv)
+	}
+
+	return recs.Compact(), eof(err)
+}
@@ -0,0 +1,50 @@
+package tlv
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestReadAsOf(t *testing.T) {
+	day := 24 * time.Hour
+	start := time.Date(2013, 6, 25, 0, 0, 0, 0, time.UTC)
+
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+	for _, rec := range []TLV{
+		newTLV(TagTest1, []byte("initial")),
+		Timestamp(start),
+		newTLV(TagTest2, []byte("monday")),
+		Timestamp(start.Add(day)),
+		Tombstone(TagTest1),
+		newTLV(TagTest3, []byte("tuesday")),
+		Timestamp(start.Add(2 * day)),
+		newTLV(TagTest1, []byte("restored")),
+	} {
+		if err := enc.Encode(rec); err != nil {
+			FailWithError(t, "TestReadAsOf", err)
+		}
+	}
+	log := buf.Bytes()
+
+	for _, tc := range []struct {
+		at   time.Time
+		tags []int
+	}{
+		{start.Add(-day), []int{TagTest1}},
+		{start, []int{TagTest1, TagTest2}},
+		{start.Add(day + time.Hour), []int{TagTest2, TagTest3}},
+		{start.Add(3 * day), []int{TagTest2, TagTest3, TagTest1}},
+	} {
+		recs, err := NewDecoder(bytes.NewReader(log)).ReadAsOf(tc.at)
+		if err != nil {
+			FailWithError(t, "TestReadAsOf", err)
+		}
+		checkTags(t, "TestReadAsOf", recs, tc.tags...)
+	}
+
+	if ts, ok := IsTimestamp(Timestamp(start)); !ok || !ts.Equal(start) {
+		FailWithError(t, "TestReadAsOf", noMatch)
+	}
+}
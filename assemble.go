@@ -0,0 +1,119 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrStalled is returned by an Assembler when a partial record has not
+// been completed within its Timeout.
+var ErrStalled = fmt.Errorf("TLV record not completed in time")
+
+// Type Assembler builds records from data arriving in arbitrary pieces,
+// as from nonblocking reads in an event loop, so that a server does not
+// need a goroutine per connection blocked waiting for the rest of a
+// record. Bytes are passed to Push as they arrive, and each complete
+// record is returned as soon as its last byte has been pushed.
+//
+// Format must not keep state between headers, since a partial header is
+// parsed again once more data arrives; CoAP options cannot be assembled.
+type Assembler struct {
+	// Format is the wire format of the records; if nil, Standard is
+	// used.
+	Format Format
+
+	// MaxLength, if nonzero, is the largest value length accepted.
+	// Longer records are rejected with ErrTooLarge as soon as their
+	// header arrives, rather than being buffered.
+	MaxLength int
+
+	// Timeout, if nonzero, is how long a partial record may remain
+	// incomplete. A peer that stops sending partway through a record
+	// is reported with ErrStalled by Push and by Stalled.
+	Timeout time.Duration
+
+	buf   []byte
+	since time.Time
+}
+
+// Push adds b to the data received so far, returning the records it
+// completes. Any trailing partial record is kept for the next call. If
+// the Format reports the end of the stream, the records before it are
+// returned along with io.EOF. If b leaves a partial record that has been
+// waiting longer than the Timeout, ErrStalled is returned; b is kept all
+// the same, so the caller may keep pushing data, and once the record is
+// completed Push returns it. Once Push returns any other error, the
+// stream is not recoverable and the Assembler should be discarded or
+// Reset.
+func (asm *Assembler) Push(b []byte) (recs []TLV, err error) {
+	asm.buf = append(asm.buf, b...)
+
+	format := asm.Format
+	if format == nil {
+		format = Standard
+	}
+
+	var off int
+	for off < len(asm.buf) {
+		r := bytes.NewReader(asm.buf[off:])
+		tag, length, herr := format.ReadHeader(r)
+		if herr == io.ErrUnexpectedEOF {
+			break
+		} else if herr != nil {
+			err = herr
+			break
+		} else if length < 0 {
+			err = ErrLengthRange
+			break
+		} else if asm.MaxLength > 0 && length > asm.MaxLength {
+			err = ErrTooLarge
+			break
+		}
+
+		start := len(asm.buf) - off - r.Len()
+		if r.Len() < length {
+			break
+		}
+		recs = append(recs, newTLV(tag, asm.buf[off+start:off+start+length]))
+		off += start + length
+	}
+
+	// Keep the partial record at the front of the buffer, timing it
+	// from when its first byte arrived.
+	rest := copy(asm.buf, asm.buf[off:])
+	asm.buf = asm.buf[:rest]
+	if rest == 0 {
+		asm.since = time.Time{}
+	} else if off > 0 || asm.since.IsZero() {
+		asm.since = time.Now()
+	}
+	if err == nil && asm.Stalled(time.Now()) {
+		err = ErrStalled
+	}
+	return
+}
+
+// Buffered returns the number of bytes held for a partial record.
+func (asm *Assembler) Buffered() int {
+	return len(asm.buf)
+}
+
+// Stalled reports whether, at time now, a partial record has been
+// waiting for longer than the Assembler's Timeout. An event loop can
+// call it from a timer to close connections to peers that have gone
+// quiet partway through a record.
+func (asm *Assembler) Stalled(now time.Time) bool {
+	if asm.Timeout <= 0 || asm.since.IsZero() {
+		return false
+	}
+	return now.Sub(asm.since) > asm.Timeout
+}
+
+// Reset discards any partial record, preparing the Assembler for a new
+// stream.
+func (asm *Assembler) Reset() {
+	asm.buf = asm.buf[:0]
+	asm.since = time.Time{}
+}
@@ -0,0 +1,100 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestAssembler(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(TagTest1, []byte("foo bar"))
+	tlvl.Add(TagTest2, nil)
+	tlvl.Add(TagTest3, []byte("gophers are everywhere!"))
+
+	buf := new(bytes.Buffer)
+	if err := tlvl.Write(buf); err != nil {
+		FailWithError(t, "TestAssembler", err)
+	}
+	raw := buf.Bytes()
+
+	// Feed the stream in awkward pieces, splitting headers and values.
+	asm := new(Assembler)
+	var got []TLV
+	for off := 0; off < len(raw); off += 5 {
+		end := off + 5
+		if end > len(raw) {
+			end = len(raw)
+		}
+		recs, err := asm.Push(raw[off:end])
+		if err != nil {
+			FailWithError(t, "TestAssembler", err)
+		}
+		got = append(got, recs...)
+	}
+
+	if len(got) != tlvl.Length() {
+		FailWithError(t, "TestAssembler",
+			fmt.Errorf("assembled %d records, expected %d",
+				len(got), tlvl.Length()))
+	}
+	i := 0
+	for _, tlv := range tlvl.records {
+		if !Equals(got[i], tlv) {
+			FailWithError(t, "TestAssembler", noMatch)
+		}
+		i++
+	}
+	if asm.Buffered() != 0 {
+		FailWithError(t, "TestAssembler",
+			fmt.Errorf("%d bytes left buffered", asm.Buffered()))
+	}
+}
+
+func TestAssemblerStalled(t *testing.T) {
+	asm := &Assembler{Timeout: time.Minute}
+	if _, err := asm.Push([]byte{0, 0, 0, 1, 0}); err != nil {
+		FailWithError(t, "TestAssemblerStalled", err)
+	}
+	if asm.Stalled(time.Now()) {
+		FailWithError(t, "TestAssemblerStalled",
+			fmt.Errorf("stalled before timeout"))
+	}
+	if !asm.Stalled(time.Now().Add(time.Hour)) {
+		FailWithError(t, "TestAssemblerStalled",
+			fmt.Errorf("not stalled after timeout"))
+	}
+
+	// A stalled record is reported, but the data is kept, and the
+	// record is returned once it is completed.
+	asm.since = time.Now().Add(-time.Hour)
+	if _, err := asm.Push([]byte{0, 0}); err != ErrStalled {
+		FailWithError(t, "TestAssemblerStalled",
+			fmt.Errorf("expected ErrStalled, got %v", err))
+	} else if asm.Buffered() != 7 {
+		FailWithError(t, "TestAssemblerStalled",
+			fmt.Errorf("%d bytes buffered, expected 7", asm.Buffered()))
+	}
+	recs, err := asm.Push([]byte{2, 'h', 'i'})
+	if err != nil {
+		FailWithError(t, "TestAssemblerStalled", err)
+	} else if len(recs) != 1 || string(recs[0].Value()) != "hi" {
+		FailWithError(t, "TestAssemblerStalled", noMatch)
+	}
+
+	asm.Push([]byte{0})
+	asm.Reset()
+	if asm.Stalled(time.Now().Add(time.Hour)) {
+		FailWithError(t, "TestAssemblerStalled",
+			fmt.Errorf("stalled with nothing buffered"))
+	}
+}
+
+func TestAssemblerMaxLength(t *testing.T) {
+	asm := &Assembler{MaxLength: 4}
+	if _, err := asm.Push([]byte{0, 0, 0, 1, 0, 0, 0, 5}); err != ErrTooLarge {
+		FailWithError(t, "TestAssemblerMaxLength",
+			fmt.Errorf("expected ErrTooLarge, got %v", err))
+	}
+}
@@ -0,0 +1,294 @@
+package tlv
+
+import (
+	"fmt"
+	"io"
+)
+
+// BER is the BER-TLV format used by ASN.1 BER, EMV and other smartcard
+// standards. A record's tag is its identifier octets read as a
+// big-endian integer, so that tags are written the way the standards
+// write them: the EMV Application Identifier is tag 0x4F, and the
+// Language Preference is tag 0x5F2D. Tags of up to four identifier
+// octets are supported; where int is 32 bits, four-octet tags in the
+// context-specific and private classes do not fit and are rejected
+// with ErrTagRange. Lengths use the definite short and long forms;
+// the indefinite form is rejected with ErrLengthRange.
+var BER Format = berFormat{}
+
+// DER is the ASN.1 DER subset of BER. It is written identically to BER,
+// but on reading, identifier and length octets must use their shortest
+// encoding, as X.690 requires for DER; anything else is rejected with
+// ErrTagRange or ErrLengthRange. Tags that do not use their shortest
+// encoding are also rejected when writing.
+var DER Format = berFormat{der: true}
+
+type berFormat struct {
+	der bool
+}
+
+func (f berFormat) ReadHeader(r io.Reader) (tag, length int, err error) {
+	br := asByteReader(r)
+	if tag, err = readBERTag(br); err != nil {
+		return
+	} else if f.der && !minimalBERTag(tag) {
+		return 0, 0, ErrTagRange
+	}
+
+	length, err = readBERLength(br, f.der)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return
+}
+
+func (f berFormat) WriteHeader(w io.Writer, tag, length int) error {
+	if f.der && !minimalBERTag(tag) {
+		return ErrTagRange
+	}
+
+	var hdr [9]byte
+	n, err := putBERTag(hdr[:], tag)
+	if err != nil {
+		return err
+	}
+
+	m, err := putBERLength(hdr[n:], length)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(hdr[:n+m])
+	return err
+}
+
+// readBERTag reads a record's identifier octets. Subsequent octets follow
+// the first when its tag number bits are all set, and continue for as
+// long as the high bit of each is set.
+func readBERTag(br io.ByteReader) (tag int, err error) {
+	b, err := br.ReadByte()
+	if err != nil {
+		return
+	}
+	tag = int(b)
+	if b&0x1f != 0x1f {
+		return
+	}
+
+	for i := 1; ; i++ {
+		if i == 4 {
+			return 0, ErrTagRange
+		}
+		if b, err = br.ReadByte(); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return
+		}
+		if uint64(tag) > maxInt>>8 {
+			return 0, ErrTagRange
+		}
+		tag = tag<<8 | int(b)
+		if b&0x80 == 0 {
+			return
+		}
+	}
+}
+
+// readBERLength reads a definite length. If minimal is true, lengths
+// that could have been encoded in fewer octets are rejected.
+func readBERLength(br io.ByteReader, minimal bool) (length int, err error) {
+	b, err := br.ReadByte()
+	if err != nil {
+		return
+	} else if b < 0x80 {
+		return int(b), nil
+	}
+
+	n := int(b & 0x7f)
+	if n == 0 || n > 4 {
+		return 0, ErrLengthRange
+	}
+	for i := 0; i < n; i++ {
+		if b, err = br.ReadByte(); err != nil {
+			return 0, io.ErrUnexpectedEOF
+		}
+		length = length<<8 | int(b)
+	}
+	if length < 0 || length > maxBERLength {
+		return 0, ErrLengthRange
+	} else if minimal && (length < 0x80 || length>>uint(8*(n-1)) == 0) {
+		return 0, ErrLengthRange
+	}
+	return
+}
+
+// minimalBERTag reports whether a tag's identifier octets are the
+// shortest encoding of its tag number: the high-tag-number form is only
+// used for tag numbers of 31 or more, and has no leading zero bits.
+func minimalBERTag(tag int) bool {
+	if tag < 0x100 {
+		return true
+	} else if tag < 0x10000 {
+		return tag&0x7f >= 0x1f
+	}
+
+	n := 0
+	for t := tag; t >= 0x100; t >>= 8 {
+		n++
+	}
+	return byte(tag>>uint(8*(n-1))) != 0x80
+}
+
+const maxBERLength = 1<<31 - 1
+
+// berTagSize returns the number of identifier octets in tag, checking
+// that they form a valid identifier.
+func berTagSize(tag int) (n int, err error) {
+	if tag < 0 || int64(tag) > 0xffffffff {
+		return 0, ErrTagRange
+	}
+
+	n = 1
+	for t := tag >> 8; t != 0; t >>= 8 {
+		n++
+	}
+
+	first := byte(tag >> uint(8*(n-1)))
+	if n == 1 {
+		if first&0x1f == 0x1f {
+			return 0, ErrTagRange
+		}
+		return
+	} else if first&0x1f != 0x1f {
+		return 0, ErrTagRange
+	}
+
+	for i := n - 2; i >= 0; i-- {
+		more := byte(tag>>uint(8*i))&0x80 != 0
+		if more != (i > 0) {
+			return 0, ErrTagRange
+		}
+	}
+	return
+}
+
+func putBERTag(b []byte, tag int) (n int, err error) {
+	if n, err = berTagSize(tag); err != nil {
+		return
+	}
+	for i := 0; i < n; i++ {
+		b[i] = byte(tag >> uint(8*(n-1-i)))
+	}
+	return
+}
+
+func putBERLength(b []byte, length int) (n int, err error) {
+	if length < 0 || length > maxBERLength {
+		return 0, ErrLengthRange
+	} else if length < 0x80 {
+		b[0] = byte(length)
+		return 1, nil
+	}
+
+	for l := length; l != 0; l >>= 8 {
+		n++
+	}
+	b[0] = 0x80 | byte(n)
+	for i := 0; i < n; i++ {
+		b[1+i] = byte(length >> uint(8*(n-1-i)))
+	}
+	return n + 1, nil
+}
+
+// Type TagClass is the class of a BER tag, held in the top two bits of
+// its first identifier octet.
+type TagClass byte
+
+// The four BER tag classes.
+const (
+	ClassUniversal   TagClass = 0x00
+	ClassApplication TagClass = 0x40
+	ClassContext     TagClass = 0x80
+	ClassPrivate     TagClass = 0xc0
+)
+
+// Method String returns the name of the class as X.680 writes it.
+func (class TagClass) String() string {
+	switch class {
+	case ClassUniversal:
+		return "UNIVERSAL"
+	case ClassApplication:
+		return "APPLICATION"
+	case ClassContext:
+		return "CONTEXT"
+	case ClassPrivate:
+		return "PRIVATE"
+	}
+	return fmt.Sprintf("TagClass(%#x)", byte(class))
+}
+
+// berConstructedBit marks a constructed encoding, whose value holds
+// nested records, in the first identifier octet.
+const berConstructedBit = 0x20
+
+// maxBERTagNumber is the largest tag number that fits in the four
+// identifier octets supported by BER: three subsequent octets of seven
+// bits each.
+const maxBERTagNumber = 1<<21 - 1
+
+// BERTag returns the tag used by the BER and DER formats for a record of
+// the given class and tag number, with the constructed bit set if the
+// record's value holds nested records. Tag numbers of 31 or more use the
+// high-tag-number form. ErrTagRange is returned if class is not one of
+// the four classes or the tag number is negative or too large.
+func BERTag(class TagClass, constructed bool, number int) (tag int, err error) {
+	if class&^0xc0 != 0 || number < 0 || number > maxBERTagNumber {
+		return 0, ErrTagRange
+	}
+
+	first := int(class)
+	if constructed {
+		first |= berConstructedBit
+	}
+	if number < 0x1f {
+		return first | number, nil
+	}
+
+	tag = first | 0x1f
+	n := 1
+	for t := number >> 7; t != 0; t >>= 7 {
+		n++
+	}
+	for i := n - 1; i >= 0; i-- {
+		b := number >> uint(7*i) & 0x7f
+		if i > 0 {
+			b |= 0x80
+		}
+		if uint64(tag) > maxInt>>8 {
+			return 0, ErrTagRange
+		}
+		tag = tag<<8 | b
+	}
+	return
+}
+
+// SplitBERTag splits a tag used by the BER and DER formats into its
+// class, constructed bit and tag number. ErrTagRange is returned if the
+// tag's identifier octets are malformed.
+func SplitBERTag(tag int) (class TagClass, constructed bool, number int, err error) {
+	n, err := berTagSize(tag)
+	if err != nil {
+		return
+	}
+
+	first := byte(tag >> uint(8*(n-1)))
+	class = TagClass(first & 0xc0)
+	constructed = first&berConstructedBit != 0
+	if n == 1 {
+		return class, constructed, int(first & 0x1f), nil
+	}
+	for i := n - 2; i >= 0; i-- {
+		number = number<<7 | tag>>uint(8*i)&0x7f
+	}
+	return
+}
@@ -0,0 +1,204 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+// An EMV File Control Information template, as returned when selecting
+// the payment system environment.
+var emvFCI = []byte{
+	0x6f, 0x1a,
+	0x84, 0x0e, 0x31, 0x50, 0x41, 0x59, 0x2e, 0x53, 0x59, 0x53,
+	0x2e, 0x44, 0x44, 0x46, 0x30, 0x31,
+	0xa5, 0x08,
+	0x88, 0x01, 0x02,
+	0x5f, 0x2d, 0x02, 0x65, 0x6e,
+}
+
+func decodeFormat(t *testing.T, name string, f Format, raw []byte) *TLVList {
+	dec := NewDecoder(bytes.NewReader(raw))
+	dec.Format = f
+	recs, err := dec.DecodeList()
+	if err != nil {
+		FailWithError(t, name, err)
+	}
+	return recs
+}
+
+func encodeFormat(t *testing.T, name string, f Format, recs *TLVList) []byte {
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+	enc.Format = f
+	if err := enc.EncodeList(recs); err != nil {
+		FailWithError(t, name, err)
+	}
+	return buf.Bytes()
+}
+
+func TestBER(t *testing.T) {
+	fci := decodeFormat(t, "TestBER", BER, emvFCI)
+	tmpl, err := fci.Get(0x6f)
+	if err != nil {
+		FailWithError(t, "TestBER", err)
+	}
+
+	inner := decodeFormat(t, "TestBER", BER, tmpl.Value())
+	if df, err := inner.Get(0x84); err != nil {
+		FailWithError(t, "TestBER", err)
+	} else if string(df.Value()) != "1PAY.SYS.DDF01" {
+		FailWithError(t, "TestBER", noMatch)
+	}
+
+	prop, err := inner.Get(0xa5)
+	if err != nil {
+		FailWithError(t, "TestBER", err)
+	}
+	props := decodeFormat(t, "TestBER", BER, prop.Value())
+	if lang, err := props.Get(0x5f2d); err != nil {
+		FailWithError(t, "TestBER", err)
+	} else if string(lang.Value()) != "en" {
+		FailWithError(t, "TestBER", noMatch)
+	}
+
+	if !bytes.Equal(encodeFormat(t, "TestBER", BER, fci), emvFCI) {
+		FailWithError(t, "TestBER",
+			fmt.Errorf("BER encoding did not round-trip"))
+	}
+}
+
+func TestBERLengths(t *testing.T) {
+	for _, length := range []int{0, 0x7f, 0x80, 0xff, 0x100, 0x10000} {
+		tlvl := New()
+		tlvl.Add(0x9f02, make([]byte, length))
+		raw := encodeFormat(t, "TestBERLengths", BER, tlvl)
+
+		rtlvl := decodeFormat(t, "TestBERLengths", BER, raw)
+		if rec, err := rtlvl.Get(0x9f02); err != nil {
+			FailWithError(t, "TestBERLengths", err)
+		} else if rec.Length() != length {
+			FailWithError(t, "TestBERLengths",
+				fmt.Errorf("length %d decoded as %d",
+					length, rec.Length()))
+		}
+	}
+}
+
+func TestBERInvalid(t *testing.T) {
+	buf := new(bytes.Buffer)
+	for _, tag := range []int{0x1f, 0x9f82, 0x5f, 0x9f8201 ^ 0x80, -1} {
+		if err := BER.WriteHeader(buf, tag, 0); err != ErrTagRange {
+			FailWithError(t, "TestBERInvalid",
+				fmt.Errorf("tag %x should be rejected", tag))
+		}
+	}
+
+	dec := NewDecoder(bytes.NewReader([]byte{0x30, 0x80, 0x00, 0x00}))
+	dec.Format = BER
+	if _, err := dec.Decode(); err != ErrLengthRange {
+		FailWithError(t, "TestBERInvalid",
+			fmt.Errorf("indefinite length should be rejected"))
+	}
+}
+
+func TestDER(t *testing.T) {
+	// SEQUENCE { INTEGER 5, [APPLICATION 40] "hi" }
+	der := []byte{0x30, 0x08, 0x02, 0x01, 0x05, 0x5f, 0x28, 0x02, 'h', 'i'}
+
+	seq := decodeFormat(t, "TestDER", DER, der)
+	rec, err := seq.Get(0x30)
+	if err != nil {
+		FailWithError(t, "TestDER", err)
+	}
+	fields := decodeFormat(t, "TestDER", DER, rec.Value())
+	if _, err = fields.Get(0x5f28); err != nil {
+		FailWithError(t, "TestDER", err)
+	}
+	if !bytes.Equal(encodeFormat(t, "TestDER", DER, seq), der) {
+		FailWithError(t, "TestDER",
+			fmt.Errorf("DER encoding did not round-trip"))
+	}
+
+	nonMinimal := [][]byte{
+		{0x04, 0x81, 0x01, 0x00},
+		{0x04, 0x82, 0x00, 0x01, 0x00},
+		{0x5f, 0x05, 0x00},
+		{0x5f, 0x80, 0x28, 0x00},
+	}
+	for _, raw := range nonMinimal {
+		// BER accepts these encodings; DER must not.
+		decodeFormat(t, "TestDER", BER, raw)
+
+		dec := NewDecoder(bytes.NewReader(raw))
+		dec.Format = DER
+		if _, err := dec.Decode(); err != ErrTagRange && err != ErrLengthRange {
+			FailWithError(t, "TestDER",
+				fmt.Errorf("non-minimal encoding %x accepted", raw))
+		}
+	}
+
+	if err = DER.WriteHeader(new(bytes.Buffer), 0x5f05, 0); err != ErrTagRange {
+		FailWithError(t, "TestDER",
+			fmt.Errorf("non-minimal tag should not be written"))
+	}
+}
+
+func TestBERTag(t *testing.T) {
+	var tests = []struct {
+		class       TagClass
+		constructed bool
+		number      int
+		tag         int
+	}{
+		{ClassUniversal, false, 2, 0x02},      // INTEGER
+		{ClassUniversal, true, 16, 0x30},      // SEQUENCE
+		{ClassApplication, true, 15, 0x6f},    // EMV FCI template
+		{ClassContext, true, 5, 0xa5},         // FCI proprietary template
+		{ClassApplication, false, 45, 0x5f2d}, // Language Preference
+		{ClassContext, false, 0x80, 0x9f8100}, // two subsequent octets
+		{ClassPrivate, true, 0x3fff, 0xffff7f},
+	}
+
+	for _, tt := range tests {
+		tag, err := BERTag(tt.class, tt.constructed, tt.number)
+		if err != nil {
+			FailWithError(t, "TestBERTag", err)
+		} else if tag != tt.tag {
+			FailWithError(t, "TestBERTag",
+				fmt.Errorf("tag is %#x, expected %#x", tag, tt.tag))
+		}
+
+		class, constructed, number, err := SplitBERTag(tt.tag)
+		if err != nil {
+			FailWithError(t, "TestBERTag", err)
+		} else if class != tt.class || constructed != tt.constructed || number != tt.number {
+			FailWithError(t, "TestBERTag",
+				fmt.Errorf("%#x split into %v %v %d", tt.tag,
+					class, constructed, number))
+		}
+	}
+
+	// Four-octet tags with the high bit set only fit in a 64-bit int.
+	if tag, err := BERTag(ClassPrivate, true, maxBERTagNumber); strconv.IntSize == 32 {
+		if err != ErrTagRange {
+			FailWithError(t, "TestBERTag",
+				fmt.Errorf("expected ErrTagRange, got %v", err))
+		}
+	} else if err != nil {
+		FailWithError(t, "TestBERTag", err)
+	} else if uint64(tag) != 0xffffff7f {
+		FailWithError(t, "TestBERTag",
+			fmt.Errorf("tag is %#x, expected 0xffffff7f", tag))
+	}
+
+	if _, err := BERTag(ClassContext, false, maxBERTagNumber+1); err != ErrTagRange {
+		FailWithError(t, "TestBERTag",
+			fmt.Errorf("expected ErrTagRange, got %v", err))
+	}
+	if _, _, _, err := SplitBERTag(0x1f); err != ErrTagRange {
+		FailWithError(t, "TestBERTag",
+			fmt.Errorf("expected ErrTagRange, got %v", err))
+	}
+}
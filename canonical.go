@@ -0,0 +1,94 @@
+package tlv
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"sort"
+)
+
+// Canonical returns a copy of the TLVList with its records sorted by
+// tag. Records with the same tag keep their relative order, so equal
+// lists always produce the same canonical list.
+func (recs *TLVList) Canonical() *TLVList {
+	canon := New()
+	canon.records = append([]TLV(nil), recs.records...)
+	canon.Sort()
+	return canon
+}
+
+// Hash returns the SHA-256 digest of the list's records sorted by tag
+// and then by value, in the Standard format. It is stable across
+// processes and machines, so it can be used as a cache key or to detect
+// changes; lists that are equal under ListEquals with ignoreOrder set
+// have the same Hash. An error is returned if a record cannot be
+// encoded.
+func (recs *TLVList) Hash() (sum [sha256.Size]byte, err error) {
+	h := sha256.New()
+	if err = NewEncoder(h).EncodeList(recs.unordered()); err != nil {
+		return
+	}
+	h.Sum(sum[:0])
+	return
+}
+
+// unordered returns a copy of the TLVList sorted by tag and then by
+// value, so that lists holding the same records in any order give the
+// same list.
+func (recs *TLVList) unordered() *TLVList {
+	sorted := New()
+	sorted.records = append([]TLV(nil), recs.records...)
+	sorted.SortFunc(func(a, b TLV) bool {
+		if a.Tag() != b.Tag() {
+			return a.Tag() < b.Tag()
+		}
+		return bytes.Compare(a.Value(), b.Value()) < 0
+	})
+	return sorted
+}
+
+// listSorter sorts a TLVList's records, keeping their checksums, if
+// any, alongside.
+type listSorter struct {
+	recs *TLVList
+	less func(a, b TLV) bool
+}
+
+func (ls listSorter) Len() int {
+	return len(ls.recs.records)
+}
+
+func (ls listSorter) Less(i, j int) bool {
+	return ls.less(ls.recs.records[i], ls.recs.records[j])
+}
+
+func (ls listSorter) Swap(i, j int) {
+	records := ls.recs.records
+	records[i], records[j] = records[j], records[i]
+	if sums := ls.recs.sums; sums != nil {
+		sums[i], sums[j] = sums[j], sums[i]
+	}
+}
+
+// Sort sorts the records in the TLVList by ascending tag, in place.
+// Records with the same tag keep their relative order.
+func (recs *TLVList) Sort() {
+	recs.SortFunc(func(a, b TLV) bool { return a.Tag() < b.Tag() })
+}
+
+// SortFunc sorts the records in the TLVList in place, in the order given
+// by less, which reports whether a must come before b. The sort is
+// stable: records that less does not order keep their relative order.
+func (recs *TLVList) SortFunc(less func(a, b TLV) bool) {
+	sort.Stable(listSorter{recs, less})
+}
+
+// WriteCanonical writes the records of the TLVList to w in canonical
+// form: sorted as by Canonical, in the Standard format, whose headers
+// have only one encoding. Lists holding the same records, in any order
+// of tags, are written byte for byte identically, so the output can be
+// signed or hashed and compared across machines. To write BER-TLV data
+// canonically, encode the Canonical list with an Encoder using DER.
+func (recs *TLVList) WriteCanonical(w io.Writer) error {
+	return NewEncoder(w).EncodeList(recs.Canonical())
+}
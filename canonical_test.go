@@ -0,0 +1,112 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestWriteCanonical(t *testing.T) {
+	a := New()
+	a.Add(TagTest3, []byte("c"))
+	a.Add(TagTest1, []byte("a1"))
+	a.Add(TagTest2, []byte("b"))
+	a.Add(TagTest1, []byte("a2"))
+
+	b := New()
+	b.Add(TagTest1, []byte("a1"))
+	b.Add(TagTest2, []byte("b"))
+	b.Add(TagTest1, []byte("a2"))
+	b.Add(TagTest3, []byte("c"))
+
+	bufA, bufB := new(bytes.Buffer), new(bytes.Buffer)
+	if err := a.WriteCanonical(bufA); err != nil {
+		FailWithError(t, "TestWriteCanonical", err)
+	} else if err = b.WriteCanonical(bufB); err != nil {
+		FailWithError(t, "TestWriteCanonical", err)
+	} else if !bytes.Equal(bufA.Bytes(), bufB.Bytes()) {
+		FailWithError(t, "TestWriteCanonical",
+			fmt.Errorf("equal lists were written differently"))
+	}
+
+	canon := a.Canonical()
+	checkTags(t, "TestWriteCanonical", canon, TagTest1, TagTest1, TagTest2, TagTest3)
+	if recs := canon.GetAll(TagTest1); string(recs[0].Value()) != "a1" {
+		FailWithError(t, "TestWriteCanonical",
+			fmt.Errorf("duplicates were reordered"))
+	}
+	checkTags(t, "TestWriteCanonical", a, TagTest3, TagTest1, TagTest2, TagTest1)
+}
+
+func TestSort(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(TagTest3, []byte("c"))
+	tlvl.Add(TagTest1, []byte("a1"))
+	tlvl.Protect()
+	tlvl.Add(TagTest2, []byte("bb"))
+	tlvl.Add(TagTest1, []byte("a2"))
+
+	tlvl.Sort()
+	checkTags(t, "TestSort", tlvl, TagTest1, TagTest1, TagTest2, TagTest3)
+	if rec, _ := tlvl.Get(TagTest1); string(rec.Value()) != "a1" {
+		FailWithError(t, "TestSort", fmt.Errorf("duplicates were reordered"))
+	} else if err := tlvl.Verify(); err != nil {
+		FailWithError(t, "TestSort", err)
+	}
+
+	tlvl.SortFunc(func(a, b TLV) bool { return a.Length() < b.Length() })
+	checkTags(t, "TestSort", tlvl, TagTest3, TagTest1, TagTest1, TagTest2)
+	if err := tlvl.Verify(); err != nil {
+		FailWithError(t, "TestSort", err)
+	}
+}
+
+func TestListEqualsHash(t *testing.T) {
+	a := New()
+	a.Add(TagTest2, []byte("b"))
+	a.Add(TagTest1, []byte("a1"))
+	a.Add(TagTest1, []byte("a2"))
+
+	b := New()
+	b.Add(TagTest1, []byte("a1"))
+	b.Add(TagTest1, []byte("a2"))
+	b.Add(TagTest2, []byte("b"))
+
+	c := New()
+	c.Add(TagTest1, []byte("a2"))
+	c.Add(TagTest1, []byte("a1"))
+	c.Add(TagTest2, []byte("b"))
+
+	d := New()
+	d.Add(TagTest1, []byte("a1"))
+	d.Add(TagTest1, []byte("a1"))
+	d.Add(TagTest2, []byte("b"))
+
+	if !ListEquals(a, a.Clone(), false) || ListEquals(a, b, false) || ListEquals(b, c, false) {
+		FailWithError(t, "TestListEqualsHash", fmt.Errorf("bad ordered comparison"))
+	} else if !ListEquals(a, b, true) || !ListEquals(b, c, true) || ListEquals(c, d, true) {
+		FailWithError(t, "TestListEqualsHash", fmt.Errorf("bad unordered comparison"))
+	} else if ListEquals(a, b.Head(2), true) {
+		FailWithError(t, "TestListEqualsHash", fmt.Errorf("lists of different lengths are equal"))
+	}
+
+	hashes := make(map[[32]byte]bool)
+	for _, recs := range []*TLVList{a, b, c, d, New()} {
+		sum, err := recs.Hash()
+		if err != nil {
+			FailWithError(t, "TestListEqualsHash", err)
+		}
+		hashes[sum] = true
+	}
+	if len(hashes) != 3 {
+		FailWithError(t, "TestListEqualsHash",
+			fmt.Errorf("expected 3 distinct hashes, got %d", len(hashes)))
+	}
+
+	bad := New()
+	bad.AddRecord(bigRecord{})
+	if _, err := bad.Hash(); err == nil && wideLength != 0 {
+		FailWithError(t, "TestListEqualsHash",
+			fmt.Errorf("unencodable list should not hash"))
+	}
+}
@@ -0,0 +1,162 @@
+package tlv
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// ErrCBOR is returned by FromCBOR when its input is not a CBOR map of
+// the form written by ToCBOR.
+var ErrCBOR = fmt.Errorf("unsupported CBOR data")
+
+// CBOR major types.
+const (
+	cborUint   = 0
+	cborNegInt = 1
+	cborBytes  = 2
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+)
+
+func appendCBORHead(b []byte, major byte, n uint64) []byte {
+	major <<= 5
+	switch {
+	case n < 24:
+		return append(b, major|byte(n))
+	case n <= 0xff:
+		return append(b, major|24, byte(n))
+	case n <= 0xffff:
+		b = append(b, major|25, 0, 0)
+		binary.BigEndian.PutUint16(b[len(b)-2:], uint16(n))
+	case n <= 0xffffffff:
+		b = append(b, major|26, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(b[len(b)-4:], uint32(n))
+	default:
+		b = append(b, major|27, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(b[len(b)-8:], n)
+	}
+	return b
+}
+
+// ToCBOR converts the TLVList to a CBOR map (RFC 8949) with integer keys
+// holding the tags and byte string values. A tag appearing more than
+// once maps to an array of byte strings. Keys are written in the order
+// their tags first appear in the list, and the values of each tag stay
+// in order, but the interleaving of different tags is not kept.
+func (recs *TLVList) ToCBOR() ([]byte, error) {
+	var tags []int
+	values := make(map[int][][]byte)
+	for _, tlv := range recs.records {
+		if _, ok := values[tlv.Tag()]; !ok {
+			tags = append(tags, tlv.Tag())
+		}
+		values[tlv.Tag()] = append(values[tlv.Tag()], tlv.Value())
+	}
+
+	out := appendCBORHead(nil, cborMap, uint64(len(tags)))
+	for _, tag := range tags {
+		if tag < 0 {
+			out = appendCBORHead(out, cborNegInt, uint64(-1-tag))
+		} else {
+			out = appendCBORHead(out, cborUint, uint64(tag))
+		}
+
+		vs := values[tag]
+		if len(vs) > 1 {
+			out = appendCBORHead(out, cborArray, uint64(len(vs)))
+		}
+		for _, v := range vs {
+			out = appendCBORHead(out, cborBytes, uint64(len(v)))
+			out = append(out, v...)
+		}
+	}
+	return out, nil
+}
+
+type cborReader struct {
+	data []byte
+}
+
+func (cr *cborReader) head() (major byte, n uint64, err error) {
+	if len(cr.data) == 0 {
+		return 0, 0, ErrCBOR
+	}
+	major, info := cr.data[0]>>5, cr.data[0]&0x1f
+	cr.data = cr.data[1:]
+	if info < 24 {
+		return major, uint64(info), nil
+	} else if info > 27 {
+		// Indefinite lengths and reserved values are not supported.
+		return 0, 0, ErrCBOR
+	}
+
+	size := 1 << (info - 24)
+	if len(cr.data) < size {
+		return 0, 0, ErrCBOR
+	}
+	var buf [8]byte
+	copy(buf[8-size:], cr.data[:size])
+	cr.data = cr.data[size:]
+	return major, binary.BigEndian.Uint64(buf[:]), nil
+}
+
+func (cr *cborReader) bytes() ([]byte, error) {
+	major, n, err := cr.head()
+	if err != nil {
+		return nil, err
+	} else if (major != cborBytes && major != cborText) || n > uint64(len(cr.data)) {
+		return nil, ErrCBOR
+	}
+	value := cr.data[:n]
+	cr.data = cr.data[n:]
+	return value, nil
+}
+
+// FromCBOR builds a TLVList from a CBOR map written by ToCBOR. Text
+// strings are accepted in place of byte strings.
+func FromCBOR(data []byte) (*TLVList, error) {
+	cr := &cborReader{data}
+	major, n, err := cr.head()
+	if err != nil {
+		return nil, err
+	} else if major != cborMap {
+		return nil, ErrCBOR
+	}
+
+	recs := New()
+	for i := uint64(0); i < n; i++ {
+		major, key, err := cr.head()
+		if err != nil {
+			return nil, err
+		}
+		var tag int
+		switch {
+		case major == cborUint && key <= 0x7fffffff:
+			tag = int(key)
+		case major == cborNegInt && key <= 0x7fffffff:
+			tag = -1 - int(key)
+		default:
+			return nil, ErrCBOR
+		}
+
+		count := uint64(1)
+		if len(cr.data) > 0 && cr.data[0]>>5 == cborArray {
+			if _, count, err = cr.head(); err != nil {
+				return nil, err
+			}
+		}
+		for j := uint64(0); j < count; j++ {
+			value, err := cr.bytes()
+			if err != nil {
+				return nil, err
+			}
+			recs.Add(tag, value)
+		}
+	}
+
+	if len(cr.data) != 0 {
+		return nil, ErrCBOR
+	}
+	return recs, nil
+}
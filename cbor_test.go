@@ -0,0 +1,57 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestCBOR(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(TagTest1, []byte("hi"))
+	tlvl.Add(TagTest2, nil)
+	tlvl.Add(TagTest1, make([]byte, 300))
+	tlvl.Add(-25, []byte{0x01})
+
+	out, err := tlvl.ToCBOR()
+	if err != nil {
+		FailWithError(t, "TestCBOR", err)
+	}
+	want := []byte{0xa3, 0x00, 0x82, 0x42, 'h', 'i', 0x59, 0x01, 0x2c}
+	if !bytes.Equal(out[:len(want)], want) {
+		FailWithError(t, "TestCBOR",
+			fmt.Errorf("bad encoding % x", out[:len(want)]))
+	} else if !bytes.Equal(out[len(want)+300:], []byte{0x01, 0x40, 0x38, 0x18, 0x41, 0x01}) {
+		FailWithError(t, "TestCBOR",
+			fmt.Errorf("bad encoding % x", out[len(want)+300:]))
+	}
+
+	rtlvl, err := FromCBOR(out)
+	if err != nil {
+		FailWithError(t, "TestCBOR", err)
+	}
+	checkTags(t, "TestCBOR", rtlvl, TagTest1, TagTest1, TagTest2, -25)
+	if recs := rtlvl.GetAll(TagTest1); recs[1].Length() != 300 {
+		FailWithError(t, "TestCBOR", noMatch)
+	}
+
+	// {1: "text"}, with a text string value.
+	if rtlvl, err = FromCBOR([]byte{0xa1, 0x01, 0x64, 't', 'e', 'x', 't'}); err != nil {
+		FailWithError(t, "TestCBOR", err)
+	} else if rec, _ := rtlvl.Get(TagTest2); string(rec.Value()) != "text" {
+		FailWithError(t, "TestCBOR", noMatch)
+	}
+
+	for _, bad := range [][]byte{
+		{0x81, 0x40},
+		{0xbf, 0xff},
+		{0xa1, 0x61, 'k', 0x40},
+		{0xa1, 0x01, 0x42, 0x00},
+		{0xa1, 0x01, 0x40, 0x00},
+	} {
+		if _, err = FromCBOR(bad); err != ErrCBOR {
+			FailWithError(t, "TestCBOR",
+				fmt.Errorf("% x should be rejected", bad))
+		}
+	}
+}
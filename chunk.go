@@ -0,0 +1,105 @@
+package tlv
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+)
+
+// FourCC returns the tag for a four-character chunk type, such as the
+// "IHDR" of PNG or the "fmt " of RIFF: the four bytes read as a
+// big-endian integer. ErrTagRange is returned if code is not four bytes
+// long, or, where int is 32 bits, if its first byte has the high bit
+// set.
+func FourCC(code string) (int, error) {
+	if len(code) != 4 {
+		return 0, ErrTagRange
+	}
+	return fourCCTag(binary.BigEndian.Uint32([]byte(code)))
+}
+
+// fourCCTag converts a four-byte chunk type to a tag, checking that it
+// fits in an int.
+func fourCCTag(code uint32) (int, error) {
+	if uint64(code) > maxInt {
+		return 0, ErrTagRange
+	}
+	return int(code), nil
+}
+
+// FourCCString returns the four-character chunk type of a tag returned
+// by FourCC.
+func FourCCString(tag int) string {
+	var code [4]byte
+	binary.BigEndian.PutUint32(code[:], uint32(tag))
+	return string(code[:])
+}
+
+// ReadChunks reads PNG-style chunks from r until the end of the stream.
+// Each chunk is a 32-bit big-endian length, a four-byte type, the data,
+// and a CRC-32 of the type and data; the type becomes the record's tag,
+// as given by FourCC. A chunk whose CRC does not match fails with
+// ErrCorrupt. Any signature preceding the chunks, such as PNG's, must
+// already have been read.
+func ReadChunks(r io.Reader) (*TLVList, error) {
+	recs := New()
+	for {
+		var hdr [8]byte
+		if _, err := io.ReadFull(r, hdr[:]); err == io.EOF {
+			return recs, nil
+		} else if err != nil {
+			return nil, ErrTLVRead
+		}
+
+		length := binary.BigEndian.Uint32(hdr[:])
+		if length > maxChunk {
+			return nil, ErrLengthRange
+		}
+		value, err := readValue(r, int(length))
+		if err != nil {
+			return nil, ErrTLVRead
+		}
+		var sum [4]byte
+		if _, err = io.ReadFull(r, sum[:]); err != nil {
+			return nil, ErrTLVRead
+		}
+
+		crc := crc32.Update(crc32.ChecksumIEEE(hdr[4:]), crc32.IEEETable, value)
+		if crc != binary.BigEndian.Uint32(sum[:]) {
+			return nil, ErrCorrupt
+		}
+		tag, err := fourCCTag(binary.BigEndian.Uint32(hdr[4:]))
+		if err != nil {
+			return nil, err
+		}
+		recs.records = append(recs.records, &record{tag: tag, length: len(value), value: value})
+	}
+}
+
+// WriteChunks writes every record in recs to w as a PNG-style chunk, as
+// read by ReadChunks, computing each chunk's CRC.
+func WriteChunks(w io.Writer, recs *TLVList) error {
+	for _, tlv := range recs.records {
+		if tlv.Tag() < 0 || int64(tlv.Tag()) > 0xffffffff {
+			return ErrTagRange
+		} else if tlv.Length() > maxChunk {
+			return ErrLengthRange
+		}
+
+		var hdr [8]byte
+		var sum [4]byte
+		binary.BigEndian.PutUint32(hdr[:], uint32(tlv.Length()))
+		binary.BigEndian.PutUint32(hdr[4:], uint32(tlv.Tag()))
+		crc := crc32.Update(crc32.ChecksumIEEE(hdr[4:]), crc32.IEEETable, tlv.Value())
+		binary.BigEndian.PutUint32(sum[:], crc)
+
+		if _, err := w.Write(hdr[:]); err != nil {
+			return ErrTLVWrite
+		} else if _, err = w.Write(tlv.Value()); err != nil {
+			return ErrTLVWrite
+		} else if _, err = w.Write(sum[:]); err != nil {
+			return ErrTLVWrite
+		}
+	}
+	return nil
+}
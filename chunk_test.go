@@ -0,0 +1,59 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestChunks(t *testing.T) {
+	text, _ := FourCC("tEXt")
+	end, _ := FourCC("IEND")
+	recs := New()
+	recs.Add(text, []byte("Comment\x00gophers"))
+	recs.Add(end, nil)
+
+	buf := new(bytes.Buffer)
+	if err := WriteChunks(buf, recs); err != nil {
+		FailWithError(t, "TestChunks", err)
+	}
+
+	// Every PNG ends with the same IEND chunk.
+	raw := buf.Bytes()
+	iend := []byte{0, 0, 0, 0, 'I', 'E', 'N', 'D', 0xae, 0x42, 0x60, 0x82}
+	if !bytes.HasSuffix(raw, iend) {
+		FailWithError(t, "TestChunks", noMatch)
+	}
+
+	out, err := ReadChunks(bytes.NewReader(raw))
+	if err != nil {
+		FailWithError(t, "TestChunks", err)
+	} else if !CompareLists(out, recs, CompareRules{}).Equal() {
+		FailWithError(t, "TestChunks", noMatch)
+	}
+	if FourCCString(text) != "tEXt" {
+		FailWithError(t, "TestChunks", noMatch)
+	}
+
+	raw[10] ^= 1
+	if _, err = ReadChunks(bytes.NewReader(raw)); err != ErrCorrupt {
+		FailWithError(t, "TestChunks",
+			fmt.Errorf("expected ErrCorrupt, got %v", err))
+	}
+}
+
+func TestChunksHostileLength(t *testing.T) {
+	// An IDAT chunk claiming 2 GB of data, followed by nothing.
+	hostile := []byte{0x7f, 0xff, 0xff, 0xff, 'I', 'D', 'A', 'T'}
+	var err error
+	n := allocatedBy(func() {
+		_, err = ReadChunks(bytes.NewReader(hostile))
+	})
+	if err != ErrTLVRead {
+		FailWithError(t, "TestChunksHostileLength",
+			fmt.Errorf("expected ErrTLVRead, got %v", err))
+	} else if n > 1<<20 {
+		FailWithError(t, "TestChunksHostileLength",
+			fmt.Errorf("%d bytes allocated for a short input", n))
+	}
+}
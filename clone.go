@@ -0,0 +1,56 @@
+package tlv
+
+// Clone returns a deep copy of the TLVList: every record, and every
+// value, is copied, so the copy can be changed freely without affecting
+// the original. Constructed records are cloned with their children, and
+// AVPs, NDEF records and Matter elements keep their types. Other record
+// types are copied as plain records with the same tag and value. A
+// protected list's copy is protected too.
+func (recs *TLVList) Clone() *TLVList {
+	clone := New()
+	clone.records = make([]TLV, len(recs.records))
+	for i, tlv := range recs.records {
+		clone.records[i] = cloneRecord(tlv)
+	}
+	if recs.sums != nil {
+		clone.sums = append([]uint32{}, recs.sums...)
+	}
+	return clone
+}
+
+// cloneBytes copies b, keeping nil slices nil.
+func cloneBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
+
+// cloneRecord returns a deep copy of a single record.
+func cloneRecord(tlv TLV) TLV {
+	switch rec := tlv.(type) {
+	case *riffContainer:
+		return &riffContainer{
+			tag:    rec.tag,
+			form:   rec.form,
+			chunks: rec.chunks.Clone(),
+		}
+	case Constructed:
+		return NewConstructed(rec.Tag(), rec.Children().Clone())
+	case *AVP:
+		avp := *rec
+		avp.Data = cloneBytes(rec.Data)
+		return &avp
+	case *NDEFRecord:
+		ndef := *rec
+		ndef.Type = cloneBytes(rec.Type)
+		ndef.ID = cloneBytes(rec.ID)
+		ndef.Payload = cloneBytes(rec.Payload)
+		return &ndef
+	case *MatterElement:
+		elem := *rec
+		elem.Data = cloneBytes(rec.Data)
+		return &elem
+	}
+	return newTLV(tlv.Tag(), tlv.Value())
+}
@@ -0,0 +1,44 @@
+package tlv
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestClone(t *testing.T) {
+	inner := New()
+	inner.Add(TagTest3, []byte("inner"))
+	tlvl := New()
+	tlvl.Add(TagTest1, []byte("foo"))
+	tlvl.AddList(TagTest2, inner)
+	tlvl.AddRecord(&AVP{Code: 264, Flags: 0x40, Data: []byte("host")})
+	tlvl.Add(TagTest1, []byte("bar"))
+	tlvl.Protect()
+
+	clone := tlvl.Clone()
+	if !CompareLists(tlvl, clone, CompareRules{}).Equal() {
+		FailWithError(t, "TestClone", noMatch)
+	} else if err := clone.Verify(); err != nil {
+		FailWithError(t, "TestClone", err)
+	}
+
+	rec, _ := clone.Get(TagTest1)
+	rec.Value()[0] = 'F'
+	kids, _ := clone.Get(TagTest2)
+	kids.(Constructed).Children().Add(TagTest4, nil)
+	avp, _ := clone.Get(264)
+	avp.(*AVP).Data[0] = 'H'
+	clone.Add(TagTest5, nil)
+
+	if rec, _ = tlvl.Get(TagTest1); string(rec.Value()) != "foo" {
+		FailWithError(t, "TestClone", fmt.Errorf("value was shared"))
+	} else if inner.Length() != 1 {
+		FailWithError(t, "TestClone", fmt.Errorf("children were shared"))
+	} else if avp, _ = tlvl.Get(264); string(avp.Value()) != "host" || avp.(*AVP).Flags != 0x40 {
+		FailWithError(t, "TestClone", fmt.Errorf("AVP was shared"))
+	} else if tlvl.Length() != 4 {
+		FailWithError(t, "TestClone", fmt.Errorf("records were shared"))
+	} else if err := tlvl.Verify(); err != nil {
+		FailWithError(t, "TestClone", err)
+	}
+}
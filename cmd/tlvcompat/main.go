@@ -0,0 +1,49 @@
+// Command tlvcompat compares two versions of a TLV schema file and
+// reports the changes between them. It exits with status 1 if any change
+// is breaking, so it can be used to gate schema changes in CI:
+//
+//	tlvcompat old.json new.json
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/gokyle/tlv"
+)
+
+func readSchema(path string) *tlv.Registry {
+	file, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	defer file.Close()
+
+	reg, err := tlv.ReadSchema(file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		os.Exit(2)
+	}
+	return reg
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s old-schema new-schema\n", os.Args[0])
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	changes := tlv.CompatCheck(readSchema(flag.Arg(0)), readSchema(flag.Arg(1)))
+	for _, c := range changes {
+		fmt.Println(c)
+	}
+	if tlv.Breaking(changes) {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,60 @@
+// Command tlvgen generates typed accessors for the tags in a TLV schema
+// file, wrapping a TLVList in a type with a getter and setter for each
+// named tag. It is meant to be run by go generate:
+//
+//	//go:generate tlvgen -type Device -o device_tlv.go device.json
+//
+// The package name defaults to $GOPACKAGE, which go generate sets.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/gokyle/tlv"
+)
+
+func main() {
+	typeName := flag.String("type", "", "name of the generated type")
+	pkg := flag.String("package", os.Getenv("GOPACKAGE"), "package name")
+	out := flag.String("o", "", "output file; standard output if empty")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s -type name [-package pkg] [-o file] schema\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 || *typeName == "" || *pkg == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	file, err := os.Open(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	reg, err := tlv.ReadSchema(file)
+	file.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+
+	buf := new(bytes.Buffer)
+	if err = reg.GenerateAccessors(buf, *pkg, *typeName); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *out == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+	} else {
+		err = ioutil.WriteFile(*out, buf.Bytes(), 0644)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
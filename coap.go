@@ -0,0 +1,137 @@
+package tlv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+)
+
+// CoAPPayloadMarker separates the options of a CoAP message from its
+// payload.
+const CoAPPayloadMarker = 0xff
+
+// NewCoAPFormat returns a Format for CoAP options (RFC 7252, section
+// 3.1). Each option header holds the difference between its option
+// number and the previous option's, so the returned Format keeps track
+// of the last option number and must not be shared between streams.
+// Reading reconstructs absolute option numbers as tags, and reading the
+// payload marker ends the stream. Writing computes the deltas, and fails
+// with ErrOrder if a tag is less than the one before it. Tags and
+// lengths must be between 0 and 65804.
+func NewCoAPFormat() Format {
+	return &coapFormat{}
+}
+
+type coapFormat struct {
+	last int
+}
+
+const coapMaxExtended = 0xffff + 269
+
+func readCoAPNibble(br io.ByteReader, nibble byte) (int, error) {
+	switch nibble {
+	case 13:
+		b, err := br.ReadByte()
+		return int(b) + 13, err
+	case 14:
+		var ext [2]byte
+		var err error
+		if ext[0], err = br.ReadByte(); err == nil {
+			ext[1], err = br.ReadByte()
+		}
+		return int(binary.BigEndian.Uint16(ext[:])) + 269, err
+	case 15:
+		return 0, ErrLengthRange
+	}
+	return int(nibble), nil
+}
+
+func (f *coapFormat) ReadHeader(r io.Reader) (tag, length int, err error) {
+	br := asByteReader(r)
+	b, err := br.ReadByte()
+	if err != nil {
+		return
+	} else if b == CoAPPayloadMarker {
+		return 0, 0, io.EOF
+	}
+
+	delta, err := readCoAPNibble(br, b>>4)
+	if err == nil {
+		length, err = readCoAPNibble(br, b&0x0f)
+	}
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	f.last += delta
+	return f.last, length, nil
+}
+
+func putCoAPNibble(n int) (nibble byte, ext []byte) {
+	switch {
+	case n < 13:
+		return byte(n), nil
+	case n < 269:
+		return 13, []byte{byte(n - 13)}
+	}
+	ext = make([]byte, 2)
+	binary.BigEndian.PutUint16(ext, uint16(n-269))
+	return 14, ext
+}
+
+func (f *coapFormat) WriteHeader(w io.Writer, tag, length int) error {
+	if tag < f.last {
+		return ErrOrder
+	} else if tag-f.last > coapMaxExtended {
+		return ErrTagRange
+	} else if length < 0 || length > coapMaxExtended {
+		return ErrLengthRange
+	}
+
+	delta, dext := putCoAPNibble(tag - f.last)
+	size, lext := putCoAPNibble(length)
+	hdr := append([]byte{delta<<4 | size}, dext...)
+	if _, err := w.Write(append(hdr, lext...)); err != nil {
+		return err
+	}
+	f.last = tag
+	return nil
+}
+
+// DecodeCoAPOptions decodes the options of a CoAP message, returning
+// them along with the payload that follows the payload marker, if any.
+func DecodeCoAPOptions(data []byte) (opts *TLVList, payload []byte, err error) {
+	r := bytes.NewReader(data)
+	dec := NewDecoder(r)
+	dec.Format = NewCoAPFormat()
+	if opts, err = dec.DecodeList(); err != nil {
+		return nil, nil, err
+	}
+
+	if payload, _ = ioutil.ReadAll(r); len(payload) == 0 {
+		payload = nil
+	}
+	return opts, payload, nil
+}
+
+// EncodeCoAPOptions encodes opts as the options of a CoAP message,
+// followed by the payload marker and payload if the payload is not
+// empty. The options must be in ascending order of option number.
+func EncodeCoAPOptions(opts *TLVList, payload []byte) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+	enc.Format = NewCoAPFormat()
+	if err := enc.EncodeList(opts); err != nil {
+		return nil, err
+	}
+
+	if len(payload) > 0 {
+		buf.WriteByte(CoAPPayloadMarker)
+		buf.Write(payload)
+	}
+	return buf.Bytes(), nil
+}
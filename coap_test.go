@@ -0,0 +1,56 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// The options of a CoAP GET for /sensors/temperature with an Accept of
+// application/cbor (60) and a Size2 of 769, followed by a payload.
+var coapOptions = []byte{
+	0xb7, 's', 'e', 'n', 's', 'o', 'r', 's',
+	0x0b, 't', 'e', 'm', 'p', 'e', 'r', 'a', 't', 'u', 'r', 'e',
+	0x61, 0x3c,
+	0xb2, 0x03, 0x01,
+	0xff, 'h', 'i',
+}
+
+func TestCoAP(t *testing.T) {
+	opts, payload, err := DecodeCoAPOptions(coapOptions)
+	if err != nil {
+		FailWithError(t, "TestCoAP", err)
+	}
+	checkTags(t, "TestCoAP", opts, 11, 11, 17, 28)
+	if string(payload) != "hi" {
+		FailWithError(t, "TestCoAP",
+			fmt.Errorf("bad payload %q", payload))
+	} else if path := opts.GetAll(11); string(path[1].Value()) != "temperature" {
+		FailWithError(t, "TestCoAP", noMatch)
+	}
+
+	raw, err := EncodeCoAPOptions(opts, payload)
+	if err != nil {
+		FailWithError(t, "TestCoAP", err)
+	} else if !bytes.Equal(raw, coapOptions) {
+		FailWithError(t, "TestCoAP",
+			fmt.Errorf("options did not round-trip"))
+	}
+
+	long := New()
+	long.Add(1000, make([]byte, 300))
+	raw, err = EncodeCoAPOptions(long, nil)
+	if err != nil {
+		FailWithError(t, "TestCoAP", err)
+	} else if rlong, _, err := DecodeCoAPOptions(raw); err != nil {
+		FailWithError(t, "TestCoAP", err)
+	} else if rec, err := rlong.Get(1000); err != nil || rec.Length() != 300 {
+		FailWithError(t, "TestCoAP", noMatch)
+	}
+
+	opts.Add(4, nil)
+	if _, err = EncodeCoAPOptions(opts, nil); err != ErrOrder {
+		FailWithError(t, "TestCoAP",
+			fmt.Errorf("expected ErrOrder, got %v", err))
+	}
+}
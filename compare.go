@@ -0,0 +1,161 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Type CompareRules controls how CompareLists and CompareFiles treat each
+// tag. Records with tags in Ignore are not compared at all, which suits
+// volatile fields such as timestamps and nonces. Records with tags in
+// Exact must match byte for byte. Any other tag must appear the same
+// number of times in both lists, but its values may differ; if Exact is
+// nil, however, every tag not ignored must match exactly.
+type CompareRules struct {
+	Ignore []int
+	Exact  []int
+}
+
+// The kinds of Difference.
+const (
+	DiffMissing = "missing"
+	DiffExtra   = "extra"
+	DiffValue   = "value"
+)
+
+// Type Difference describes a mismatch between two lists for the Index'th
+// record with Tag. Kind is DiffMissing if the record is only in the
+// first list, DiffExtra if it is only in the second, and DiffValue if
+// the values differ; A and B hold the values from each list.
+type Difference struct {
+	Tag   int
+	Index int
+	Kind  string
+	A, B  []byte
+}
+
+// Type CompareReport holds the differences found by a comparison, ordered
+// by tag and then by index.
+type CompareReport struct {
+	Differences []Difference
+}
+
+// Equal reports whether the comparison found no differences.
+func (report *CompareReport) Equal() bool {
+	return len(report.Differences) == 0
+}
+
+// String returns a line describing each difference.
+func (report *CompareReport) String() string {
+	var sb strings.Builder
+	for _, d := range report.Differences {
+		fmt.Fprintf(&sb, "tag %d #%d: %s", d.Tag, d.Index, d.Kind)
+		if d.Kind == DiffValue {
+			fmt.Fprintf(&sb, " (%s != %s)", RenderHex(d.A), RenderHex(d.B))
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// CompareLists compares two lists under the given rules. Records are
+// matched up by tag and by their position among the records with that
+// tag, so records with different tags may be reordered freely.
+func CompareLists(a, b *TLVList, rules CompareRules) *CompareReport {
+	ignore := make(map[int]bool)
+	for _, tag := range rules.Ignore {
+		ignore[tag] = true
+	}
+	exact := make(map[int]bool)
+	for _, tag := range rules.Exact {
+		exact[tag] = true
+	}
+
+	values := func(recs *TLVList) map[int][][]byte {
+		vs := make(map[int][][]byte)
+		for _, tlv := range recs.records {
+			if !ignore[tlv.Tag()] {
+				vs[tlv.Tag()] = append(vs[tlv.Tag()], tlv.Value())
+			}
+		}
+		return vs
+	}
+	va, vb := values(a), values(b)
+
+	var tags []int
+	for tag := range va {
+		tags = append(tags, tag)
+	}
+	for tag := range vb {
+		if _, ok := va[tag]; !ok {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Ints(tags)
+
+	report := new(CompareReport)
+	for _, tag := range tags {
+		checkValue := rules.Exact == nil || exact[tag]
+		as, bs := va[tag], vb[tag]
+		for i := 0; i < len(as) || i < len(bs); i++ {
+			d := Difference{Tag: tag, Index: i}
+			switch {
+			case i >= len(bs):
+				d.Kind, d.A = DiffMissing, as[i]
+			case i >= len(as):
+				d.Kind, d.B = DiffExtra, bs[i]
+			case checkValue && !bytes.Equal(as[i], bs[i]):
+				d.Kind, d.A, d.B = DiffValue, as[i], bs[i]
+			default:
+				continue
+			}
+			report.Differences = append(report.Differences, d)
+		}
+	}
+	return report
+}
+
+// ListEquals reports whether two lists hold equal records. If ignoreOrder
+// is false, the records must also be in the same order. If it is true,
+// the lists are compared as multisets: each record in one must be
+// matched by an equal record in the other, in any order. Lists that are
+// equal this way have the same Hash.
+func ListEquals(a, b *TLVList, ignoreOrder bool) bool {
+	if a.Length() != b.Length() {
+		return false
+	} else if ignoreOrder {
+		a, b = a.unordered(), b.unordered()
+	}
+	for i := range a.records {
+		if !Equals(a.records[i], b.records[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func readListFile(path string) (*TLVList, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Read(file)
+}
+
+// CompareFiles reads the lists stored in the files at paths a and b and
+// compares them with CompareLists.
+func CompareFiles(a, b string, rules CompareRules) (*CompareReport, error) {
+	la, err := readListFile(a)
+	if err != nil {
+		return nil, err
+	}
+	lb, err := readListFile(b)
+	if err != nil {
+		return nil, err
+	}
+	return CompareLists(la, lb, rules), nil
+}
@@ -0,0 +1,61 @@
+package tlv
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeListFile(t *testing.T, path string, recs *TLVList) {
+	file, err := os.Create(path)
+	if err != nil {
+		FailWithError(t, "writeListFile", err)
+	}
+	defer file.Close()
+	if err = recs.Write(file); err != nil {
+		FailWithError(t, "writeListFile", err)
+	}
+}
+
+func TestCompareFiles(t *testing.T) {
+	a, b := New(), New()
+	a.Add(TagTest1, []byte("artifact"))
+	a.Add(TagTest2, []byte("2013-06-25"))
+	a.Add(TagTest3, []byte("nonce-a"))
+	b.Add(TagTest3, []byte("nonce-b"))
+	b.Add(TagTest1, []byte("artifact"))
+	b.Add(TagTest2, []byte("2013-06-26"))
+
+	dir := t.TempDir()
+	pa, pb := filepath.Join(dir, "a.tlv"), filepath.Join(dir, "b.tlv")
+	writeListFile(t, pa, a)
+	writeListFile(t, pb, b)
+
+	report, err := CompareFiles(pa, pb, CompareRules{Ignore: []int{TagTest2, TagTest3}})
+	if err != nil {
+		FailWithError(t, "TestCompareFiles", err)
+	} else if !report.Equal() {
+		FailWithError(t, "TestCompareFiles",
+			fmt.Errorf("unexpected differences:\n%s", report))
+	}
+
+	report = CompareLists(a, b, CompareRules{Ignore: []int{TagTest2}})
+	if len(report.Differences) != 1 || report.Differences[0].Kind != DiffValue {
+		FailWithError(t, "TestCompareFiles",
+			fmt.Errorf("expected a nonce difference:\n%s", report))
+	}
+
+	b.Add(TagTest4, nil)
+	report = CompareLists(a, b, CompareRules{Exact: []int{TagTest1}})
+	if len(report.Differences) != 1 || report.Differences[0].Tag != TagTest4 ||
+		report.Differences[0].Kind != DiffExtra {
+		FailWithError(t, "TestCompareFiles",
+			fmt.Errorf("expected an extra record:\n%s", report))
+	}
+
+	if _, err = CompareFiles(pa, filepath.Join(dir, "missing.tlv"), CompareRules{}); err == nil {
+		FailWithError(t, "TestCompareFiles",
+			fmt.Errorf("missing file should fail"))
+	}
+}
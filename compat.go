@@ -0,0 +1,139 @@
+package tlv
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Type Change describes one difference between two versions of a
+// schema, as reported by CompatCheck. Breaking changes are those that
+// may cause records written under one version to be misread, or
+// rejected, under the other.
+type Change struct {
+	Tag         int
+	Breaking    bool
+	Description string
+}
+
+// String returns a readable description of the change.
+func (c Change) String() string {
+	kind := "compatible"
+	if c.Breaking {
+		kind = "breaking"
+	}
+	return fmt.Sprintf("%s: tag %d: %s", kind, c.Tag, c.Description)
+}
+
+// CompatCheck compares two versions of a schema and returns the changes
+// between them, ordered by tag. Adding an optional tag, making a tag
+// optional, widening a tag's maximum length, or adding an enumerated
+// value is compatible. Removing or renaming a tag, adding a required
+// tag or making a tag required, narrowing a tag's maximum length, and
+// removing or renaming an enumerated value are breaking. Range changes
+// are reported against the range's minimum tag; removing or altering a
+// range is breaking.
+func CompatCheck(oldSchema, newSchema *Registry) []Change {
+	var changes []Change
+	report := func(tag int, breaking bool, format string, args ...interface{}) {
+		changes = append(changes, Change{tag, breaking, fmt.Sprintf(format, args...)})
+	}
+
+	tags := map[int]bool{}
+	for _, reg := range []*Registry{oldSchema, newSchema} {
+		for tag := range reg.names {
+			tags[tag] = true
+		}
+		for tag := range reg.enums {
+			tags[tag] = true
+		}
+		for tag := range reg.constraints {
+			tags[tag] = true
+		}
+	}
+
+	for tag := range tags {
+		oldName, inOld := oldSchema.Name(tag)
+		newName, inNew := newSchema.Name(tag)
+		oldC, newC := oldSchema.Constraint(tag), newSchema.Constraint(tag)
+		switch {
+		case inOld && !inNew:
+			report(tag, true, "removed %q", oldName)
+			continue
+		case !inOld && inNew:
+			report(tag, newC.Required, "added %q", newName)
+			continue
+		case oldName != newName:
+			report(tag, true, "renamed %q to %q", oldName, newName)
+		}
+
+		if !oldC.Required && newC.Required {
+			report(tag, true, "made required")
+		} else if oldC.Required && !newC.Required {
+			report(tag, false, "made optional")
+		}
+
+		switch {
+		case oldC.MaxLength == newC.MaxLength:
+		case newC.MaxLength != 0 && (oldC.MaxLength == 0 || newC.MaxLength < oldC.MaxLength):
+			report(tag, true, "maximum length narrowed to %d", newC.MaxLength)
+		default:
+			report(tag, false, "maximum length widened")
+		}
+
+		oldEnum, newEnum := oldSchema.enums[tag], newSchema.enums[tag]
+		for value, name := range oldEnum {
+			if newEnum == nil {
+				break
+			} else if newName, ok := newEnum[value]; !ok {
+				report(tag, true, "removed value %d (%q)", value, name)
+			} else if newName != name {
+				report(tag, true, "renamed value %d from %q to %q", value, name, newName)
+			}
+		}
+		for value, name := range newEnum {
+			if _, ok := oldEnum[value]; !ok && oldEnum != nil {
+				report(tag, false, "added value %d (%q)", value, name)
+			}
+		}
+		if oldEnum == nil && newEnum != nil {
+			report(tag, true, "made enumerated")
+		} else if oldEnum != nil && newEnum == nil {
+			report(tag, false, "no longer enumerated")
+		}
+	}
+
+	oldRanges := map[string]Range{}
+	for _, r := range oldSchema.ranges {
+		oldRanges[r.Name] = r
+	}
+	for _, r := range newSchema.ranges {
+		old, ok := oldRanges[r.Name]
+		delete(oldRanges, r.Name)
+		if !ok {
+			report(r.Min, false, "added range %q", r.Name)
+		} else if old != r {
+			report(r.Min, true, "changed range %q", r.Name)
+		}
+	}
+	for _, r := range oldRanges {
+		report(r.Min, true, "removed range %q", r.Name)
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Tag != changes[j].Tag {
+			return changes[i].Tag < changes[j].Tag
+		}
+		return changes[i].Description < changes[j].Description
+	})
+	return changes
+}
+
+// Breaking reports whether any of the changes are breaking.
+func Breaking(changes []Change) bool {
+	for _, c := range changes {
+		if c.Breaking {
+			return true
+		}
+	}
+	return false
+}
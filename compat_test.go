@@ -0,0 +1,51 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestCompatCheck(t *testing.T) {
+	oldSchema := testRegistry()
+	oldSchema.Constrain(TagTest2, Constraint{MaxLength: 64})
+	oldSchema.SetEnum(TagTest3, map[byte]string{0: "off", 1: "on"})
+
+	newSchema := testRegistry()
+	newSchema.Register(TagTest4, "test-four")
+	newSchema.Constrain(TagTest2, Constraint{MaxLength: 128})
+	newSchema.SetEnum(TagTest3, map[byte]string{0: "off", 1: "on", 2: "auto"})
+	if changes := CompatCheck(oldSchema, newSchema); len(changes) != 3 {
+		FailWithError(t, "TestCompatCheck",
+			fmt.Errorf("expected 3 changes, got %v", changes))
+	} else if Breaking(changes) {
+		FailWithError(t, "TestCompatCheck",
+			fmt.Errorf("changes should be compatible: %v", changes))
+	}
+
+	newSchema.Constrain(TagTest2, Constraint{MaxLength: 32})
+	changes := CompatCheck(oldSchema, newSchema)
+	if !Breaking(changes) || changes[0].Tag != TagTest2 || !changes[0].Breaking {
+		FailWithError(t, "TestCompatCheck",
+			fmt.Errorf("narrowed length should be breaking: %v", changes))
+	}
+
+	newSchema = NewRegistry()
+	newSchema.Register(TagTest1, "test-one")
+	newSchema.Register(TagTest2, "test-2")
+	changes = CompatCheck(oldSchema, newSchema)
+	if len(changes) != 3 || !changes[1].Breaking || !changes[2].Breaking {
+		FailWithError(t, "TestCompatCheck",
+			fmt.Errorf("rename and removal should be breaking: %v", changes))
+	}
+
+	buf := new(bytes.Buffer)
+	oldSchema.WriteSchema(buf)
+	rreg, err := ReadSchema(buf)
+	if err != nil {
+		FailWithError(t, "TestCompatCheck", err)
+	} else if changes = CompatCheck(oldSchema, rreg); len(changes) != 0 {
+		FailWithError(t, "TestCompatCheck",
+			fmt.Errorf("schema changed in round trip: %v", changes))
+	}
+}
@@ -0,0 +1,192 @@
+package tlv
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+)
+
+// TagCompressed is the tag of a compressed record. Its value is an
+// extended header, made up of a one-byte Compression identifying the
+// algorithm and the original record's tag as a 32-bit big-endian
+// integer, followed by the compressed value.
+const TagCompressed = 0x7fffff04
+
+// Type Compression identifies a compression algorithm in the header of a
+// compressed record.
+type Compression byte
+
+// The compression algorithms with assigned identifiers. Only
+// CompressGzip is available by default; other algorithms must be
+// provided with RegisterCompression before they can be used.
+const (
+	CompressNone Compression = 0
+	CompressGzip Compression = 1
+	CompressZstd Compression = 2
+	CompressLZ4  Compression = 3
+)
+
+// Type CompressedPolicy determines what a Decoder does with a compressed
+// record that it cannot decompress.
+type CompressedPolicy int
+
+const (
+	// CompressedStrict causes Decode to fail with
+	// ErrUnsupportedCompression.
+	CompressedStrict CompressedPolicy = iota
+
+	// CompressedSkip causes the record to be skipped.
+	CompressedSkip
+)
+
+// ErrUnsupportedCompression is returned when a record is compressed with
+// an algorithm that has not been registered or accepted.
+var ErrUnsupportedCompression = fmt.Errorf("unsupported TLV compression")
+
+type compressor struct {
+	compress   func(value []byte) ([]byte, error)
+	decompress func(r io.Reader) io.Reader
+}
+
+var compressors = struct {
+	sync.RWMutex
+	m map[Compression]compressor
+}{m: map[Compression]compressor{CompressGzip: {gzipCompress, gzipDecompress}}}
+
+// RegisterCompression makes a compression algorithm available to
+// Encoders and Decoders. compress returns the compressed form of a
+// value, and decompress returns a reader producing the decompressed form
+// of its input, reporting any corruption as a read error.
+func RegisterCompression(c Compression, compress func(value []byte) ([]byte, error), decompress func(r io.Reader) io.Reader) {
+	compressors.Lock()
+	defer compressors.Unlock()
+	compressors.m[c] = compressor{compress, decompress}
+}
+
+func lookupCompression(c Compression) (comp compressor, ok bool) {
+	compressors.RLock()
+	defer compressors.RUnlock()
+	comp, ok = compressors.m[c]
+	return
+}
+
+func gzipCompress(value []byte) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	zw := gzip.NewWriter(buf)
+	if _, err := zw.Write(value); err != nil {
+		return nil, err
+	} else if err = zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type errReader struct {
+	err error
+}
+
+func (er errReader) Read([]byte) (int, error) {
+	return 0, er.err
+}
+
+func gzipDecompress(r io.Reader) io.Reader {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return errReader{err}
+	}
+	return zr
+}
+
+// compress returns the compressed form of a record, or the record itself
+// if compression would not make it smaller.
+func (enc *Encoder) compress(tlv TLV) (TLV, error) {
+	comp, ok := lookupCompression(enc.Compression)
+	if !ok {
+		return nil, ErrUnsupportedCompression
+	} else if int(int32(tlv.Tag())) != tlv.Tag() {
+		return nil, ErrTagRange
+	}
+
+	packed, err := comp.compress(tlv.Value())
+	if err != nil {
+		return nil, err
+	} else if len(packed)+5 >= tlv.Length() {
+		return tlv, nil
+	}
+
+	value := make([]byte, 5, 5+len(packed))
+	value[0] = byte(enc.Compression)
+	binary.BigEndian.PutUint32(value[1:], uint32(tlv.Tag()))
+	value = append(value, packed...)
+	return &record{tag: TagCompressed, length: len(value), value: value}, nil
+}
+
+// defaultMaxDecompressed is the longest value a compressed record may
+// decompress to when a Decoder sets neither MaxDecompressed nor
+// MaxLength.
+const defaultMaxDecompressed = 64 << 20
+
+// maxDecompressed returns the longest value the Decoder will produce
+// by decompressing a record.
+func (dec *Decoder) maxDecompressed() int {
+	switch {
+	case dec.MaxDecompressed > 0:
+		return dec.MaxDecompressed
+	case dec.MaxLength > 0:
+		return dec.MaxLength
+	}
+	return defaultMaxDecompressed
+}
+
+// errSkip is returned by decompress when a record should be skipped.
+var errSkip = fmt.Errorf("skip record")
+
+func (dec *Decoder) accepts(c Compression) bool {
+	if dec.Accept == nil {
+		return true
+	}
+	for _, accepted := range dec.Accept {
+		if accepted == c {
+			return true
+		}
+	}
+	return false
+}
+
+// decompress restores the original record from a compressed record.
+func (dec *Decoder) decompress(tlv TLV) (TLV, error) {
+	value := tlv.Value()
+	if len(value) < 5 {
+		return nil, ErrTLVRead
+	}
+
+	c := Compression(value[0])
+	comp, ok := lookupCompression(c)
+	if !ok || !dec.accepts(c) {
+		if dec.Compressed == CompressedSkip {
+			return nil, errSkip
+		}
+		return nil, ErrUnsupportedCompression
+	}
+
+	tag := int(int32(binary.BigEndian.Uint32(value[1:])))
+	if dec.Order != nil {
+		if err := dec.checkOrder(tag); err != nil {
+			return nil, err
+		}
+	}
+
+	limit := dec.maxDecompressed()
+	r := io.LimitReader(comp.decompress(bytes.NewReader(value[5:])), int64(limit)+1)
+	unpacked, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, ErrTLVRead
+	} else if len(unpacked) > limit {
+		return nil, ErrTooLarge
+	}
+	return &record{tag: tag, length: len(unpacked), value: unpacked}, nil
+}
@@ -0,0 +1,125 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func compressTestStream(t *testing.T, c Compression) []byte {
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+	enc.Compression = c
+	if err := enc.Encode(newTLV(TagTest1, bytes.Repeat([]byte("gopher "), 100))); err != nil {
+		FailWithError(t, "compressTestStream", err)
+	} else if err = enc.Encode(newTLV(TagTest2, []byte("short"))); err != nil {
+		FailWithError(t, "compressTestStream", err)
+	}
+	return buf.Bytes()
+}
+
+func TestCompression(t *testing.T) {
+	raw := compressTestStream(t, CompressGzip)
+	if len(raw) > 200 {
+		FailWithError(t, "TestCompression",
+			fmt.Errorf("stream was not compressed"))
+	}
+
+	exts, err := Extents(bytes.NewReader(raw))
+	if err != nil {
+		FailWithError(t, "TestCompression", err)
+	} else if exts[0].Tag != TagCompressed || exts[1].Tag != TagTest2 {
+		FailWithError(t, "TestCompression",
+			fmt.Errorf("only the long record should be compressed"))
+	}
+
+	tlvl := decodeFormat(t, "TestCompression", Standard, raw)
+	checkTags(t, "TestCompression", tlvl, TagTest1, TagTest2)
+	if rec, _ := tlvl.Get(TagTest1); rec.Length() != 700 {
+		FailWithError(t, "TestCompression", noMatch)
+	}
+
+	dec := NewDecoder(bytes.NewReader(raw))
+	dec.MaxLength = 100
+	if _, err = dec.Decode(); err != ErrTooLarge {
+		FailWithError(t, "TestCompression",
+			fmt.Errorf("decompressed length should be limited"))
+	}
+
+	dec = NewDecoder(bytes.NewReader(raw))
+	dec.MaxLength = 100
+	dec.MaxDecompressed = 1000
+	if rec, err := dec.Decode(); err != nil {
+		FailWithError(t, "TestCompression", err)
+	} else if rec.Length() != 700 {
+		FailWithError(t, "TestCompression", noMatch)
+	}
+
+	dec = NewDecoder(bytes.NewReader(raw))
+	dec.MaxDecompressed = 100
+	if _, err = dec.Decode(); err != ErrTooLarge {
+		FailWithError(t, "TestCompression",
+			fmt.Errorf("decompressed length should be limited"))
+	}
+
+	if NewDecoder(nil).maxDecompressed() != defaultMaxDecompressed {
+		FailWithError(t, "TestCompression",
+			fmt.Errorf("decompression should be limited by default"))
+	}
+
+	// A record may inflate past the size of an in-memory input.
+	if tlvl, err = decodeBytes(raw); err != nil {
+		FailWithError(t, "TestCompression", err)
+	}
+	checkTags(t, "TestCompression", tlvl, TagTest1, TagTest2)
+}
+
+// registerTestCompression registers a compression algorithm for the
+// rest of a test, restoring the previous registration afterwards.
+func registerTestCompression(t *testing.T, c Compression, compress func(value []byte) ([]byte, error), decompress func(r io.Reader) io.Reader) {
+	prev, ok := lookupCompression(c)
+	t.Cleanup(func() {
+		compressors.Lock()
+		defer compressors.Unlock()
+		if ok {
+			compressors.m[c] = prev
+		} else {
+			delete(compressors.m, c)
+		}
+	})
+	RegisterCompression(c, compress, decompress)
+}
+
+func TestCompressionNegotiation(t *testing.T) {
+	registerTestCompression(t, CompressZstd, func(value []byte) ([]byte, error) {
+		return value[:len(value)/2], nil
+	}, func(r io.Reader) io.Reader {
+		return io.MultiReader(r)
+	})
+
+	raw := compressTestStream(t, CompressZstd)
+
+	dec := NewDecoder(bytes.NewReader(raw))
+	dec.Accept = []Compression{CompressGzip}
+	if _, err := dec.Decode(); err != ErrUnsupportedCompression {
+		FailWithError(t, "TestCompressionNegotiation",
+			fmt.Errorf("unaccepted algorithm should fail"))
+	}
+
+	dec = NewDecoder(bytes.NewReader(raw))
+	dec.Accept = []Compression{CompressGzip}
+	dec.Compressed = CompressedSkip
+	tlvl, err := dec.DecodeList()
+	if err != nil {
+		FailWithError(t, "TestCompressionNegotiation", err)
+	}
+	checkTags(t, "TestCompressionNegotiation", tlvl, TagTest2)
+
+	enc := NewEncoder(new(bytes.Buffer))
+	enc.Compression = CompressLZ4
+	if err = enc.Encode(newTLV(TagTest1, nil)); err != ErrUnsupportedCompression {
+		FailWithError(t, "TestCompressionNegotiation",
+			fmt.Errorf("unregistered algorithm should fail"))
+	}
+}
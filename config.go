@@ -0,0 +1,113 @@
+package tlv
+
+import (
+	"flag"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// SetKind sets the kind of value carried by records with the given tag,
+// using the kinds understood by ParseSpec: hex, str, u8, u16, u32 or
+// u64. The kind determines how BindFlags and FromEnv parse values given
+// as text; tags without a kind are treated as str. ErrSpec is returned
+// for an unknown kind.
+func (reg *Registry) SetKind(tag int, kind string) error {
+	switch kind {
+	case "hex", "str", "u8", "u16", "u32", "u64":
+	default:
+		return ErrSpec
+	}
+	if reg.kinds == nil {
+		reg.kinds = make(map[int]string)
+	}
+	reg.kinds[tag] = kind
+	return nil
+}
+
+// Kind returns the kind of value set for tag, or str if none was set.
+func (reg *Registry) Kind(tag int) string {
+	if kind, ok := reg.kinds[tag]; ok {
+		return kind
+	}
+	return "str"
+}
+
+// parseKind parses the text form of a value with the given tag.
+func (reg *Registry) parseKind(tag int, text string) ([]byte, error) {
+	return specValue(reg.Kind(tag), text)
+}
+
+// flagValue adds a record to a list each time its flag is set.
+type flagValue struct {
+	reg  *Registry
+	recs *TLVList
+	tag  int
+}
+
+func (fv *flagValue) String() string {
+	return ""
+}
+
+func (fv *flagValue) Set(text string) error {
+	value, err := fv.reg.parseKind(fv.tag, text)
+	if err != nil {
+		return err
+	}
+	fv.recs.Add(fv.tag, value)
+	return nil
+}
+
+// BindFlags defines a flag in fs for every name registered in reg, and
+// returns the TLVList that the flags populate as fs is parsed. Each
+// occurrence of a flag adds a record, in the order the flags are given,
+// with its value parsed according to the tag's kind.
+func BindFlags(fs *flag.FlagSet, reg *Registry) *TLVList {
+	recs := New()
+	for name, tag := range reg.tags {
+		usage := "TLV record " + reg.Kind(tag) + " value"
+		fs.Var(&flagValue{reg: reg, recs: recs, tag: tag}, name, usage)
+	}
+	return recs
+}
+
+// FromEnv builds a TLVList from environment variables, one record for
+// each registered name whose variable is set. The variable for a name is
+// prefix followed by the name in upper case, with any character other
+// than a letter or digit replaced by an underscore: with prefix "APP_",
+// the name "max-size" is read from APP_MAX_SIZE. Values are parsed
+// according to each tag's kind, and records are ordered by tag.
+func FromEnv(prefix string, reg *Registry) (*TLVList, error) {
+	fields := make(map[int]string)
+	for name, tag := range reg.tags {
+		if text, ok := os.LookupEnv(prefix + envName(name)); ok {
+			fields[tag] = text
+		}
+	}
+
+	tags := make([]int, 0, len(fields))
+	for tag := range fields {
+		tags = append(tags, tag)
+	}
+	sort.Ints(tags)
+
+	recs := New()
+	for _, tag := range tags {
+		value, err := reg.parseKind(tag, fields[tag])
+		if err != nil {
+			return nil, err
+		}
+		recs.Add(tag, value)
+	}
+	return recs, nil
+}
+
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, name)
+}
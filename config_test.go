@@ -0,0 +1,64 @@
+package tlv
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"testing"
+)
+
+func configRegistry(t *testing.T) *Registry {
+	reg := NewRegistry()
+	reg.Register(TagTest1, "name")
+	reg.Register(TagTest2, "max-size")
+	reg.Register(TagTest3, "key")
+	if err := reg.SetKind(TagTest2, "u16"); err != nil {
+		FailWithError(t, "configRegistry", err)
+	}
+	if err := reg.SetKind(TagTest3, "hex"); err != nil {
+		FailWithError(t, "configRegistry", err)
+	}
+	if err := reg.SetKind(TagTest4, "float"); err != ErrSpec {
+		FailWithError(t, "configRegistry", noMatch)
+	}
+	return reg
+}
+
+func TestBindFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	recs := BindFlags(fs, configRegistry(t))
+	err := fs.Parse([]string{"-key", "a1b2", "-max-size", "512", "-key", "ff"})
+	if err != nil {
+		FailWithError(t, "TestBindFlags", err)
+	}
+
+	want, err := ParseSpec("2=hex:a1b2, 1=u16:512, 2=hex:ff")
+	if err != nil {
+		FailWithError(t, "TestBindFlags", err)
+	}
+	if recs.Spec() != want.Spec() {
+		FailWithError(t, "TestBindFlags", noMatch)
+	}
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(new(bytes.Buffer))
+	BindFlags(fs, configRegistry(t))
+	if err = fs.Parse([]string{"-max-size", "70000"}); err == nil {
+		FailWithError(t, "TestBindFlags", noMatch)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	os.Setenv("TLVTEST_NAME", "gopher")
+	os.Setenv("TLVTEST_MAX_SIZE", "0x10")
+	defer os.Unsetenv("TLVTEST_NAME")
+	defer os.Unsetenv("TLVTEST_MAX_SIZE")
+
+	recs, err := FromEnv("TLVTEST_", configRegistry(t))
+	if err != nil {
+		FailWithError(t, "TestFromEnv", err)
+	}
+	if recs.Spec() != "0=str:gopher, 1=hex:0010" {
+		FailWithError(t, "TestFromEnv", noMatch)
+	}
+}
@@ -0,0 +1,314 @@
+package tlv
+
+import (
+	"fmt"
+	"hash"
+	"io"
+	"time"
+)
+
+// ErrTooLarge is returned by a Decoder when its input exceeds one of the
+// limits it was configured with.
+var ErrTooLarge = fmt.Errorf("TLV input exceeds limit")
+
+// Type Decoder reads TLV records from an input stream. The limits on a
+// Decoder are off by default; they should be set when the input comes
+// from an untrusted source.
+type Decoder struct {
+	// Format is the wire format records are read in. If nil, Standard
+	// is used.
+	Format Format
+
+	// MaxLength, if nonzero, is the longest value the Decoder will
+	// accept. Longer records are rejected before any space is
+	// allocated for their values.
+	MaxLength int
+
+	// MaxDecompressed, if nonzero, is the longest value a compressed
+	// record may decompress to. If it is zero, MaxLength is used
+	// instead, or 64 MiB if MaxLength is also zero, so that a small
+	// record cannot inflate without bound.
+	MaxDecompressed int
+
+	// MaxRecords, if nonzero, is the largest number of records that
+	// DecodeList will return.
+	MaxRecords int
+
+	// Order, if set, is the order in which tags must appear. Tags in
+	// Order may be repeated or omitted, but a record is rejected with
+	// ErrOrder if it follows a record whose tag comes later in Order.
+	// Tags that are not in Order may appear anywhere. Order must not
+	// be changed once decoding has begun.
+	Order []int
+
+	// Accept, if set, lists the compression algorithms the Decoder
+	// will decompress; otherwise, every registered algorithm is
+	// accepted. Compressed controls what happens to records that
+	// cannot be decompressed.
+	Accept     []Compression
+	Compressed CompressedPolicy
+
+	// Repair, if set, maps single-valued tags to the policy used by
+	// DecodeList to reconcile duplicate records with that tag, such
+	// as those left by multiple writers of a replicated file.
+	Repair map[int]RepairPolicy
+
+	// Allow, if set, is the registry of tags the Decoder accepts.
+	// Records with tags that are neither registered nor within one of
+	// the registry's ranges are handled according to Unknown. Control
+	// records, such as signatures and tombstones, are always accepted;
+	// unassigned tags in the reserved range are treated like any other.
+	Allow   *Registry
+	Unknown UnknownPolicy
+
+	// Align, if greater than one, is the alignment records were
+	// written with by an Encoder; the padding after each record is
+	// skipped. As for an Encoder, alignments above 4096 bytes are
+	// rejected with ErrAlign.
+	Align int
+
+	// Skew, if set, checks that timestamp records are in order, so
+	// that out-of-order batches are caught before they reach
+	// time-series storage. A timestamp may be up to MaxSkew earlier
+	// than the latest seen so far.
+	Skew    SkewPolicy
+	MaxSkew time.Duration
+
+	// Legacy, if set, treats records with reserved tags as ordinary
+	// records, for reading files written before the range was
+	// reserved: compressed records are not decompressed, and the
+	// allowlist and skew checks do not treat them specially. See
+	// RemapReserved.
+	Legacy bool
+
+	// Nested, if set, reports whether a record's value holds further
+	// records. Such records are decoded recursively, with the same
+	// configuration except for Align and Skew, which apply only to the
+	// top-level records, and returned as Constructed records; their
+	// contents must decode cleanly. Records are nested at most
+	// MaxDepth levels deep, or 32 if MaxDepth is zero; deeper records
+	// are rejected with ErrTooLarge.
+	Nested   func(rec TLV) bool
+	MaxDepth int
+
+	r       *countingReader
+	unknown *TLVList
+
+	orderIndex map[int]int
+	orderPos   int
+
+	digest   hash.Hash
+	verify   func(digest, sig []byte) error
+	verified bool
+	closer   io.Closer
+
+	latest time.Time
+	skewed bool
+
+	summary *summaryState
+	depth   int
+}
+
+// NewDecoder returns a new Decoder that reads from r. The Decoder never
+// reads past the end of the record it is decoding.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: &countingReader{r: r}}
+}
+
+// Reset discards the Decoder's state and switches it to reading from r,
+// as if it had been newly created by NewDecoder, but keeps its
+// configuration: Format, the limits, Order, and the other exported
+// fields. Signature verification must be set up again with Verify.
+func (dec *Decoder) Reset(r io.Reader) {
+	dec.r.r, dec.r.n = r, 0
+	dec.unknown = nil
+	dec.orderPos = 0
+	dec.digest, dec.verify, dec.verified = nil, nil, false
+	dec.closer = nil
+	dec.latest, dec.skewed = time.Time{}, false
+	dec.summary = nil
+}
+
+func (dec *Decoder) format() Format {
+	if dec.Format == nil {
+		return Standard
+	}
+	return dec.Format
+}
+
+// Decode reads the next record from the stream. At the end of the input,
+// Decode returns io.EOF; if the input ends partway through a record,
+// ErrTLVRead is returned. Compressed records are returned decompressed.
+func (dec *Decoder) Decode() (rec TLV, err error) {
+	for {
+		rec, err = dec.decode()
+		if err == nil && rec.Tag() == TagCompressed && !dec.Legacy {
+			rec, err = dec.decompress(rec)
+		}
+		if err == nil && dec.Allow != nil {
+			err = dec.checkAllowed(rec)
+		}
+		if err == nil && dec.Nested != nil && dec.Nested(rec) {
+			rec, err = dec.nest(rec)
+		}
+		if err == nil && dec.Skew != SkewIgnore && rec.Tag() == TagTimestamp && !dec.Legacy {
+			err = dec.checkSkew(rec)
+		}
+		if err != errSkip {
+			return
+		}
+	}
+}
+
+func (dec *Decoder) decode() (rec TLV, err error) {
+	if dec.verified {
+		return nil, io.EOF
+	}
+	if err = checkAlign(dec.Align); err != nil {
+		return
+	}
+
+	// Signatures and summaries cover the header bytes as read, not
+	// as they would be written, so the header is kept for them.
+	start := dec.r.n
+	dec.r.keep, dec.r.kept = dec.summary != nil || dec.verify != nil, dec.r.kept[:0]
+	tag, length, err := dec.format().ReadHeader(dec.r)
+	dec.r.keep = false
+	header := dec.r.kept
+	if err == io.EOF && dec.summary != nil {
+		return nil, ErrNoSummary
+	} else if err == io.EOF && dec.verify != nil {
+		return nil, ErrUnsigned
+	} else if err == io.ErrUnexpectedEOF {
+		return nil, ErrTLVRead
+	} else if err != nil {
+		return
+	} else if length < 0 {
+		return nil, ErrLengthRange
+	} else if dec.MaxLength > 0 && length > dec.MaxLength {
+		return nil, ErrTooLarge
+	} else if dec.Order != nil {
+		if err = dec.checkOrder(tag); err != nil {
+			return
+		}
+	}
+
+	tlv := &record{tag: tag, length: length}
+	if tlv.value, err = readValue(dec.r, length); err != nil {
+		return nil, ErrTLVRead
+	} else if err = dec.skipPadding(); err != nil {
+		return nil, err
+	}
+
+	if dec.summary != nil {
+		if err = dec.checkSummary(tlv, header, start); err != nil {
+			return nil, err
+		}
+	}
+
+	if dec.verify != nil {
+		var done bool
+		if done, err = dec.checkSignature(tlv, header); err != nil {
+			return nil, err
+		} else if done {
+			return nil, io.EOF
+		}
+	}
+	return tlv, nil
+}
+
+// DecodeList reads records until the end of the stream, returning them
+// as a TLVList. Duplicates of tags with a Repair policy are reconciled
+// with the first record with the tag, in place.
+func (dec *Decoder) DecodeList() (recs *TLVList, err error) {
+	recs = New()
+	singles := make(map[int]int)
+	for {
+		var tlv TLV
+		if tlv, err = dec.Decode(); err != nil {
+			break
+		}
+
+		policy, single := dec.Repair[tlv.Tag()]
+		if i, ok := singles[tlv.Tag()]; ok {
+			kept, perr := policy(recs.records[i], tlv)
+			if perr != nil {
+				return recs, perr
+			}
+			recs.records[i] = kept
+			continue
+		}
+
+		if dec.MaxRecords > 0 && recs.Length() == dec.MaxRecords {
+			return recs, ErrTooLarge
+		}
+		if single {
+			singles[tlv.Tag()] = recs.Length()
+		}
+		recs.records = append(recs.records, tlv)
+	}
+
+	return recs, eof(err)
+}
+
+// eof clears err if it marks the clean end of a stream.
+func eof(err error) error {
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+// Offset returns the number of bytes the Decoder has consumed from its
+// input; between calls to Decode, this is the offset of the next record.
+func (dec *Decoder) Offset() int64 {
+	return dec.r.n
+}
+
+// Close releases the Decoder's input. If the Decoder was created by
+// NewPipe, the pipe is closed, and any writes blocked on the paired
+// Encoder fail.
+func (dec *Decoder) Close() error {
+	if dec.closer != nil {
+		return dec.closer.Close()
+	}
+	return nil
+}
+
+// countingReader tracks how much of the Decoder's input has been read,
+// and provides the single-byte reads needed by variable-length formats.
+type countingReader struct {
+	r io.Reader
+	n int64
+	b [1]byte
+
+	// If keep is set, the bytes read are appended to kept, so that
+	// a record's header can be hashed as it was read.
+	keep bool
+	kept []byte
+}
+
+func (cr *countingReader) Read(p []byte) (n int, err error) {
+	n, err = cr.r.Read(p)
+	cr.n += int64(n)
+	if cr.keep {
+		cr.kept = append(cr.kept, p[:n]...)
+	}
+	return
+}
+
+func (cr *countingReader) ReadByte() (byte, error) {
+	if br, ok := cr.r.(io.ByteReader); ok {
+		c, err := br.ReadByte()
+		if err == nil {
+			cr.n++
+			if cr.keep {
+				cr.kept = append(cr.kept, c)
+			}
+		}
+		return c, err
+	}
+
+	_, err := io.ReadFull(cr, cr.b[:])
+	return cr.b[0], err
+}
@@ -0,0 +1,133 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestDecoder(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(TagTest1, []byte("foo bar"))
+	tlvl.Add(TagTest2, nil)
+	tlvl.Add(TagTest3, []byte("gophers are everywhere!"))
+
+	buf := new(bytes.Buffer)
+	if err := tlvl.Write(buf); err != nil {
+		FailWithError(t, "TestDecoder", err)
+	}
+	raw := buf.Bytes()
+
+	dec := NewDecoder(bytes.NewBuffer(raw))
+	for _, tlv := range tlvl.records {
+		rec, err := dec.Decode()
+		if err != nil {
+			FailWithError(t, "TestDecoder", err)
+		} else if !Equals(rec, tlv) {
+			FailWithError(t, "TestDecoder", noMatch)
+		}
+	}
+	if dec.Offset() != int64(len(raw)) {
+		FailWithError(t, "TestDecoder",
+			fmt.Errorf("decoder offset is %d, expected %d",
+				dec.Offset(), len(raw)))
+	}
+	if _, err := dec.Decode(); err != io.EOF {
+		FailWithError(t, "TestDecoder",
+			fmt.Errorf("expected EOF, got %v", err))
+	}
+
+	dec = NewDecoder(bytes.NewBuffer(raw[:len(raw)-1]))
+	if _, err := dec.DecodeList(); err != ErrTLVRead {
+		FailWithError(t, "TestDecoder",
+			fmt.Errorf("truncated input should fail"))
+	}
+
+	dec = NewDecoder(bytes.NewBuffer(raw[:20]))
+	if _, err := dec.DecodeList(); err != ErrTLVRead {
+		FailWithError(t, "TestDecoder",
+			fmt.Errorf("truncated header should fail"))
+	}
+}
+
+func TestDecoderLimits(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(TagTest1, []byte("foo bar"))
+	tlvl.Add(TagTest2, []byte("baz quux"))
+
+	buf := new(bytes.Buffer)
+	if err := tlvl.Write(buf); err != nil {
+		FailWithError(t, "TestDecoderLimits", err)
+	}
+	raw := buf.Bytes()
+
+	dec := NewDecoder(bytes.NewBuffer(raw))
+	dec.MaxLength = 7
+	if _, err := dec.DecodeList(); err != ErrTooLarge {
+		FailWithError(t, "TestDecoderLimits",
+			fmt.Errorf("long value should be rejected"))
+	}
+
+	dec = NewDecoder(bytes.NewBuffer(raw))
+	dec.MaxRecords = 1
+	if _, err := dec.DecodeList(); err != ErrTooLarge {
+		FailWithError(t, "TestDecoderLimits",
+			fmt.Errorf("too many records should be rejected"))
+	}
+
+	dec = NewDecoder(bytes.NewBuffer([]byte{0, 0, 0, 1, 0xff, 0xff, 0xff, 0xff}))
+	if _, err := dec.Decode(); err != ErrLengthRange {
+		FailWithError(t, "TestDecoderLimits",
+			fmt.Errorf("negative length should be rejected"))
+	}
+
+	// Without a MaxLength, a header claiming a 2 GB value must not
+	// cost 2 GB when the value is not there.
+	hostile := []byte{0, 0, 0, 1, 0x7f, 0xff, 0xff, 0xff, 'a', 'b'}
+	var err error
+	n := allocatedBy(func() {
+		_, err = NewDecoder(bytes.NewReader(hostile)).Decode()
+	})
+	if err != ErrTLVRead {
+		FailWithError(t, "TestDecoderLimits",
+			fmt.Errorf("expected ErrTLVRead, got %v", err))
+	} else if n > 1<<20 {
+		FailWithError(t, "TestDecoderLimits",
+			fmt.Errorf("%d bytes allocated for a short input", n))
+	}
+}
+
+func TestEncoderDecoderVarint(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(TagTest1, []byte("foo bar"))
+	tlvl.Add(300, []byte("baz quux"))
+
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+	enc.Format = Varint
+	if err := enc.EncodeList(tlvl); err != nil {
+		FailWithError(t, "TestEncoderDecoderVarint", err)
+	} else if buf.Len() != 2+7+3+8 {
+		FailWithError(t, "TestEncoderDecoderVarint",
+			fmt.Errorf("unexpected encoded length %d", buf.Len()))
+	}
+
+	dec := NewDecoder(buf)
+	dec.Format = Varint
+	rtlvl, err := dec.DecodeList()
+	if err != nil {
+		FailWithError(t, "TestEncoderDecoderVarint", err)
+	} else if rtlvl.Length() != 2 {
+		FailWithError(t, "TestEncoderDecoderVarint", noMatch)
+	} else if rec, err := rtlvl.Get(300); err != nil {
+		FailWithError(t, "TestEncoderDecoderVarint", err)
+	} else if string(rec.Value()) != "baz quux" {
+		FailWithError(t, "TestEncoderDecoderVarint", noMatch)
+	}
+
+	if err = enc.Encode(newTLV(-1, nil)); err != ErrTagRange {
+		FailWithError(t, "TestEncoderDecoderVarint",
+			fmt.Errorf("negative tag should not encode"))
+	}
+}
@@ -0,0 +1,49 @@
+package tlv
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// DGI is the format of GlobalPlatform data grouping identifiers, used to
+// personalize card content (GlobalPlatform Card Specification, STORE
+// DATA): a 16-bit big-endian identifier followed by a length of one
+// byte, or, for lengths of 255 or more, the byte 0xFF and a 16-bit
+// big-endian length. Tags must be between 0 and 65535, and values may
+// be at most 65535 bytes long. The contents of many DGIs are themselves
+// BER-TLV records.
+var DGI Format = dgiFormat{}
+
+type dgiFormat struct{}
+
+func (dgiFormat) ReadHeader(r io.Reader) (tag, length int, err error) {
+	var hdr [3]byte
+	if _, err = io.ReadFull(r, hdr[:]); err != nil {
+		return
+	}
+	tag = int(binary.BigEndian.Uint16(hdr[:]))
+	if hdr[2] != 0xff {
+		return tag, int(hdr[2]), nil
+	}
+
+	var ext [2]byte
+	if _, err = io.ReadFull(r, ext[:]); err != nil {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	return tag, int(binary.BigEndian.Uint16(ext[:])), nil
+}
+
+func (dgiFormat) WriteHeader(w io.Writer, tag, length int) error {
+	if tag < 0 || tag > 0xffff {
+		return ErrTagRange
+	} else if length < 0 || length > 0xffff {
+		return ErrLengthRange
+	}
+
+	hdr := []byte{byte(tag >> 8), byte(tag), byte(length)}
+	if length >= 0xff {
+		hdr = append(hdr[:2], 0xff, byte(length>>8), byte(length))
+	}
+	_, err := w.Write(hdr)
+	return err
+}
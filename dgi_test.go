@@ -0,0 +1,24 @@
+package tlv
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDGI(t *testing.T) {
+	recs := New()
+	recs.Add(0x0101, []byte{0x5f, 0x20, 0x02, 'J', 'D'})
+	recs.Add(0x8010, bytes.Repeat([]byte{0x11}, 300))
+
+	raw := encodeFormat(t, "TestDGI", DGI, recs)
+	if !bytes.Equal(raw[:8], []byte{0x01, 0x01, 0x05, 0x5f, 0x20, 0x02, 'J', 'D'}) {
+		FailWithError(t, "TestDGI", noMatch)
+	} else if !bytes.Equal(raw[8:13], []byte{0x80, 0x10, 0xff, 0x01, 0x2c}) {
+		FailWithError(t, "TestDGI", noMatch)
+	}
+
+	out := decodeFormat(t, "TestDGI", DGI, raw)
+	if !CompareLists(out, recs, CompareRules{}).Equal() {
+		FailWithError(t, "TestDGI", noMatch)
+	}
+}
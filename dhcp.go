@@ -0,0 +1,140 @@
+package tlv
+
+import (
+	"bytes"
+	"io"
+)
+
+// DHCP is the DHCPv4 option format (RFC 2132): a one-byte code followed by
+// a one-byte length. The PAD option is skipped when reading, and the END
+// option is read as the end of the stream. Tags must be between 1 and
+// 254, and values may be at most 255 bytes long; DecodeDHCPOptions and
+// EncodeDHCPOptions handle longer values and option overloading.
+var DHCP Format = dhcpFormat{}
+
+// The DHCP options with special meaning to the option format.
+const (
+	DHCPPad      = 0
+	DHCPOverload = 52
+	DHCPEnd      = 255
+)
+
+type dhcpFormat struct{}
+
+func (dhcpFormat) ReadHeader(r io.Reader) (tag, length int, err error) {
+	br := asByteReader(r)
+	var code byte
+	for {
+		if code, err = br.ReadByte(); err != nil {
+			return
+		} else if code != DHCPPad {
+			break
+		}
+	}
+
+	if code == DHCPEnd {
+		return 0, 0, io.EOF
+	}
+
+	n, err := br.ReadByte()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return int(code), int(n), err
+}
+
+func (dhcpFormat) WriteHeader(w io.Writer, tag, length int) error {
+	if tag <= DHCPPad || tag >= DHCPEnd {
+		return ErrTagRange
+	} else if length < 0 || length > 0xff {
+		return ErrLengthRange
+	}
+	_, err := w.Write([]byte{byte(tag), byte(length)})
+	return err
+}
+
+// DecodeDHCPOptions decodes the options of a DHCPv4 packet. If the
+// options contain an Option Overload, the options carried in the file
+// and sname fields are decoded as well. Following RFC 3396, all
+// instances of an option are concatenated into a single record, placed
+// where the option first appeared. The Option Overload itself is not
+// included in the result.
+func DecodeDHCPOptions(options, file, sname []byte) (*TLVList, error) {
+	fields := [][]byte{options}
+	if overload, ok := findDHCPOverload(options); ok {
+		if overload&1 != 0 {
+			fields = append(fields, file)
+		}
+		if overload&2 != 0 {
+			fields = append(fields, sname)
+		}
+	}
+
+	var tags []int
+	values := map[int][]byte{}
+	for _, field := range fields {
+		dec := NewDecoder(bytes.NewReader(field))
+		dec.Format = DHCP
+		for {
+			rec, err := dec.Decode()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, err
+			} else if rec.Tag() == DHCPOverload {
+				continue
+			}
+
+			if _, ok := values[rec.Tag()]; !ok {
+				tags = append(tags, rec.Tag())
+			}
+			values[rec.Tag()] = append(values[rec.Tag()], rec.Value()...)
+		}
+	}
+
+	recs := New()
+	for _, tag := range tags {
+		recs.Add(tag, values[tag])
+	}
+	return recs, nil
+}
+
+func findDHCPOverload(options []byte) (overload byte, ok bool) {
+	dec := NewDecoder(bytes.NewReader(options))
+	dec.Format = DHCP
+	for {
+		rec, err := dec.Decode()
+		if err != nil {
+			return 0, false
+		} else if rec.Tag() == DHCPOverload && rec.Length() == 1 {
+			return rec.Value()[0], true
+		}
+	}
+}
+
+// EncodeDHCPOptions encodes recs as the options field of a DHCPv4
+// packet, terminated by the END option. Following RFC 3396, values longer
+// than 255 bytes are split across several instances of the option.
+func EncodeDHCPOptions(recs *TLVList) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+	enc.Format = DHCP
+	for _, rec := range recs.records {
+		value := rec.Value()
+		for {
+			n := len(value)
+			if n > 0xff {
+				n = 0xff
+			}
+			if err := enc.Encode(newTLV(rec.Tag(), value[:n])); err != nil {
+				return nil, err
+			}
+			value = value[n:]
+			if len(value) == 0 {
+				break
+			}
+		}
+	}
+	buf.WriteByte(DHCPEnd)
+	return buf.Bytes(), nil
+}
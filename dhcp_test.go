@@ -0,0 +1,71 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// The options of a DHCPDISCOVER: message type, a PAD, a parameter
+// request list split across two instances, and an Option Overload
+// moving a host name into the file field.
+var dhcpOptions = []byte{
+	0x35, 0x01, 0x01,
+	0x00,
+	0x37, 0x02, 0x01, 0x03,
+	0x34, 0x01, 0x01,
+	0x37, 0x01, 0x06,
+	0xff, 0x00, 0x00,
+}
+
+var dhcpFile = []byte{0x0c, 0x06, 'g', 'o', 'p', 'h', 'e', 'r', 0xff}
+
+func TestDHCP(t *testing.T) {
+	opts := decodeFormat(t, "TestDHCP", DHCP, dhcpOptions)
+	checkTags(t, "TestDHCP", opts, 0x35, 0x37, DHCPOverload, 0x37)
+
+	opts, err := DecodeDHCPOptions(dhcpOptions, dhcpFile, nil)
+	if err != nil {
+		FailWithError(t, "TestDHCP", err)
+	}
+	checkTags(t, "TestDHCP", opts, 0x35, 0x37, 0x0c)
+	if prl, err := opts.Get(0x37); err != nil {
+		FailWithError(t, "TestDHCP", err)
+	} else if !bytes.Equal(prl.Value(), []byte{1, 3, 6}) {
+		FailWithError(t, "TestDHCP", noMatch)
+	}
+
+	opts, err = DecodeDHCPOptions(dhcpOptions, nil, dhcpFile)
+	if err != nil {
+		FailWithError(t, "TestDHCP", err)
+	}
+	checkTags(t, "TestDHCP", opts, 0x35, 0x37)
+
+	buf := new(bytes.Buffer)
+	if err = DHCP.WriteHeader(buf, DHCPEnd, 0); err != ErrTagRange {
+		FailWithError(t, "TestDHCP",
+			fmt.Errorf("END should not be written as an option"))
+	}
+}
+
+func TestDHCPLongOptions(t *testing.T) {
+	opts := New()
+	opts.Add(0x35, []byte{0x01})
+	opts.Add(0x2b, bytes.Repeat([]byte{0x42}, 300))
+	raw, err := EncodeDHCPOptions(opts)
+	if err != nil {
+		FailWithError(t, "TestDHCPLongOptions", err)
+	} else if len(raw) != 3+2+255+2+45+1 {
+		FailWithError(t, "TestDHCPLongOptions",
+			fmt.Errorf("bad encoded length %d", len(raw)))
+	}
+
+	ropts, err := DecodeDHCPOptions(raw, nil, nil)
+	if err != nil {
+		FailWithError(t, "TestDHCPLongOptions", err)
+	} else if ropts.Length() != 2 {
+		FailWithError(t, "TestDHCPLongOptions", noMatch)
+	} else if rec, _ := ropts.Get(0x2b); !Equals(rec, newTLV(0x2b, bytes.Repeat([]byte{0x42}, 300))) {
+		FailWithError(t, "TestDHCPLongOptions", noMatch)
+	}
+}
@@ -0,0 +1,140 @@
+package tlv
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// AVP header flags. AVPFlagVendor indicates that the AVP carries a
+// Vendor-ID; AVPFlagMandatory and AVPFlagProtected are the M and P bits.
+const (
+	AVPFlagVendor    = 0x80
+	AVPFlagMandatory = 0x40
+	AVPFlagProtected = 0x20
+)
+
+// maxAVPData is the most data that fits in an AVP, whose 24-bit length
+// includes its header.
+const maxAVPData = 1<<24 - 1 - 12
+
+// Type AVP is a Diameter attribute-value pair. It implements TLV, using
+// the AVP code as the tag and the AVP data as the value, so AVPs can be
+// held in a TLVList alongside other records.
+type AVP struct {
+	Code     uint32
+	Flags    byte
+	VendorID uint32
+	Data     []byte
+}
+
+// Method Tag returns the AVP code.
+func (avp *AVP) Tag() int {
+	return int(avp.Code)
+}
+
+// Method Length returns the length of the AVP data.
+func (avp *AVP) Length() int {
+	return len(avp.Data)
+}
+
+// Method Value returns the AVP data.
+func (avp *AVP) Value() []byte {
+	return avp.Data
+}
+
+// ReadAVPs reads Diameter AVPs until the end of r, such as the body of a
+// Diameter message or the data of a Grouped AVP. Each AVP is returned as
+// an *AVP; padding is discarded.
+func ReadAVPs(r io.Reader) (recs *TLVList, err error) {
+	recs = New()
+	for {
+		var avp *AVP
+		if avp, err = readAVP(r); err != nil {
+			break
+		}
+		recs.records = append(recs.records, avp)
+	}
+	return recs, eof(err)
+}
+
+func readAVP(r io.Reader) (avp *AVP, err error) {
+	var hdr [12]byte
+	if _, err = io.ReadFull(r, hdr[:8]); err == io.ErrUnexpectedEOF {
+		return nil, ErrTLVRead
+	} else if err != nil {
+		return
+	}
+
+	avp = new(AVP)
+	avp.Code = binary.BigEndian.Uint32(hdr[:])
+	avp.Flags = hdr[4]
+	length := int(hdr[5])<<16 | int(hdr[6])<<8 | int(hdr[7])
+	hlen := 8
+	if avp.Flags&AVPFlagVendor != 0 {
+		if _, err = io.ReadFull(r, hdr[8:]); err != nil {
+			return nil, ErrTLVRead
+		}
+		avp.VendorID = binary.BigEndian.Uint32(hdr[8:])
+		hlen = 12
+	}
+	if length < hlen {
+		return nil, ErrLengthRange
+	}
+
+	avp.Data = make([]byte, length-hlen+pad4(length))
+	if _, err = io.ReadFull(r, avp.Data); err != nil {
+		return nil, ErrTLVRead
+	}
+	avp.Data = avp.Data[:length-hlen]
+	return avp, nil
+}
+
+// pad4 returns the padding needed to bring n up to a multiple of four.
+func pad4(n int) int {
+	return (4 - n%4) % 4
+}
+
+// WriteAVPs writes the records in recs as Diameter AVPs, padding each to
+// a multiple of four bytes. Records that are not *AVP values are written
+// with no flags set.
+func WriteAVPs(w io.Writer, recs *TLVList) (err error) {
+	for _, tlv := range recs.records {
+		avp, ok := tlv.(*AVP)
+		if !ok {
+			avp = &AVP{Code: uint32(tlv.Tag()), Data: tlv.Value()}
+			if tlv.Tag() < 0 || int(avp.Code) != tlv.Tag() {
+				return ErrTagRange
+			}
+		}
+		if err = writeAVP(w, avp); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func writeAVP(w io.Writer, avp *AVP) error {
+	if len(avp.Data) > maxAVPData {
+		return ErrLengthRange
+	}
+
+	var hdr [12]byte
+	hlen := 8
+	if avp.Flags&AVPFlagVendor != 0 {
+		binary.BigEndian.PutUint32(hdr[8:], avp.VendorID)
+		hlen = 12
+	}
+	length := hlen + len(avp.Data)
+	binary.BigEndian.PutUint32(hdr[:], avp.Code)
+	binary.BigEndian.PutUint32(hdr[4:], uint32(length))
+	hdr[4] = avp.Flags
+
+	if _, err := w.Write(hdr[:hlen]); err != nil {
+		return err
+	} else if _, err = w.Write(avp.Data); err != nil {
+		return err
+	}
+	var padding [3]byte
+	_, err := w.Write(padding[:pad4(length)])
+	return err
+}
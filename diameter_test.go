@@ -0,0 +1,71 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+var diameterAVPs = []byte{
+	// Origin-Host (264), mandatory, "host.example"
+	0x00, 0x00, 0x01, 0x08, 0x40, 0x00, 0x00, 0x14,
+	'h', 'o', 's', 't', '.', 'e', 'x', 'a', 'm', 'p', 'l', 'e',
+	// Vendor-specific AVP 1, vendor 10415, one byte of data
+	0x00, 0x00, 0x00, 0x01, 0xc0, 0x00, 0x00, 0x0d,
+	0x00, 0x00, 0x28, 0xaf, 0x07, 0x00, 0x00, 0x00,
+	// Result-Code (268), 2001
+	0x00, 0x00, 0x01, 0x0c, 0x40, 0x00, 0x00, 0x0c,
+	0x00, 0x00, 0x07, 0xd1,
+}
+
+func TestDiameter(t *testing.T) {
+	recs, err := ReadAVPs(bytes.NewReader(diameterAVPs))
+	if err != nil {
+		FailWithError(t, "TestDiameter", err)
+	} else if recs.Length() != 3 {
+		FailWithError(t, "TestDiameter",
+			fmt.Errorf("expected 3 AVPs, got %d", recs.Length()))
+	}
+
+	host, err := recs.Get(264)
+	if err != nil {
+		FailWithError(t, "TestDiameter", err)
+	} else if string(host.Value()) != "host.example" {
+		FailWithError(t, "TestDiameter", noMatch)
+	}
+
+	rec, err := recs.Get(1)
+	if err != nil {
+		FailWithError(t, "TestDiameter", err)
+	}
+	vsa := rec.(*AVP)
+	if vsa.VendorID != 10415 || !bytes.Equal(vsa.Data, []byte{7}) {
+		FailWithError(t, "TestDiameter", noMatch)
+	}
+
+	buf := new(bytes.Buffer)
+	if err = WriteAVPs(buf, recs); err != nil {
+		FailWithError(t, "TestDiameter", err)
+	} else if !bytes.Equal(buf.Bytes(), diameterAVPs) {
+		FailWithError(t, "TestDiameter",
+			fmt.Errorf("AVPs did not round-trip"))
+	}
+
+	plain := New()
+	plain.Add(268, []byte{0, 0, 7, 0xd1})
+	buf.Reset()
+	if err = WriteAVPs(buf, plain); err != nil {
+		FailWithError(t, "TestDiameter", err)
+	}
+	expected := append([]byte(nil), diameterAVPs[len(diameterAVPs)-12:]...)
+	expected[4] = 0
+	if !bytes.Equal(buf.Bytes(), expected) {
+		FailWithError(t, "TestDiameter",
+			fmt.Errorf("bad AVP encoding %x", buf.Bytes()))
+	}
+
+	if _, err = ReadAVPs(bytes.NewReader(diameterAVPs[:30])); err != ErrTLVRead {
+		FailWithError(t, "TestDiameter",
+			fmt.Errorf("truncated AVP should fail"))
+	}
+}
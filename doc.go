@@ -26,8 +26,13 @@
    revision will support tags and lengths other than an integer.
 
    The basic unit of the library is the TLVList. A new, empty TLVList can
-   be created using the New function. A TLVList may be written to an
-   io.Writer using the Write method, and may be read from a file using
-   the Read function.
+   be created using the New function, or declared as a zero value. A
+   TLVList may be written to an io.Writer using the Write method, and may
+   be read from a file using the Read function.
+
+   A TLVList preserves the order of its records. Records are kept in the
+   order they were added, Write emits them in that order, and Read and
+   the Decoder return them in the order they appear on the wire. Records
+   with duplicate tags are kept in place rather than being merged.
 */
 package tlv
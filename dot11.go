@@ -0,0 +1,69 @@
+package tlv
+
+import "io"
+
+// Dot11 is the IEEE 802.11 information element format used in management
+// frames such as beacons and probe responses: a one-byte element ID
+// followed by a one-byte length. Elements with the Element ID Extension
+// ID carry a further one-byte extension ID at the start of their value;
+// these are read with tags from Dot11Extension, with the extension ID
+// removed from the value, and are written the same way. Plain element
+// values may be at most 255 bytes long, and extended element values 254.
+var Dot11 Format = dot11Format{}
+
+// Dot11ElementExtension is the Element ID Extension element ID.
+const Dot11ElementExtension = 255
+
+// Dot11Extension returns the tag used for the extended element with the
+// given extension ID.
+func Dot11Extension(ext byte) int {
+	return Dot11ElementExtension<<8 | int(ext)
+}
+
+// IsDot11Extension reports whether tag is an extended element's tag, and
+// if it is, its extension ID.
+func IsDot11Extension(tag int) (ext byte, ok bool) {
+	if tag>>8 != Dot11ElementExtension {
+		return 0, false
+	}
+	return byte(tag), true
+}
+
+type dot11Format struct{}
+
+func (dot11Format) ReadHeader(r io.Reader) (tag, length int, err error) {
+	var hdr [3]byte
+	if _, err = io.ReadFull(r, hdr[:2]); err != nil {
+		return
+	}
+
+	tag, length = int(hdr[0]), int(hdr[1])
+	if tag != Dot11ElementExtension {
+		return
+	} else if length == 0 {
+		return 0, 0, ErrLengthRange
+	}
+
+	if _, err = io.ReadFull(r, hdr[2:]); err != nil {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	return Dot11Extension(hdr[2]), length - 1, nil
+}
+
+func (dot11Format) WriteHeader(w io.Writer, tag, length int) error {
+	if ext, ok := IsDot11Extension(tag); ok {
+		if length < 0 || length > 0xff-1 {
+			return ErrLengthRange
+		}
+		_, err := w.Write([]byte{Dot11ElementExtension, byte(length + 1), ext})
+		return err
+	}
+
+	if tag < 0 || tag >= Dot11ElementExtension {
+		return ErrTagRange
+	} else if length < 0 || length > 0xff {
+		return ErrLengthRange
+	}
+	_, err := w.Write([]byte{byte(tag), byte(length)})
+	return err
+}
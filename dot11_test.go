@@ -0,0 +1,45 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// The information elements of a beacon: SSID "gopher", Supported Rates,
+// and an HE Operation element (Element ID Extension 36).
+var dot11Elements = []byte{
+	0x00, 0x06, 'g', 'o', 'p', 'h', 'e', 'r',
+	0x01, 0x04, 0x82, 0x84, 0x8b, 0x96,
+	0xff, 0x07, 0x24, 0x04, 0x00, 0x00, 0x3f, 0xfc, 0xff,
+}
+
+func TestDot11(t *testing.T) {
+	heOp := Dot11Extension(36)
+	ies := decodeFormat(t, "TestDot11", Dot11, dot11Elements)
+	checkTags(t, "TestDot11", ies, 0, 1, heOp)
+
+	if rec, err := ies.Get(heOp); err != nil {
+		FailWithError(t, "TestDot11", err)
+	} else if !bytes.Equal(rec.Value(), dot11Elements[17:]) {
+		FailWithError(t, "TestDot11", noMatch)
+	}
+
+	if ext, ok := IsDot11Extension(heOp); !ok || ext != 36 {
+		FailWithError(t, "TestDot11", noMatch)
+	} else if _, ok = IsDot11Extension(1); ok {
+		FailWithError(t, "TestDot11",
+			fmt.Errorf("plain element reported as extended"))
+	}
+
+	if !bytes.Equal(encodeFormat(t, "TestDot11", Dot11, ies), dot11Elements) {
+		FailWithError(t, "TestDot11",
+			fmt.Errorf("elements did not round-trip"))
+	}
+
+	buf := new(bytes.Buffer)
+	if err := Dot11.WriteHeader(buf, heOp, 255); err != ErrLengthRange {
+		FailWithError(t, "TestDot11",
+			fmt.Errorf("extended element length should be limited"))
+	}
+}
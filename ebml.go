@@ -0,0 +1,226 @@
+package tlv
+
+import (
+	"bytes"
+	"io"
+)
+
+// EBML is the element format of EBML (RFC 8794), used by Matroska and
+// WebM: an element ID and a data size, each a variable-size integer
+// whose length is given by the number of leading zero bits in its first
+// byte. IDs keep their length marker bits, so tags are written the way
+// the specifications write them: the EBML header is 0x1A45DFA3. IDs of
+// up to four bytes and sizes of up to eight bytes are supported, and
+// sizes are written in their shortest form.
+//
+// Master elements of unknown size, as written by live encoders, cannot
+// be read with the EBML format, which rejects them with ErrLengthRange;
+// use DecodeEBML for those.
+var EBML Format = ebmlFormat{}
+
+// Element IDs of the EBML header and the top-level Matroska elements
+// that may have unknown sizes.
+const (
+	EBMLHeader      = 0x1a45dfa3
+	MatroskaSegment = 0x18538067
+	MatroskaCluster = 0x1f43b675
+)
+
+// ebmlUnknown marks a data size of unknown length.
+const ebmlUnknown = -1
+
+type ebmlFormat struct{}
+
+// readVint reads a variable-size integer, returning its value with the
+// length marker removed, the raw value with the marker kept, and its
+// length in bytes. maxLen limits the length accepted.
+func readVint(br io.ByteReader, maxLen int) (value, raw uint64, n int, err error) {
+	b, err := br.ReadByte()
+	if err != nil {
+		return
+	}
+	for n = 1; n <= 8 && b&(0x80>>uint(n-1)) == 0; n++ {
+	}
+	if n > maxLen {
+		return 0, 0, 0, ErrLengthRange
+	}
+
+	raw = uint64(b)
+	value = uint64(b) & (0xff >> uint(n))
+	for i := 1; i < n; i++ {
+		if b, err = br.ReadByte(); err != nil {
+			return 0, 0, 0, io.ErrUnexpectedEOF
+		}
+		raw = raw<<8 | uint64(b)
+		value = value<<8 | uint64(b)
+	}
+	return
+}
+
+func readEBMLHeader(br io.ByteReader) (tag, length int, err error) {
+	_, id, _, err := readVint(br, 4)
+	if err == ErrLengthRange {
+		return 0, 0, ErrTagRange
+	} else if err != nil {
+		return
+	}
+
+	size, _, n, err := readVint(br, 8)
+	if err == io.EOF {
+		return 0, 0, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return
+	}
+	if size == 1<<uint(7*n)-1 {
+		return int(id), ebmlUnknown, nil
+	} else if size > maxChunk {
+		return 0, 0, ErrLengthRange
+	}
+	return int(id), int(size), nil
+}
+
+func (ebmlFormat) ReadHeader(r io.Reader) (tag, length int, err error) {
+	tag, length, err = readEBMLHeader(asByteReader(r))
+	if err == nil && length == ebmlUnknown {
+		return 0, 0, ErrLengthRange
+	}
+	return
+}
+
+func (ebmlFormat) WriteHeader(w io.Writer, tag, length int) error {
+	if tag <= 0 || int64(tag) > 0xffffffff {
+		return ErrTagRange
+	}
+	n := 1
+	for t := tag >> 8; t != 0; t >>= 8 {
+		n++
+	}
+	if first := byte(tag >> uint(8*(n-1))); first>>uint(8-n) != 1 {
+		return ErrTagRange
+	}
+	if length < 0 || length > maxChunk {
+		return ErrLengthRange
+	}
+
+	// The all-ones value of each length means unknown, so a size
+	// needs a byte more than its bits alone would suggest.
+	m := 1
+	for uint64(length) >= 1<<uint(7*m)-1 {
+		m++
+	}
+
+	var hdr [12]byte
+	for i := 0; i < n; i++ {
+		hdr[i] = byte(tag >> uint(8*(n-1-i)))
+	}
+	for i := 0; i < m; i++ {
+		hdr[n+i] = byte(length >> uint(8*(m-1-i)))
+	}
+	hdr[n] |= 0x80 >> uint(m-1)
+	_, err := w.Write(hdr[:n+m])
+	return err
+}
+
+// DecodeEBML decodes the EBML elements in data. Master elements, those
+// for which master returns true, are decoded recursively and returned as
+// Constructed records holding their children; as with any Constructed
+// record, an Encoder using the EBML format writes the children back in
+// EBML. Other elements, and all elements if master is nil, are returned
+// with their data as their value. Elements of unknown size are supported
+// by scanning for their end: such an element extends to the end of its
+// parent, or to the first following element, at any depth of scanning,
+// whose ID is the same as its own or for which ends returns true. If
+// ends is nil, only repeated IDs end an element, which suits runs of
+// Clusters. Elements read with unknown size are returned with their size
+// made known. Masters, and elements of unknown size, nested too deeply
+// are rejected with ErrTooLarge.
+func DecodeEBML(data []byte, master, ends func(id int) bool) (*TLVList, error) {
+	return decodeEBML(data, master, ends, 0)
+}
+
+// decodeEBML decodes the elements in data, which are nested depth deep.
+func decodeEBML(data []byte, master, ends func(id int) bool, depth int) (*TLVList, error) {
+	if depth >= maxNestDepth {
+		return nil, ErrTooLarge
+	}
+
+	recs := New()
+	for pos := 0; pos < len(data); {
+		r := bytes.NewReader(data[pos:])
+		tag, length, err := readEBMLHeader(r)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, ErrTLVRead
+		} else if err != nil {
+			return nil, err
+		}
+
+		start := len(data) - r.Len()
+		if length == ebmlUnknown {
+			if length, err = scanEBML(data[start:], tag, nil, ends, depth); err != nil {
+				return nil, err
+			}
+		} else if length > len(data)-start {
+			return nil, ErrTLVRead
+		}
+		value := data[start : start+length]
+		pos = start + length
+
+		if master == nil || !master(tag) {
+			recs.records = append(recs.records, newTLV(tag, value))
+			continue
+		}
+		children, err := decodeEBML(value, master, ends, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		recs.records = append(recs.records, NewConstructed(tag, children))
+	}
+	return recs, nil
+}
+
+// scanEBML finds the length of the data of an element of unknown size
+// with the given ID, which starts at the beginning of data and is nested
+// depth deep. The element ends at the first following element whose ID
+// is in ancestors, the IDs of the elements of unknown size it lies
+// within, or is its own, or for which ends returns true.
+func scanEBML(data []byte, id int, ancestors map[int]bool, ends func(id int) bool, depth int) (int, error) {
+	if depth >= maxNestDepth {
+		return 0, ErrTooLarge
+	}
+
+	// Whatever ends this element also ends any element of unknown
+	// size inside it.
+	if ancestors == nil {
+		ancestors = make(map[int]bool)
+	}
+	if !ancestors[id] {
+		ancestors[id] = true
+		defer delete(ancestors, id)
+	}
+
+	pos := 0
+	for pos < len(data) {
+		r := bytes.NewReader(data[pos:])
+		tag, length, err := readEBMLHeader(r)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return 0, ErrTLVRead
+		} else if err != nil {
+			return 0, err
+		}
+		if ancestors[tag] || (ends != nil && ends(tag)) {
+			return pos, nil
+		}
+
+		start := pos + len(data[pos:]) - r.Len()
+		if length == ebmlUnknown {
+			length, err = scanEBML(data[start:], tag, ancestors, ends, depth+1)
+			if err != nil {
+				return 0, err
+			}
+		} else if length > len(data)-start {
+			return 0, ErrTLVRead
+		}
+		pos = start + length
+	}
+	return pos, nil
+}
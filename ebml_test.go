@@ -0,0 +1,153 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestEBML(t *testing.T) {
+	// An EBML header holding DocType "webm" (0x4282), and a Segment
+	// of 200 bytes, whose size needs two bytes.
+	recs := New()
+	recs.Add(EBMLHeader, []byte{0x42, 0x82, 0x84, 'w', 'e', 'b', 'm'})
+	recs.Add(MatroskaSegment, make([]byte, 200))
+
+	raw := encodeFormat(t, "TestEBML", EBML, recs)
+	want := []byte{0x1a, 0x45, 0xdf, 0xa3, 0x87, 0x42, 0x82, 0x84, 'w', 'e', 'b', 'm',
+		0x18, 0x53, 0x80, 0x67, 0x40, 0xc8}
+	if !bytes.Equal(raw[:len(want)], want) {
+		FailWithError(t, "TestEBML",
+			fmt.Errorf("encoded % x", raw[:len(want)]))
+	}
+
+	out := decodeFormat(t, "TestEBML", EBML, raw)
+	if !CompareLists(out, recs, CompareRules{}).Equal() {
+		FailWithError(t, "TestEBML", noMatch)
+	}
+	header, _ := out.Get(EBMLHeader)
+	if doc, err := DecodeEBML(header.Value(), nil, nil); err != nil {
+		FailWithError(t, "TestEBML", err)
+	} else if dt, err := doc.Get(0x4282); err != nil || string(dt.Value()) != "webm" {
+		FailWithError(t, "TestEBML", noMatch)
+	}
+
+	// 0x7f is the all-ones one-byte size, which is reserved.
+	enc := NewEncoder(new(bytes.Buffer))
+	enc.Format = EBML
+	if err := enc.Encode(newTLV(0xec, make([]byte, 0x7f))); err != nil {
+		FailWithError(t, "TestEBML", err)
+	} else if enc.Offset() != 1+2+0x7f {
+		FailWithError(t, "TestEBML", noMatch)
+	}
+	if err := enc.Encode(newTLV(0x8182, nil)); err != ErrTagRange {
+		FailWithError(t, "TestEBML",
+			fmt.Errorf("expected ErrTagRange, got %v", err))
+	}
+}
+
+func TestEBMLUnknownSize(t *testing.T) {
+	// A live stream: a Segment and two Clusters of unknown size, each
+	// Cluster holding a Timestamp (0xE7) and a SimpleBlock (0xA3).
+	cluster := []byte{0x1f, 0x43, 0xb6, 0x75, 0x01, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	var stream []byte
+	stream = append(stream, 0x18, 0x53, 0x80, 0x67, 0xff)
+	stream = append(stream, cluster...)
+	stream = append(stream, 0xe7, 0x81, 0x00, 0xa3, 0x82, 0xaa, 0xbb)
+	stream = append(stream, cluster...)
+	stream = append(stream, 0xe7, 0x81, 0x10)
+
+	dec := NewDecoder(bytes.NewReader(stream))
+	dec.Format = EBML
+	if _, err := dec.Decode(); err != ErrLengthRange {
+		FailWithError(t, "TestEBMLUnknownSize",
+			fmt.Errorf("expected ErrLengthRange, got %v", err))
+	}
+
+	top, err := DecodeEBML(stream, nil, nil)
+	if err != nil {
+		FailWithError(t, "TestEBMLUnknownSize", err)
+	} else if top.Length() != 1 {
+		FailWithError(t, "TestEBMLUnknownSize", noMatch)
+	}
+	segment, _ := top.Get(MatroskaSegment)
+	clusters, err := DecodeEBML(segment.Value(), nil, nil)
+	if err != nil {
+		FailWithError(t, "TestEBMLUnknownSize", err)
+	} else if clusters.Length() != 2 {
+		FailWithError(t, "TestEBMLUnknownSize",
+			fmt.Errorf("found %d clusters, expected 2", clusters.Length()))
+	}
+
+	first, _ := clusters.Get(MatroskaCluster)
+	if kids, err := DecodeEBML(first.Value(), nil, nil); err != nil {
+		FailWithError(t, "TestEBMLUnknownSize", err)
+	} else if kids.Length() != 2 {
+		FailWithError(t, "TestEBMLUnknownSize", noMatch)
+	}
+}
+
+func TestEBMLMaster(t *testing.T) {
+	// The same live stream, with the Segment and Clusters decoded as
+	// master elements.
+	cluster := []byte{0x1f, 0x43, 0xb6, 0x75, 0xff}
+	var stream []byte
+	stream = append(stream, 0x18, 0x53, 0x80, 0x67, 0xff)
+	stream = append(stream, cluster...)
+	stream = append(stream, 0xe7, 0x81, 0x00, 0xa3, 0x82, 0xaa, 0xbb)
+	stream = append(stream, cluster...)
+	stream = append(stream, 0xe7, 0x81, 0x10)
+
+	master := func(id int) bool {
+		return id == MatroskaSegment || id == MatroskaCluster
+	}
+	top, err := DecodeEBML(stream, master, nil)
+	if err != nil {
+		FailWithError(t, "TestEBMLMaster", err)
+	}
+	rec, err := top.Get(MatroskaSegment)
+	if err != nil {
+		FailWithError(t, "TestEBMLMaster", err)
+	}
+	segment, ok := rec.(Constructed)
+	if !ok || segment.Children().Length() != 2 {
+		FailWithError(t, "TestEBMLMaster", noMatch)
+	}
+	for _, rec := range segment.Children().GetAll(MatroskaCluster) {
+		if _, ok := rec.(Constructed); !ok {
+			FailWithError(t, "TestEBMLMaster", noMatch)
+		}
+	}
+
+	// Written back, the elements have known sizes.
+	raw := encodeFormat(t, "TestEBMLMaster", EBML, top)
+	again, err := DecodeEBML(raw, master, nil)
+	if err != nil {
+		FailWithError(t, "TestEBMLMaster", err)
+	} else if !CompareLists(again, top, CompareRules{}).Equal() {
+		FailWithError(t, "TestEBMLMaster", noMatch)
+	}
+
+	var deep []byte
+	for i := 0; i < maxNestDepth+1; i++ {
+		deep = append([]byte{0xa0, 0x80 | byte(len(deep))}, deep...)
+	}
+	nested := func(id int) bool { return id == 0xa0 }
+	if _, err = DecodeEBML(deep, nested, nil); err != ErrTooLarge {
+		FailWithError(t, "TestEBMLMaster",
+			fmt.Errorf("expected ErrTooLarge, got %v", err))
+	}
+}
+
+func TestEBMLUnknownDepth(t *testing.T) {
+	// Elements of unknown size with distinct IDs, each nested in the
+	// last, would make the scan for their ends recurse without limit.
+	var hostile []byte
+	for i := 0; i < 16<<10; i++ {
+		hostile = append(hostile, 0x41, byte(i), 0xff)
+	}
+	if _, err := DecodeEBML(hostile, nil, nil); err != ErrTooLarge {
+		FailWithError(t, "TestEBMLUnknownDepth",
+			fmt.Errorf("expected ErrTooLarge, got %v", err))
+	}
+}
@@ -0,0 +1,132 @@
+package tlv
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+)
+
+// Type Editor edits a file of records in the Standard format in place,
+// without rewriting the records it does not touch. A value replaced by
+// one of the same length is overwritten where it lies; any other change
+// is made by appending a tombstone and, for Set, the new record, so the
+// file should be read with Compact or ReadAsOf applied. An Editor must
+// not be used from more than one goroutine at a time.
+type Editor struct {
+	file *os.File
+	size int64
+	live map[int][]Extent
+}
+
+// OpenEditor opens the file at path for editing, indexing the records it
+// holds. Records deleted by tombstones in the file are not indexed.
+func OpenEditor(path string) (*Editor, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	ed := &Editor{file: file, live: make(map[int][]Extent)}
+	dec := NewDecoder(bufio.NewReader(file))
+	for {
+		off := dec.Offset()
+		rec, err := dec.Decode()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			file.Close()
+			return nil, err
+		}
+
+		if tag, ok := IsTombstone(rec); ok {
+			delete(ed.live, tag)
+			continue
+		}
+		ext := Extent{rec.Tag(), off, dec.Offset() - off}
+		ed.live[rec.Tag()] = append(ed.live[rec.Tag()], ext)
+	}
+	ed.size = dec.Offset()
+	return ed, nil
+}
+
+// Get returns the first live record with tag.
+func (ed *Editor) Get(tag int) (TLV, error) {
+	exts := ed.live[tag]
+	if len(exts) == 0 {
+		return nil, ErrTagNotFound
+	}
+
+	value := make([]byte, exts[0].Length-headerLength)
+	if _, err := ed.file.ReadAt(value, exts[0].Offset+headerLength); err != nil {
+		return nil, ErrTLVRead
+	}
+	return newTLV(tag, value), nil
+}
+
+func (ed *Editor) append(recs ...TLV) error {
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+	for _, rec := range recs {
+		if err := enc.Encode(rec); err != nil {
+			return err
+		}
+	}
+
+	if _, err := ed.file.WriteAt(buf.Bytes(), ed.size); err != nil {
+		return ErrTLVWrite
+	}
+	ed.size += int64(buf.Len())
+	return nil
+}
+
+// Set makes value the only value of tag. If the tag has a single record
+// whose value is the same length, its value is overwritten in place;
+// otherwise, a tombstone for the tag and a new record are appended.
+func (ed *Editor) Set(tag int, value []byte) error {
+	if exts := ed.live[tag]; len(exts) == 1 && exts[0].Length-headerLength == int64(len(value)) {
+		if _, err := ed.file.WriteAt(value, exts[0].Offset+headerLength); err != nil {
+			return ErrTLVWrite
+		}
+		return nil
+	}
+
+	rec := newTLV(tag, value)
+	recs := []TLV{rec}
+	if len(ed.live[tag]) > 0 {
+		recs = []TLV{Tombstone(tag), rec}
+	}
+	if err := ed.append(recs...); err != nil {
+		return err
+	}
+	n := int64(headerLength + len(value))
+	ed.live[tag] = []Extent{{tag, ed.size - n, n}}
+	return nil
+}
+
+// Remove deletes every record with tag by appending a tombstone,
+// returning the number of records deleted.
+func (ed *Editor) Remove(tag int) (int, error) {
+	n := len(ed.live[tag])
+	if n == 0 {
+		return 0, nil
+	} else if err := ed.append(Tombstone(tag)); err != nil {
+		return 0, err
+	}
+	delete(ed.live, tag)
+	return n, nil
+}
+
+// Size returns the size of the file, including any records appended.
+func (ed *Editor) Size() int64 {
+	return ed.size
+}
+
+// Close flushes the edits to stable storage and closes the file.
+func (ed *Editor) Close() error {
+	if err := ed.file.Sync(); err != nil {
+		ed.file.Close()
+		return err
+	}
+	return ed.file.Close()
+}
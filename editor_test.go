@@ -0,0 +1,68 @@
+package tlv
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEditor(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(TagTest1, []byte("0123456789abcdef"))
+	tlvl.Add(TagTest2, []byte("short"))
+	tlvl.Add(TagTest3, []byte("gone"))
+	path := filepath.Join(t.TempDir(), "archive.tlv")
+	writeListFile(t, path, tlvl)
+
+	ed, err := OpenEditor(path)
+	if err != nil {
+		FailWithError(t, "TestEditor", err)
+	}
+	size := ed.Size()
+
+	if err = ed.Set(TagTest1, []byte("fedcba9876543210")); err != nil {
+		FailWithError(t, "TestEditor", err)
+	} else if ed.Size() != size {
+		FailWithError(t, "TestEditor",
+			fmt.Errorf("same-length edit should be made in place"))
+	}
+
+	if err = ed.Set(TagTest2, []byte("much longer")); err != nil {
+		FailWithError(t, "TestEditor", err)
+	} else if n, err := ed.Remove(TagTest3); err != nil || n != 1 {
+		FailWithError(t, "TestEditor",
+			fmt.Errorf("expected to remove 1 record, got %d (%v)", n, err))
+	} else if rec, err := ed.Get(TagTest2); err != nil || string(rec.Value()) != "much longer" {
+		FailWithError(t, "TestEditor", noMatch)
+	} else if err = ed.Close(); err != nil {
+		FailWithError(t, "TestEditor", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		FailWithError(t, "TestEditor", err)
+	}
+	defer file.Close()
+	stored, err := NewDecoder(file).DecodeList()
+	if err != nil {
+		FailWithError(t, "TestEditor", err)
+	}
+	live := stored.Compact()
+	checkTags(t, "TestEditor", live, TagTest1, TagTest2)
+	if rec, _ := live.Get(TagTest1); string(rec.Value()) != "fedcba9876543210" {
+		FailWithError(t, "TestEditor", noMatch)
+	}
+
+	// Reopening the file must take the appended tombstones into account.
+	if ed, err = OpenEditor(path); err != nil {
+		FailWithError(t, "TestEditor", err)
+	}
+	defer ed.Close()
+	if _, err = ed.Get(TagTest3); err != ErrTagNotFound {
+		FailWithError(t, "TestEditor",
+			fmt.Errorf("removed tag should not be found"))
+	} else if rec, err := ed.Get(TagTest2); err != nil || string(rec.Value()) != "much longer" {
+		FailWithError(t, "TestEditor", noMatch)
+	}
+}
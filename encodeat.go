@@ -0,0 +1,65 @@
+package tlv
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+func putRecord(buf []byte, tlv TLV) int {
+	binary.BigEndian.PutUint32(buf, uint32(tlv.Tag()))
+	binary.BigEndian.PutUint32(buf[4:], uint32(tlv.Length()))
+	return headerLength + copy(buf[headerLength:], tlv.Value())
+}
+
+func encodedLength(tlv TLV) (n int, err error) {
+	if int(int32(tlv.Tag())) != tlv.Tag() {
+		return 0, ErrTagRange
+	} else if int(int32(tlv.Length())) != tlv.Length() || tlv.Length() != len(tlv.Value()) {
+		return 0, ErrLengthRange
+	}
+	return headerLength + tlv.Length(), nil
+}
+
+func checkBounds(buf []byte, off, n int) error {
+	if off < 0 || off > len(buf) || n > len(buf)-off {
+		return io.ErrShortBuffer
+	}
+	return nil
+}
+
+// EncodeAt writes tlv in the Standard format into buf at offset off,
+// returning the number of bytes written. If the record does not fit
+// within buf, io.ErrShortBuffer is returned and buf is left untouched.
+// This allows records to be written directly into shared memory or a
+// memory-mapped file.
+func EncodeAt(tlv TLV, buf []byte, off int) (n int, err error) {
+	if n, err = encodedLength(tlv); err != nil {
+		return 0, err
+	} else if err = checkBounds(buf, off, n); err != nil {
+		return 0, err
+	}
+	return putRecord(buf[off:], tlv), nil
+}
+
+// EncodeAt writes the records in the list in the Standard format into
+// buf at offset off, returning the number of bytes written. If the list
+// does not fit within buf, io.ErrShortBuffer is returned and buf is left
+// untouched.
+func (recs *TLVList) EncodeAt(buf []byte, off int) (n int, err error) {
+	for _, tlv := range recs.records {
+		size, err := encodedLength(tlv)
+		if err != nil {
+			return 0, err
+		}
+		n += size
+	}
+	if err = checkBounds(buf, off, n); err != nil {
+		return 0, err
+	}
+
+	n = 0
+	for _, tlv := range recs.records {
+		n += putRecord(buf[off+n:], tlv)
+	}
+	return n, nil
+}
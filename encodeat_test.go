@@ -0,0 +1,47 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestEncodeAt(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(TagTest1, []byte("hello"))
+	tlvl.Add(TagTest2, []byte("world"))
+
+	buf := new(bytes.Buffer)
+	if err := tlvl.Write(buf); err != nil {
+		FailWithError(t, "TestEncodeAt", err)
+	}
+
+	region := make([]byte, 4+buf.Len())
+	n, err := tlvl.EncodeAt(region, 4)
+	if err != nil {
+		FailWithError(t, "TestEncodeAt", err)
+	} else if n != buf.Len() || !bytes.Equal(region[4:], buf.Bytes()) {
+		FailWithError(t, "TestEncodeAt", noMatch)
+	}
+
+	rec, _ := tlvl.Get(TagTest2)
+	n, err = EncodeAt(rec, region, 0)
+	if err != nil {
+		FailWithError(t, "TestEncodeAt", err)
+	} else if n != 13 || !bytes.Equal(region[:n], buf.Bytes()[13:]) {
+		FailWithError(t, "TestEncodeAt", noMatch)
+	}
+
+	region = make([]byte, buf.Len())
+	if _, err = tlvl.EncodeAt(region, 1); err != io.ErrShortBuffer {
+		FailWithError(t, "TestEncodeAt",
+			fmt.Errorf("list should not fit"))
+	} else if !bytes.Equal(region, make([]byte, len(region))) {
+		FailWithError(t, "TestEncodeAt",
+			fmt.Errorf("buffer modified after failed write"))
+	} else if _, err = EncodeAt(rec, region, -1); err != io.ErrShortBuffer {
+		FailWithError(t, "TestEncodeAt",
+			fmt.Errorf("negative offset should fail"))
+	}
+}
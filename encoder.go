@@ -0,0 +1,174 @@
+package tlv
+
+import (
+	"hash"
+	"io"
+)
+
+// Type Encoder writes TLV records to an output stream.
+type Encoder struct {
+	// Format is the wire format records are written in. If nil,
+	// Standard is used.
+	Format Format
+
+	// Index, if set, causes Close to append a footer index of the
+	// records written, allowing the stream to be read in reverse
+	// with a ReverseDecoder. It must be set before any records have
+	// been written.
+	Index bool
+
+	// Compression, if set, is the algorithm used to compress record
+	// values. Records that would not be made smaller are written
+	// uncompressed.
+	Compression Compression
+
+	// Align, if greater than one, pads each record with zeros so
+	// the next begins at a multiple of Align bytes from the start of
+	// the stream. Padding is not counted in the record's length.
+	// Alignments above 4096 bytes are not supported, and records
+	// are not written with them; ErrAlign is returned instead.
+	Align int
+
+	// Summary, if set, is the hash algorithm used for a summary
+	// record written by Close, holding the number of records and
+	// bytes written, the time taken, and a digest of the records, so
+	// that readers can check the stream with CheckSummary.
+	Summary HashID
+
+	w *countingWriter
+
+	digest  hash.Hash
+	sign    func(digest []byte) ([]byte, error)
+	offsets []int64
+	closer  io.Closer
+
+	summary    *summaryState
+	summarized bool
+}
+
+// NewEncoder returns a new Encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: &countingWriter{w: w}}
+}
+
+func (enc *Encoder) format() Format {
+	if enc.Format == nil {
+		return Standard
+	}
+	return enc.Format
+}
+
+// Encode writes a single record to the stream.
+func (enc *Encoder) Encode(tlv TLV) (err error) {
+	if err = enc.summarize(); err != nil {
+		return
+	}
+	if _, ok := tlv.(encodedConstructed); ok {
+		// Written as is.
+	} else if c, ok := tlv.(Constructed); ok {
+		if tlv, err = enc.flatten(c); err != nil {
+			return
+		}
+	}
+	if enc.Compression != CompressNone {
+		if tlv, err = enc.compress(tlv); err != nil {
+			return
+		}
+	}
+
+	if enc.Index {
+		enc.offsets = append(enc.offsets, enc.w.n)
+	}
+	return enc.encode(tlv)
+}
+
+func (enc *Encoder) encode(tlv TLV) (err error) {
+	if err = checkAlign(enc.Align); err != nil {
+		return
+	}
+
+	var w io.Writer = enc.w
+	if enc.digest != nil {
+		w = io.MultiWriter(w, enc.digest)
+	}
+	if enc.summary != nil {
+		w = io.MultiWriter(w, enc.summary.digest)
+		enc.summary.records++
+	}
+
+	err = enc.format().WriteHeader(w, tlv.Tag(), tlv.Length())
+	if err != nil {
+		return
+	}
+
+	n, err := w.Write(tlv.Value())
+	if err != nil {
+		return
+	} else if n != tlv.Length() {
+		return ErrTLVWrite
+	}
+
+	if pad := padding(enc.w.n, enc.Align); pad > 0 {
+		_, err = enc.w.Write(zeroPad[:pad])
+	}
+	return
+}
+
+// EncodeList writes every record in the TLVList to the stream, in order.
+func (enc *Encoder) EncodeList(recs *TLVList) (err error) {
+	for _, tlv := range recs.records {
+		err = enc.Encode(tlv)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Offset returns the number of bytes the Encoder has written; between
+// calls to Encode, this is the offset at which the next record will be
+// written.
+func (enc *Encoder) Offset() int64 {
+	return enc.w.n
+}
+
+// Close finishes the stream. The summary record is written if one was
+// requested, then the signature record if the Encoder is signing its
+// output, followed by the footer index if one was requested. If the
+// Encoder was created by NewPipe, the pipe is closed.
+func (enc *Encoder) Close() (err error) {
+	err = enc.writeSummary()
+	if err == nil && enc.sign != nil {
+		var sig []byte
+		sig, err = enc.sign(enc.digest.Sum(nil))
+		if err != nil {
+			return
+		}
+		enc.digest = nil
+		enc.sign = nil
+		err = enc.encode(newTLV(TagSignature, sig))
+	}
+
+	if err == nil && enc.Index {
+		enc.Index = false
+		err = enc.encode(indexRecord(enc.offsets, enc.w.n))
+	}
+
+	if enc.closer != nil {
+		if cerr := enc.closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (n int, err error) {
+	n, err = cw.w.Write(p)
+	cw.n += int64(n)
+	return
+}
@@ -0,0 +1,96 @@
+package tlv
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ErrNotEnum is returned when an enumeration operation is used with a
+// tag that has not been declared as an enumeration. ErrUnknownValue is
+// returned when a value or name is not part of a tag's enumeration.
+var (
+	ErrNotEnum      = fmt.Errorf("tag is not an enumeration")
+	ErrUnknownValue = fmt.Errorf("value not in enumeration")
+)
+
+// SetEnum declares tag as an enumeration, in which each record holds a
+// single byte naming one of a fixed set of values. names maps each value
+// to its symbolic name; names must be unique. The tag's Renderer is set
+// so that values are displayed by name.
+func (reg *Registry) SetEnum(tag int, names map[byte]string) error {
+	seen := make(map[string]bool, len(names))
+	enum := make(map[byte]string, len(names))
+	for value, name := range names {
+		if seen[name] {
+			return ErrDuplicateName
+		}
+		seen[name] = true
+		enum[value] = name
+	}
+
+	if reg.enums == nil {
+		reg.enums = make(map[int]map[byte]string)
+	}
+	reg.enums[tag] = enum
+	reg.SetRenderer(tag, func(value []byte) string {
+		if len(value) == 1 {
+			if name, ok := enum[value[0]]; ok {
+				return name
+			}
+			return strconv.Itoa(int(value[0]))
+		}
+		return RenderHex(value)
+	})
+	return nil
+}
+
+// EnumName returns the symbolic name of value in tag's enumeration.
+func (reg *Registry) EnumName(tag int, value byte) (name string, err error) {
+	enum, ok := reg.enums[tag]
+	if !ok {
+		return "", ErrNotEnum
+	} else if name, ok = enum[value]; !ok {
+		return "", ErrUnknownValue
+	}
+	return name, nil
+}
+
+// EnumValue returns the value named name in tag's enumeration.
+func (reg *Registry) EnumValue(tag int, name string) (value byte, err error) {
+	enum, ok := reg.enums[tag]
+	if !ok {
+		return 0, ErrNotEnum
+	}
+	for value, n := range enum {
+		if n == name {
+			return value, nil
+		}
+	}
+	return 0, ErrUnknownValue
+}
+
+// GetEnum returns the symbolic name of the value held by the first record
+// with the requested tag, which must be an enumeration in reg.
+func (recs *TLVList) GetEnum(reg *Registry, tag int) (string, error) {
+	rec, err := recs.Get(tag)
+	if err != nil {
+		return "", err
+	} else if rec.Length() != 1 {
+		if _, ok := reg.enums[tag]; !ok {
+			return "", ErrNotEnum
+		}
+		return "", ErrUnknownValue
+	}
+	return reg.EnumName(tag, rec.Value()[0])
+}
+
+// AddEnum adds a record holding the value named name in tag's
+// enumeration in reg.
+func (recs *TLVList) AddEnum(reg *Registry, tag int, name string) error {
+	value, err := reg.EnumValue(tag, name)
+	if err != nil {
+		return err
+	}
+	recs.Add(tag, []byte{value})
+	return nil
+}
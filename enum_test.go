@@ -0,0 +1,87 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func enumTestRegistry() *Registry {
+	reg := testRegistry()
+	reg.SetEnum(TagTest2, map[byte]string{
+		0: "idle",
+		1: "running",
+		2: "stopped",
+	})
+	return reg
+}
+
+func TestEnum(t *testing.T) {
+	reg := enumTestRegistry()
+
+	tlvl := New()
+	if err := tlvl.AddEnum(reg, TagTest2, "running"); err != nil {
+		FailWithError(t, "TestEnum", err)
+	}
+	if rec, err := tlvl.Get(TagTest2); err != nil {
+		FailWithError(t, "TestEnum", err)
+	} else if !bytes.Equal(rec.Value(), []byte{1}) {
+		FailWithError(t, "TestEnum", noMatch)
+	} else if s := reg.Render(rec); s != "running" {
+		FailWithError(t, "TestEnum",
+			fmt.Errorf("enum rendered as %s", s))
+	}
+
+	if name, err := tlvl.GetEnum(reg, TagTest2); err != nil {
+		FailWithError(t, "TestEnum", err)
+	} else if name != "running" {
+		FailWithError(t, "TestEnum",
+			fmt.Errorf("bad enum name %s", name))
+	}
+
+	if err := tlvl.AddEnum(reg, TagTest2, "exploded"); err != ErrUnknownValue {
+		FailWithError(t, "TestEnum",
+			fmt.Errorf("unknown name should be rejected"))
+	} else if err = tlvl.AddEnum(reg, TagTest1, "running"); err != ErrNotEnum {
+		FailWithError(t, "TestEnum",
+			fmt.Errorf("non-enum tag should be rejected"))
+	}
+
+	tlvl = New()
+	tlvl.Add(TagTest2, []byte{9})
+	if _, err := tlvl.GetEnum(reg, TagTest2); err != ErrUnknownValue {
+		FailWithError(t, "TestEnum",
+			fmt.Errorf("unknown value should be rejected"))
+	} else if s := reg.Render(newTLV(TagTest2, []byte{9})); s != "9" {
+		FailWithError(t, "TestEnum",
+			fmt.Errorf("unknown value rendered as %s", s))
+	}
+
+	err := reg.SetEnum(TagTest3, map[byte]string{0: "on", 1: "on"})
+	if err != ErrDuplicateName {
+		FailWithError(t, "TestEnum",
+			fmt.Errorf("duplicate enum names should be rejected"))
+	}
+}
+
+func TestEnumSchema(t *testing.T) {
+	reg := enumTestRegistry()
+	reg.SetEnum(TagTest6, map[byte]string{1: "yes", 0: "no"})
+
+	buf := new(bytes.Buffer)
+	if err := reg.WriteSchema(buf); err != nil {
+		FailWithError(t, "TestEnumSchema", err)
+	}
+	rreg, err := ReadSchema(buf)
+	if err != nil {
+		FailWithError(t, "TestEnumSchema", err)
+	}
+
+	if name, err := rreg.EnumName(TagTest2, 2); err != nil {
+		FailWithError(t, "TestEnumSchema", err)
+	} else if name != "stopped" {
+		FailWithError(t, "TestEnumSchema", noMatch)
+	} else if name, err = rreg.EnumName(TagTest6, 1); err != nil || name != "yes" {
+		FailWithError(t, "TestEnumSchema", noMatch)
+	}
+}
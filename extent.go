@@ -0,0 +1,38 @@
+package tlv
+
+import "io"
+
+// headerLength is the number of bytes taken up by a record's tag and
+// length on the wire.
+const headerLength = 8
+
+// Type Extent describes where an encoded record sits in a stream. Offset
+// is the position of the first byte of the record's header, and Length
+// is the number of bytes the record occupies on the wire, including the
+// header.
+type Extent struct {
+	Tag    int
+	Offset int64
+	Length int64
+}
+
+// Extents reads TLV records from r and returns the extent of each one in
+// wire order. This allows records to be correlated with the output of
+// tools such as hexdump or dd when inspecting or repairing files by hand.
+func Extents(r io.Reader) (exts []Extent, err error) {
+	var off int64
+	for {
+		var tlv TLV
+		if tlv, err = readRecord(r); err != nil {
+			break
+		}
+		n := int64(headerLength + tlv.Length())
+		exts = append(exts, Extent{tlv.Tag(), off, n})
+		off += n
+	}
+
+	if err == io.EOF {
+		err = nil
+	}
+	return
+}
@@ -0,0 +1,47 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestExtents(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(TagTest1, []byte("foo bar"))
+	tlvl.Add(TagTest2, nil)
+	tlvl.Add(TagTest3, []byte("gophers are everywhere!"))
+
+	buf := new(bytes.Buffer)
+	if err := tlvl.Write(buf); err != nil {
+		FailWithError(t, "TestExtents", err)
+	}
+	raw := buf.Bytes()
+
+	exts, err := Extents(bytes.NewReader(raw))
+	if err != nil {
+		FailWithError(t, "TestExtents", err)
+	} else if len(exts) != 3 {
+		FailWithError(t, "TestExtents",
+			fmt.Errorf("expected 3 extents, got %d", len(exts)))
+	}
+
+	expected := []Extent{
+		{TagTest1, 0, 15},
+		{TagTest2, 15, 8},
+		{TagTest3, 23, 31},
+	}
+	for i, ext := range exts {
+		if ext != expected[i] {
+			FailWithError(t, "TestExtents",
+				fmt.Errorf("extent %d is %+v, expected %+v",
+					i, ext, expected[i]))
+		}
+		rec, err := tlvFromBytes(raw[ext.Offset : ext.Offset+ext.Length])
+		if err != nil {
+			FailWithError(t, "TestExtents", err)
+		} else if rec.Tag() != ext.Tag {
+			FailWithError(t, "TestExtents", noMatch)
+		}
+	}
+}
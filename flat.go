@@ -0,0 +1,164 @@
+package tlv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Type FlatRecord is a leaf record taken out of a nested structure,
+// keyed by the path of tags leading to it. Index gives, for each tag in
+// the path, the record's position among its siblings with the same tag,
+// so that repeated records keep their place. A FlatRecord with
+// Constructed set stands for a Constructed record holding no children,
+// and has no value.
+type FlatRecord struct {
+	Path        []int
+	Index       []int
+	Value       []byte
+	Constructed bool
+}
+
+// Method Key returns the record's path in the form ParseKey accepts,
+// such as "0x70[1]/0x5a", for use as a key in flat key/value stores. A
+// position is written after a tag only if it is not zero, so paths
+// without repeated tags are written as FormatPath writes them. The key
+// of an empty Constructed record ends with a slash.
+func (rec FlatRecord) Key() string {
+	parts := make([]string, len(rec.Path))
+	for i, tag := range rec.Path {
+		parts[i] = fmt.Sprintf("%#x", tag)
+		if i < len(rec.Index) && rec.Index[i] != 0 {
+			parts[i] += fmt.Sprintf("[%d]", rec.Index[i])
+		}
+	}
+	key := strings.Join(parts, "/")
+	if rec.Constructed {
+		key += "/"
+	}
+	return key
+}
+
+// ParseKey parses a key written by FlatRecord.Key, returning a
+// FlatRecord with the path, positions and Constructed flag it gives, and
+// no value. If the key is malformed, ErrPath is returned.
+func ParseKey(key string) (FlatRecord, error) {
+	var rec FlatRecord
+	if strings.HasSuffix(key, "/") {
+		rec.Constructed = true
+		key = key[:len(key)-1]
+	}
+
+	parts := strings.Split(key, "/")
+	rec.Path = make([]int, len(parts))
+	rec.Index = make([]int, len(parts))
+	for i, part := range parts {
+		if open := strings.IndexByte(part, '['); open >= 0 {
+			if !strings.HasSuffix(part, "]") {
+				return FlatRecord{}, ErrPath
+			}
+			n, err := strconv.Atoi(part[open+1 : len(part)-1])
+			if err != nil || n < 0 {
+				return FlatRecord{}, ErrPath
+			}
+			rec.Index[i] = n
+			part = part[:open]
+		}
+		tags, err := ParsePath(part)
+		if err != nil {
+			return FlatRecord{}, err
+		}
+		rec.Path[i] = tags[0]
+	}
+	return rec, nil
+}
+
+// FormatPath formats a path of tags as ParsePath expects.
+func FormatPath(tags []int) string {
+	parts := make([]string, len(tags))
+	for i, tag := range tags {
+		parts[i] = fmt.Sprintf("%#x", tag)
+	}
+	return strings.Join(parts, "/")
+}
+
+// Flatten returns the leaves of a nested list in depth-first order, each
+// with the tags of the Constructed records above it and the positions of
+// those records and the leaf among their siblings with the same tags.
+// Constructed records holding no children are returned as FlatRecords
+// with Constructed set, so that Unflatten rebuilds the list as it was.
+func (recs *TLVList) Flatten() []FlatRecord {
+	return flatten(recs, nil, nil, make([]FlatRecord, 0, recs.Length()))
+}
+
+// flatten appends the leaves of recs, which lies below the tags in
+// path at the positions in index, to flat.
+func flatten(recs *TLVList, path, index []int, flat []FlatRecord) []FlatRecord {
+	seen := make(map[int]int)
+	for _, rec := range recs.records {
+		tags := append(path[:len(path):len(path)], rec.Tag())
+		positions := append(index[:len(index):len(index)], seen[rec.Tag()])
+		seen[rec.Tag()]++
+
+		c, ok := rec.(Constructed)
+		if !ok {
+			flat = append(flat, FlatRecord{Path: tags, Index: positions, Value: rec.Value()})
+		} else if c.Children().Length() == 0 {
+			flat = append(flat, FlatRecord{Path: tags, Index: positions, Constructed: true})
+		} else {
+			flat = flatten(c.Children(), tags, positions, flat)
+		}
+	}
+	return flat
+}
+
+// Unflatten rebuilds a nested list from the output of Flatten.
+// Consecutive records whose paths share a leading tag at the same
+// position are placed in the same Constructed record. Records without
+// positions are taken to be at position zero, so for them adjacent
+// constructed siblings with the same tag are merged into one. If a
+// record has an empty path, or more positions than tags, ErrPath is
+// returned.
+func Unflatten(flat []FlatRecord) (*TLVList, error) {
+	for _, rec := range flat {
+		if len(rec.Path) == 0 || len(rec.Index) > len(rec.Path) {
+			return nil, ErrPath
+		}
+	}
+	return unflatten(flat, 0), nil
+}
+
+// position returns the record's position at the given depth of its
+// path.
+func (rec FlatRecord) position(depth int) int {
+	if depth < len(rec.Index) {
+		return rec.Index[depth]
+	}
+	return 0
+}
+
+// unflatten builds the list at the given depth of the records' paths.
+func unflatten(flat []FlatRecord, depth int) *TLVList {
+	recs := New()
+	for i := 0; i < len(flat); {
+		tag, pos := flat[i].Path[depth], flat[i].position(depth)
+		if len(flat[i].Path) == depth+1 {
+			if flat[i].Constructed {
+				recs.AddList(tag, New())
+			} else {
+				recs.Add(tag, flat[i].Value)
+			}
+			i++
+			continue
+		}
+
+		j := i + 1
+		for j < len(flat) && len(flat[j].Path) > depth+1 &&
+			flat[j].Path[depth] == tag && flat[j].position(depth) == pos {
+			j++
+		}
+		recs.AddList(tag, unflatten(flat[i:j], depth+1))
+		i = j
+	}
+	return recs
+}
@@ -0,0 +1,99 @@
+package tlv
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFlatten(t *testing.T) {
+	recs := nestedFCI(t)
+	flat := recs.Flatten()
+	want := []string{
+		"0x6f/0x84",
+		"0x6f/0xa5/0x88",
+		"0x6f/0xa5/0x5f2d",
+	}
+	if len(flat) != len(want) {
+		FailWithError(t, "TestFlatten", fmt.Errorf("got %d leaves", len(flat)))
+	}
+	for i, rec := range flat {
+		if i < len(want) && rec.Key() != want[i] {
+			FailWithError(t, "TestFlatten", fmt.Errorf("leaf %d is %s", i, rec.Key()))
+		}
+		parsed, err := ParseKey(rec.Key())
+		if err != nil || FormatPath(parsed.Path) != rec.Key() {
+			FailWithError(t, "TestFlatten", ErrPath)
+		}
+	}
+
+	back, err := Unflatten(flat)
+	if err != nil {
+		FailWithError(t, "TestFlatten", err)
+	} else if !CompareLists(recs, back, CompareRules{}).Equal() {
+		FailWithError(t, "TestFlatten", noMatch)
+	}
+
+	if _, err = Unflatten([]FlatRecord{{}}); err != ErrPath {
+		FailWithError(t, "TestFlatten", fmt.Errorf("expected ErrPath, got %v", err))
+	}
+}
+
+func TestFlattenPositions(t *testing.T) {
+	// Two adjacent records with the same tag, one empty.
+	first := New()
+	first.Add(0x5a, []byte{1})
+	first.Add(0x5a, []byte{2})
+	second := New()
+	second.Add(0x5a, []byte{3})
+	recs := New()
+	recs.AddList(0x70, first)
+	recs.AddList(0x70, second)
+	recs.AddList(0x70, New())
+	recs.Add(0x9f, nil)
+
+	flat := recs.Flatten()
+	want := []string{
+		"0x70/0x5a",
+		"0x70/0x5a[1]",
+		"0x70[1]/0x5a",
+		"0x70[2]/",
+		"0x9f",
+	}
+	if len(flat) != len(want) {
+		FailWithError(t, "TestFlattenPositions",
+			fmt.Errorf("got %d leaves", len(flat)))
+	}
+	for i, rec := range flat {
+		if i < len(want) && rec.Key() != want[i] {
+			FailWithError(t, "TestFlattenPositions",
+				fmt.Errorf("leaf %d is %s", i, rec.Key()))
+		}
+	}
+
+	// Rebuild from the keys alone, as from a key/value store.
+	keyed := make([]FlatRecord, len(flat))
+	for i, rec := range flat {
+		parsed, err := ParseKey(rec.Key())
+		if err != nil {
+			FailWithError(t, "TestFlattenPositions", err)
+		}
+		parsed.Value = rec.Value
+		keyed[i] = parsed
+	}
+	back, err := Unflatten(keyed)
+	if err != nil {
+		FailWithError(t, "TestFlattenPositions", err)
+	} else if back.Length() != 4 {
+		FailWithError(t, "TestFlattenPositions",
+			fmt.Errorf("rebuilt %d records", back.Length()))
+	} else if !CompareLists(recs, back, CompareRules{}).Equal() {
+		FailWithError(t, "TestFlattenPositions", noMatch)
+	}
+
+	for _, key := range []string{"0x70[", "0x70[x]", "0x70[-1]", "0x70//0x5a", "/"} {
+		if _, err = ParseKey(key); err != ErrPath {
+			FailWithError(t, "TestFlattenPositions",
+				fmt.Errorf("%q: expected ErrPath, got %v", key, err))
+		}
+	}
+}
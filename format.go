@@ -0,0 +1,190 @@
+package tlv
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// ErrTagRange is returned when a tag cannot be represented in a format;
+// similarly, ErrLengthRange is returned when a length cannot be
+// represented in, or is invalid for, a format.
+var (
+	ErrTagRange    = fmt.Errorf("tag out of range for format")
+	ErrLengthRange = fmt.Errorf("length out of range for format")
+)
+
+// Type Format describes how a record's tag and length are laid out on
+// the wire. The record's value always follows the header unchanged.
+//
+// ReadHeader should return io.EOF only if no bytes could be read at all;
+// a header that is cut short should be reported as io.ErrUnexpectedEOF.
+type Format interface {
+	ReadHeader(r io.Reader) (tag, length int, err error)
+	WriteHeader(w io.Writer, tag, length int) error
+}
+
+// Standard is the package's native format: a 32-bit big-endian tag
+// followed by a 32-bit big-endian length. It is the format used by Read
+// and Write.
+var Standard Format = Fixed{Order: binary.BigEndian}
+
+// ParseHeader parses the Standard record header at the start of b,
+// returning the record's tag and length and the size of the header. It
+// does not allocate, and does not check that b holds the whole value. If
+// b is too short to hold a header, io.ErrUnexpectedEOF is returned.
+func ParseHeader(b []byte) (tag, length, headerLen int, err error) {
+	if len(b) < headerLength {
+		return 0, 0, 0, io.ErrUnexpectedEOF
+	}
+	tag = int(int32(binary.BigEndian.Uint32(b)))
+	length = int(int32(binary.BigEndian.Uint32(b[4:])))
+	if length < 0 {
+		return 0, 0, 0, ErrLengthRange
+	}
+	return tag, length, headerLength, nil
+}
+
+// Wide is Standard with 64-bit lengths, for values too large to be
+// described by a 32-bit length.
+var Wide Format = Fixed{Order: binary.BigEndian, Wide: true}
+
+// Type Fixed is a format with a 32-bit signed tag followed by a 32-bit
+// length, both written in Order. Standard uses network byte order, which
+// is also used if Order is nil; Fixed{Order: binary.LittleEndian} gives
+// the same layout in little-endian byte order. If Wide is set, lengths
+// are written as 64-bit unsigned integers instead.
+//
+// A length that cannot be represented is rejected with ErrLengthRange,
+// rather than being truncated.
+type Fixed struct {
+	Order binary.ByteOrder
+	Wide  bool
+}
+
+func (f Fixed) order() binary.ByteOrder {
+	if f.Order == nil {
+		return binary.BigEndian
+	}
+	return f.Order
+}
+
+func (f Fixed) size() int {
+	if f.Wide {
+		return 12
+	}
+	return 8
+}
+
+// ReadHeader reads a record header in the format.
+func (f Fixed) ReadHeader(r io.Reader) (tag, length int, err error) {
+	var hdr [12]byte
+	if _, err = io.ReadFull(r, hdr[:f.size()]); err != nil {
+		return
+	}
+	return f.parse(hdr[:])
+}
+
+func (f Fixed) parse(hdr []byte) (tag, length int, err error) {
+	order := f.order()
+	tag = int(int32(order.Uint32(hdr)))
+	if !f.Wide {
+		length = int(int32(order.Uint32(hdr[4:])))
+		return
+	}
+
+	n := order.Uint64(hdr[4:])
+	if n > maxInt {
+		return 0, 0, ErrLengthRange
+	}
+	length = int(n)
+	return
+}
+
+// WriteHeader writes a record header in the format.
+func (f Fixed) WriteHeader(w io.Writer, tag, length int) error {
+	if int(int32(tag)) != tag {
+		return ErrTagRange
+	} else if length < 0 || (!f.Wide && int(int32(length)) != length) {
+		return ErrLengthRange
+	}
+
+	var hdr [12]byte
+	order := f.order()
+	order.PutUint32(hdr[:], uint32(tag))
+	if f.Wide {
+		order.PutUint64(hdr[4:], uint64(length))
+	} else {
+		order.PutUint32(hdr[4:], uint32(length))
+	}
+	_, err := w.Write(hdr[:f.size()])
+	return err
+}
+
+// Varint is a compact format in which the tag and length are each
+// written as an unsigned LEB128 varint, as produced by
+// binary.PutUvarint. Tags and lengths under 128 take a single byte, so a
+// small record's header is typically two bytes rather than the eight
+// used by Standard. Negative tags cannot be written in this format.
+var Varint Format = varintFormat{}
+
+type varintFormat struct{}
+
+func (varintFormat) ReadHeader(r io.Reader) (tag, length int, err error) {
+	br := asByteReader(r)
+	n, err := binary.ReadUvarint(br)
+	if err != nil {
+		return
+	} else if n > maxInt {
+		return 0, 0, ErrTagRange
+	}
+	tag = int(n)
+
+	n, err = binary.ReadUvarint(br)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return
+	} else if n > maxInt {
+		return 0, 0, ErrLengthRange
+	}
+	length = int(n)
+	return
+}
+
+func (varintFormat) WriteHeader(w io.Writer, tag, length int) error {
+	if tag < 0 {
+		return ErrTagRange
+	} else if length < 0 {
+		return ErrLengthRange
+	}
+
+	var hdr [2 * binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(hdr[:], uint64(tag))
+	n += binary.PutUvarint(hdr[n:], uint64(length))
+	_, err := w.Write(hdr[:n])
+	return err
+}
+
+const maxInt = uint64(^uint(0) >> 1)
+
+// asByteReader returns r as an io.ByteReader, wrapping it if necessary.
+// The wrapper reads one byte at a time, so that no input beyond the end
+// of the record is consumed.
+func asByteReader(r io.Reader) io.ByteReader {
+	if br, ok := r.(io.ByteReader); ok {
+		return br
+	}
+	return &byteReader{r: r}
+}
+
+type byteReader struct {
+	r io.Reader
+	b [1]byte
+}
+
+func (br *byteReader) ReadByte() (byte, error) {
+	_, err := io.ReadFull(br.r, br.b[:])
+	return br.b[0], err
+}
@@ -0,0 +1,164 @@
+package tlv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"testing"
+)
+
+func TestVarint(t *testing.T) {
+	tlvl := New()
+	for i := 0; i < 1000; i++ {
+		tlvl.Add(i%100, []byte{byte(i), byte(i >> 8)})
+	}
+
+	std := encodeFormat(t, "TestVarint", Standard, tlvl)
+	vi := encodeFormat(t, "TestVarint", Varint, tlvl)
+	if len(std) != 1000*10 {
+		FailWithError(t, "TestVarint",
+			fmt.Errorf("standard encoding is %d bytes", len(std)))
+	} else if len(vi) != 1000*4 {
+		FailWithError(t, "TestVarint",
+			fmt.Errorf("varint encoding is %d bytes", len(vi)))
+	}
+
+	rtlvl := decodeFormat(t, "TestVarint", Varint, vi)
+	if n := CompareOrder(tlvl, rtlvl); n != -1 {
+		FailWithError(t, "TestVarint",
+			fmt.Errorf("record %d differs", n))
+	}
+
+	tag, length, err := Varint.ReadHeader(bytes.NewReader([]byte{0xac, 0x02, 0x80, 0x01}))
+	if err != nil {
+		FailWithError(t, "TestVarint", err)
+	} else if tag != 300 || length != 128 {
+		FailWithError(t, "TestVarint",
+			fmt.Errorf("bad header: tag %d, length %d", tag, length))
+	}
+
+	dec := NewDecoder(bytes.NewReader([]byte{0xac, 0x02, 0x80}))
+	dec.Format = Varint
+	if _, err = dec.Decode(); err != ErrTLVRead {
+		FailWithError(t, "TestVarint",
+			fmt.Errorf("truncated header should fail"))
+	}
+}
+
+func TestFixedByteOrder(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(0x0102, []byte("foo"))
+
+	big := encodeFormat(t, "TestFixedByteOrder", Standard, tlvl)
+	little := encodeFormat(t, "TestFixedByteOrder", Fixed{Order: binary.LittleEndian}, tlvl)
+
+	buf := new(bytes.Buffer)
+	if err := tlvl.Write(buf); err != nil {
+		FailWithError(t, "TestFixedByteOrder", err)
+	} else if !bytes.Equal(buf.Bytes(), big) {
+		FailWithError(t, "TestFixedByteOrder",
+			fmt.Errorf("Standard does not match Write"))
+	}
+
+	expected := []byte{2, 1, 0, 0, 3, 0, 0, 0, 'f', 'o', 'o'}
+	if !bytes.Equal(little, expected) {
+		FailWithError(t, "TestFixedByteOrder",
+			fmt.Errorf("bad little-endian encoding %x", little))
+	}
+
+	rtlvl := decodeFormat(t, "TestFixedByteOrder", Fixed{Order: binary.LittleEndian}, little)
+	if rec, err := rtlvl.Get(0x0102); err != nil {
+		FailWithError(t, "TestFixedByteOrder", err)
+	} else if string(rec.Value()) != "foo" {
+		FailWithError(t, "TestFixedByteOrder", noMatch)
+	}
+
+	s := NewScanner(bytes.NewReader(little))
+	s.Format = Fixed{Order: binary.LittleEndian}
+	if !s.Scan() || s.Tag() != 0x0102 {
+		FailWithError(t, "TestFixedByteOrder", noMatch)
+	}
+}
+
+// wideLength is too long for a 32-bit length field. Where int is only
+// 32 bits wide, no length is, so wideLength is zero and the tests using
+// it are skipped.
+const wideLength = (1 << 32) * (strconv.IntSize / 64)
+
+// bigRecord claims a value longer than a 32-bit length can describe,
+// without allocating one.
+type bigRecord struct{}
+
+func (bigRecord) Tag() int      { return TagTest1 }
+func (bigRecord) Length() int   { return wideLength }
+func (bigRecord) Value() []byte { return nil }
+
+func TestWide(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(TagTest1, []byte("foo bar"))
+	raw := encodeFormat(t, "TestWide", Wide, tlvl)
+	if len(raw) != 12+7 {
+		FailWithError(t, "TestWide",
+			fmt.Errorf("bad wide encoding %x", raw))
+	}
+	rtlvl := decodeFormat(t, "TestWide", Wide, raw)
+	if rec, err := rtlvl.Get(TagTest1); err != nil {
+		FailWithError(t, "TestWide", err)
+	} else if string(rec.Value()) != "foo bar" {
+		FailWithError(t, "TestWide", noMatch)
+	}
+}
+
+func TestWideLength(t *testing.T) {
+	if wideLength == 0 {
+		t.Skip("int cannot hold a length wider than 32 bits")
+	}
+
+	buf := new(bytes.Buffer)
+	if err := Wide.WriteHeader(buf, TagTest1, wideLength); err != nil {
+		FailWithError(t, "TestWideLength", err)
+	}
+	if _, length, err := Wide.ReadHeader(buf); err != nil {
+		FailWithError(t, "TestWideLength", err)
+	} else if length != wideLength {
+		FailWithError(t, "TestWideLength",
+			fmt.Errorf("bad length %d", length))
+	}
+
+	if err := Standard.WriteHeader(buf, TagTest1, wideLength); err != ErrLengthRange {
+		FailWithError(t, "TestWideLength",
+			fmt.Errorf("32-bit length should overflow"))
+	}
+	if err := writeRecord(bigRecord{}, buf); err != ErrLengthRange {
+		FailWithError(t, "TestWideLength",
+			fmt.Errorf("writeRecord should not truncate lengths"))
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	raw := []byte{0, 0, 0, 1, 0, 0, 0, 3, 'a', 'b', 'c'}
+	tag, length, n, err := ParseHeader(raw)
+	if err != nil {
+		FailWithError(t, "TestParseHeader", err)
+	} else if tag != TagTest2 || length != 3 || n != 8 {
+		FailWithError(t, "TestParseHeader", noMatch)
+	}
+
+	if _, _, _, err = ParseHeader(raw[:7]); err != io.ErrUnexpectedEOF {
+		FailWithError(t, "TestParseHeader",
+			fmt.Errorf("short header should fail"))
+	} else if _, _, _, err = ParseHeader([]byte{0, 0, 0, 1, 0xff, 0, 0, 0}); err != ErrLengthRange {
+		FailWithError(t, "TestParseHeader",
+			fmt.Errorf("negative length should fail"))
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		ParseHeader(raw)
+	})
+	if allocs != 0 {
+		FailWithError(t, "TestParseHeader",
+			fmt.Errorf("ParseHeader allocated %v times", allocs))
+	}
+}
@@ -0,0 +1,133 @@
+package tlv
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ErrFraming is returned when a length-prefixed chunk is malformed.
+var ErrFraming = fmt.Errorf("invalid length-prefixed chunk")
+
+// Type Framing is a tagless framing of length-prefixed chunks, as used
+// by pipelines that carry values without any notion of a tag.
+type Framing int
+
+// The supported framings.
+const (
+	// FramingNetstring writes each chunk as a netstring: its length
+	// in decimal, a colon, the bytes, and a comma, as in "5:hello,".
+	FramingNetstring Framing = iota
+
+	// FramingUvarint prefixes each chunk with its length as an
+	// unsigned varint, as written by binary.PutUvarint.
+	FramingUvarint
+)
+
+// maxChunk is the largest chunk accepted when reading a framing.
+const maxChunk = 1<<31 - 1
+
+// ReadFramed reads length-prefixed chunks from r until the end of the
+// stream, returning them as records. Chunks carry no tags, so the tag of
+// the i'th chunk, counting from zero, is tags(i); if tags is nil, the
+// chunk's index is used as its tag.
+func ReadFramed(r io.Reader, framing Framing, tags func(i int) int) (*TLVList, error) {
+	br := asByteReader(r)
+	recs := New()
+	for i := 0; ; i++ {
+		length, err := readFrameLength(br, framing)
+		if err == io.EOF {
+			return recs, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		value, err := readValue(r, length)
+		if err != nil {
+			return nil, ErrTLVRead
+		}
+		if framing == FramingNetstring {
+			if b, err := br.ReadByte(); err != nil || b != ',' {
+				return nil, ErrFraming
+			}
+		}
+
+		tag := i
+		if tags != nil {
+			tag = tags(i)
+		}
+		recs.records = append(recs.records, &record{tag: tag, length: length, value: value})
+	}
+}
+
+func readFrameLength(br io.ByteReader, framing Framing) (length int, err error) {
+	switch framing {
+	case FramingUvarint:
+		n, err := binary.ReadUvarint(br)
+		if err == io.ErrUnexpectedEOF {
+			return 0, ErrTLVRead
+		} else if err != nil {
+			return 0, err
+		} else if n > maxChunk {
+			return 0, ErrLengthRange
+		}
+		return int(n), nil
+	case FramingNetstring:
+		var digits []byte
+		for {
+			b, err := br.ReadByte()
+			if err == io.EOF && len(digits) == 0 {
+				return 0, io.EOF
+			} else if err != nil {
+				return 0, ErrTLVRead
+			} else if b == ':' {
+				break
+			} else if b < '0' || b > '9' || len(digits) == 10 {
+				return 0, ErrFraming
+			}
+			digits = append(digits, b)
+		}
+		if len(digits) == 0 || (digits[0] == '0' && len(digits) > 1) {
+			return 0, ErrFraming
+		}
+		n, err := strconv.ParseInt(string(digits), 10, 64)
+		if err != nil || n > maxChunk {
+			return 0, ErrLengthRange
+		}
+		return int(n), nil
+	}
+	return 0, ErrFraming
+}
+
+// WriteFramed writes the value of every record in recs to w as a
+// length-prefixed chunk, discarding the tags.
+func WriteFramed(w io.Writer, framing Framing, recs *TLVList) error {
+	var hdr [binary.MaxVarintLen64 + 1]byte
+	for _, tlv := range recs.records {
+		value := tlv.Value()
+
+		var prefix []byte
+		switch framing {
+		case FramingUvarint:
+			prefix = hdr[:binary.PutUvarint(hdr[:], uint64(len(value)))]
+		case FramingNetstring:
+			prefix = strconv.AppendInt(hdr[:0], int64(len(value)), 10)
+			prefix = append(prefix, ':')
+		default:
+			return ErrFraming
+		}
+
+		if _, err := w.Write(prefix); err != nil {
+			return ErrTLVWrite
+		} else if _, err = w.Write(value); err != nil {
+			return ErrTLVWrite
+		}
+		if framing == FramingNetstring {
+			if _, err := w.Write([]byte{','}); err != nil {
+				return ErrTLVWrite
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestFramed(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(TagTest1, []byte("hello"))
+	tlvl.Add(TagTest2, nil)
+	tlvl.Add(TagTest3, []byte("world!"))
+
+	var tests = []struct {
+		framing Framing
+		raw     string
+	}{
+		{FramingNetstring, "5:hello,0:,6:world!,"},
+		{FramingUvarint, "\x05hello\x00\x06world!"},
+	}
+
+	for _, tt := range tests {
+		buf := new(bytes.Buffer)
+		if err := WriteFramed(buf, tt.framing, tlvl); err != nil {
+			FailWithError(t, "TestFramed", err)
+		} else if buf.String() != tt.raw {
+			FailWithError(t, "TestFramed",
+				fmt.Errorf("framed as %q, expected %q", buf.String(), tt.raw))
+		}
+
+		recs, err := ReadFramed(buf, tt.framing, nil)
+		if err != nil {
+			FailWithError(t, "TestFramed", err)
+		} else if !CompareLists(recs, tlvl, CompareRules{}).Equal() {
+			FailWithError(t, "TestFramed", noMatch)
+		}
+	}
+
+	tags := func(i int) int { return []int{TagTest4, TagTest5}[i%2] }
+	recs, err := ReadFramed(bytes.NewBufferString("1:a,1:b,1:c,"), FramingNetstring, tags)
+	if err != nil {
+		FailWithError(t, "TestFramed", err)
+	} else if recs.Spec() != "3=str:a, 4=str:b, 3=str:c" {
+		FailWithError(t, "TestFramed", noMatch)
+	}
+
+	for _, bad := range []string{"5:hello;", "05:hello,", "x:", "3:ab"} {
+		if _, err = ReadFramed(bytes.NewBufferString(bad), FramingNetstring, nil); err == nil {
+			FailWithError(t, "TestFramed",
+				fmt.Errorf("%q was accepted", bad))
+		}
+	}
+}
+
+func TestFramedHostileLength(t *testing.T) {
+	// A netstring claiming a 2 GB chunk, followed by nothing.
+	var err error
+	n := allocatedBy(func() {
+		_, err = ReadFramed(bytes.NewReader([]byte("2147483647:")), FramingNetstring, nil)
+	})
+	if err != ErrTLVRead {
+		FailWithError(t, "TestFramedHostileLength",
+			fmt.Errorf("expected ErrTLVRead, got %v", err))
+	} else if n > 1<<20 {
+		FailWithError(t, "TestFramedHostileLength",
+			fmt.Errorf("%d bytes allocated for a short input", n))
+	}
+}
@@ -0,0 +1,102 @@
+package tlv
+
+import "fmt"
+
+// ErrFRU is returned when an IPMI FRU multirecord area is malformed.
+var ErrFRU = fmt.Errorf("invalid FRU multirecord area")
+
+// Record types of the IPMI FRU multirecord area (Platform Management
+// FRU Information Storage Definition, section 18). Types from FRUOEM
+// to 0xFF are OEM records.
+const (
+	FRUPowerSupply           = 0x00
+	FRUDCOutput              = 0x01
+	FRUDCLoad                = 0x02
+	FRUManagementAccess      = 0x03
+	FRUBaseCompatibility     = 0x04
+	FRUExtendedCompatibility = 0x05
+	FRUOEM                   = 0xc0
+)
+
+const (
+	fruHeaderLength = 5
+	fruEndOfList    = 0x80
+	fruVersion      = 0x02
+)
+
+// fruChecksum returns the zero checksum of b: the byte that makes the
+// sum of b and the checksum zero, modulo 256.
+func fruChecksum(b []byte) byte {
+	var sum byte
+	for _, c := range b {
+		sum += c
+	}
+	return -sum
+}
+
+// ReadFRUMultiRecords decodes an IPMI FRU multirecord area. Each record
+// has a five-byte header: its type, which becomes the tag, a byte
+// holding the end-of-list flag and the format version, the data length,
+// a checksum of the data, and a checksum of the header. Records are
+// read up to the one marked as the end of the list; any bytes after it
+// are ignored. A record whose checksums do not match fails with
+// ErrCorrupt.
+func ReadFRUMultiRecords(area []byte) (*TLVList, error) {
+	recs := New()
+	for {
+		if len(area) < fruHeaderLength {
+			return nil, ErrTLVRead
+		}
+		hdr := area[:fruHeaderLength]
+		if fruChecksum(hdr) != 0 {
+			return nil, ErrCorrupt
+		} else if hdr[1]&0x0f != fruVersion {
+			return nil, ErrFRU
+		}
+
+		length := int(hdr[2])
+		if len(area) < fruHeaderLength+length {
+			return nil, ErrTLVRead
+		}
+		data := area[fruHeaderLength : fruHeaderLength+length]
+		if fruChecksum(data) != hdr[3] {
+			return nil, ErrCorrupt
+		}
+		recs.Add(int(hdr[0]), data)
+
+		if hdr[1]&fruEndOfList != 0 {
+			return recs, nil
+		}
+		area = area[fruHeaderLength+length:]
+	}
+}
+
+// WriteFRUMultiRecords encodes recs as an IPMI FRU multirecord area,
+// computing each record's checksums and marking the last record as the
+// end of the list. Tags must be between 0 and 255, and values may be at
+// most 255 bytes long. The area is not padded; FRU areas are a multiple
+// of eight bytes long, and the caller must pad it with zeros before
+// computing the offsets in the FRU common header.
+func WriteFRUMultiRecords(recs *TLVList) ([]byte, error) {
+	if recs.Length() == 0 {
+		return nil, ErrFRU
+	}
+
+	var area []byte
+	for i, tlv := range recs.records {
+		if tlv.Tag() < 0 || tlv.Tag() > 0xff {
+			return nil, ErrTagRange
+		} else if tlv.Length() > 0xff {
+			return nil, ErrLengthRange
+		}
+
+		hdr := []byte{byte(tlv.Tag()), fruVersion, byte(tlv.Length()), fruChecksum(tlv.Value()), 0}
+		if i == recs.Length()-1 {
+			hdr[1] |= fruEndOfList
+		}
+		hdr[4] = fruChecksum(hdr[:4])
+		area = append(area, hdr...)
+		area = append(area, tlv.Value()...)
+	}
+	return area, nil
+}
@@ -0,0 +1,43 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestFRUMultiRecords(t *testing.T) {
+	recs := New()
+	recs.Add(FRUManagementAccess, []byte{0x01, 's', 'y', 's', '1'})
+	recs.Add(FRUOEM, []byte{0x57, 0x01, 0x00, 0xaa})
+
+	area, err := WriteFRUMultiRecords(recs)
+	if err != nil {
+		FailWithError(t, "TestFRUMultiRecords", err)
+	}
+
+	// Data sum 0x01+0x73+0x79+0x73+0x31 = 0x191, so its checksum is
+	// 0x6f; the header sums to zero with its own checksum.
+	want := []byte{0x03, 0x02, 0x05, 0x6f, 0x87}
+	if !bytes.Equal(area[:5], want) {
+		FailWithError(t, "TestFRUMultiRecords",
+			fmt.Errorf("header is % x", area[:5]))
+	} else if area[1]&0x80 != 0 || area[11]&0x80 == 0 {
+		FailWithError(t, "TestFRUMultiRecords", noMatch)
+	}
+
+	// The area is padded to a multiple of eight bytes.
+	padded := append(area, make([]byte, 8-len(area)%8)...)
+	out, err := ReadFRUMultiRecords(padded)
+	if err != nil {
+		FailWithError(t, "TestFRUMultiRecords", err)
+	} else if !CompareLists(out, recs, CompareRules{}).Equal() {
+		FailWithError(t, "TestFRUMultiRecords", noMatch)
+	}
+
+	area[6] ^= 0xff
+	if _, err = ReadFRUMultiRecords(area); err != ErrCorrupt {
+		FailWithError(t, "TestFRUMultiRecords",
+			fmt.Errorf("expected ErrCorrupt, got %v", err))
+	}
+}
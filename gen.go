@@ -0,0 +1,213 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ErrGoName is returned by GenerateAccessors when a tag name cannot be
+// turned into a Go identifier, or the accessors for two names, or for a
+// name and the embedded TLVList, would have the same name.
+var ErrGoName = fmt.Errorf("tag name does not make a unique Go identifier")
+
+// goKinds maps value kinds to the Go type carrying them, the expression
+// decoding it from value, and the expression encoding v.
+var goKinds = map[string][3]string{
+	"str": {"string", "string(value)", "[]byte(v)"},
+	"hex": {"[]byte", "append([]byte(nil), value...)", "v"},
+	"u8":  {"uint8", "value[0]", "[]byte{v}"},
+	"u16": {"uint16", "binary.BigEndian.Uint16(value)", "binary.BigEndian.AppendUint16(nil, v)"},
+	"u32": {"uint32", "binary.BigEndian.Uint32(value)", "binary.BigEndian.AppendUint32(nil, v)"},
+	"u64": {"uint64", "binary.BigEndian.Uint64(value)", "binary.BigEndian.AppendUint64(nil, v)"},
+}
+
+// kindSizes gives the value length of fixed-size kinds.
+var kindSizes = map[string]int{"u8": 1, "u16": 2, "u32": 4, "u64": 8}
+
+// embeddedNames returns the names a generated wrapper has through its
+// embedded TLVList: the field itself and every method it promotes.
+func embeddedNames() map[string]bool {
+	names := map[string]bool{"TLVList": true}
+	t := reflect.TypeOf(&TLVList{})
+	for i := 0; i < t.NumMethod(); i++ {
+		names[t.Method(i).Name] = true
+	}
+	return names
+}
+
+// goName turns a tag name such as "serial-number" into an exported Go
+// identifier such as SerialNumber.
+func goName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if b.Len() == 0 && unicode.IsDigit(r) {
+			b.WriteString("Tag")
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// GenerateAccessors writes Go source for package pkg declaring typeName,
+// a wrapper around a TLVList with typed accessors for each named tag in
+// the registry. The Go type of each accessor follows the tag's kind:
+// string for str, []byte for hex, and uint8 through uint64 for u8
+// through u64. For a tag named serial-number, the wrapper has
+//
+//	SerialNumber() string      // the first value, or "" if absent
+//	HasSerialNumber() bool
+//	SetSerialNumber(v string)  // replaces any existing records
+//
+// or, if the tag's constraint marks it as repeated,
+//
+//	SerialNumberList() []string
+//	AddSerialNumber(v string)
+//
+// Getters skip integer values of the wrong length. Names whose accessors
+// would clash with each other, or with the methods of the embedded
+// TLVList, such as a tag named "length" or both "foo" and "has-foo", are
+// rejected with ErrGoName. It is intended to be run through the tlvgen
+// command from a go:generate directive.
+func (reg *Registry) GenerateAccessors(w io.Writer, pkg, typeName string) error {
+	tags := make([]int, 0, len(reg.names))
+	for tag := range reg.names {
+		tags = append(tags, tag)
+	}
+	sort.Ints(tags)
+
+	body := new(bytes.Buffer)
+	seen := embeddedNames()
+	needBinary := false
+	for _, tag := range tags {
+		name := goName(reg.names[tag])
+		if name == "" {
+			return ErrGoName
+		}
+		methods := []string{name, "Has" + name, "Set" + name}
+		if reg.Constraint(tag).Repeated {
+			methods = []string{name + "List", "Add" + name}
+		}
+		for _, method := range methods {
+			if seen[method] {
+				return ErrGoName
+			}
+			seen[method] = true
+		}
+
+		kind := reg.Kind(tag)
+		needBinary = needBinary || kindSizes[kind] > 1
+		if reg.Constraint(tag).Repeated {
+			writeRepeatedAccessors(body, typeName, name, tag, kind)
+		} else {
+			writeAccessors(body, typeName, name, tag, kind)
+		}
+	}
+
+	src := new(bytes.Buffer)
+	fmt.Fprintf(src, "// Code generated by tlvgen. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	if needBinary {
+		fmt.Fprintf(src, "import (\n\t\"encoding/binary\"\n\n\t\"github.com/gokyle/tlv\"\n)\n\n")
+	} else {
+		fmt.Fprintf(src, "import \"github.com/gokyle/tlv\"\n\n")
+	}
+	fmt.Fprintf(src, "// %s is a TLVList with typed accessors.\n", typeName)
+	fmt.Fprintf(src, "type %s struct {\n\t*tlv.TLVList\n}\n\n", typeName)
+	fmt.Fprintf(src, "// New%s returns an empty %[1]s.\n", typeName)
+	fmt.Fprintf(src, "func New%s() *%[1]s {\n\treturn &%[1]s{tlv.New()}\n}\n", typeName)
+	src.Write(body.Bytes())
+
+	out, err := format.Source(src.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// valueCheck returns a statement skipping values of the wrong length
+// for kind, if it has a fixed size.
+func valueCheck(kind string) string {
+	if size, ok := kindSizes[kind]; ok {
+		return fmt.Sprintf("if len(value) != %d {\n\tcontinue\n}\n", size)
+	}
+	return ""
+}
+
+// writeAccessors writes the getter, presence check and setter for a
+// tag that occurs at most once.
+func writeAccessors(w io.Writer, typeName, name string, tag int, kind string) {
+	gk := goKinds[kind]
+	getter := `
+// %[2]s returns the value of tag %#[3]x, or the zero value if it is absent.
+func (m *%[1]s) %[2]s() %[4]s {
+	rec, err := m.Get(%#[3]x)
+	if err != nil {
+		var zero %[4]s
+		return zero
+	}
+	value := rec.Value()
+	return %[6]s
+}
+`
+	if _, ok := kindSizes[kind]; ok {
+		getter = `
+// %[2]s returns the value of tag %#[3]x, or the zero value if it is absent.
+func (m *%[1]s) %[2]s() %[4]s {
+	for _, rec := range m.GetAll(%#[3]x) {
+		value := rec.Value()
+		%[5]sreturn %[6]s
+	}
+	return 0
+}
+`
+	}
+	fmt.Fprintf(w, getter+`
+// Has%[2]s reports whether tag %#[3]x is present.
+func (m *%[1]s) Has%[2]s() bool {
+	_, err := m.Get(%#[3]x)
+	return err == nil
+}
+
+// Set%[2]s replaces any records with tag %#[3]x with one holding v.
+func (m *%[1]s) Set%[2]s(v %[4]s) {
+	m.Set(%#[3]x, %[7]s)
+}
+`, typeName, name, tag, gk[0], valueCheck(kind), gk[1], gk[2])
+}
+
+// writeRepeatedAccessors writes the list getter and adder for a
+// repeated tag.
+func writeRepeatedAccessors(w io.Writer, typeName, name string, tag int, kind string) {
+	gk := goKinds[kind]
+	fmt.Fprintf(w, `
+// %[2]sList returns the values of tag %#[3]x, in order.
+func (m *%[1]s) %[2]sList() []%[4]s {
+	var values []%[4]s
+	for _, rec := range m.GetAll(%#[3]x) {
+		value := rec.Value()
+		%[5]svalues = append(values, %[6]s)
+	}
+	return values
+}
+
+// Add%[2]s adds a record with tag %#[3]x holding v.
+func (m *%[1]s) Add%[2]s(v %[4]s) {
+	m.Add(%#[3]x, %[7]s)
+}
+`, typeName, name, tag, gk[0], valueCheck(kind), gk[1], gk[2])
+}
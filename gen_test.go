@@ -0,0 +1,148 @@
+package tlv
+
+import (
+	"bytes"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+// genStub declares the parts of this package that generated accessors
+// use, so that generated code can be type-checked without building the
+// package. The assignments below keep it in step with the real API.
+const genStub = `package tlv
+
+type TLV interface {
+	Tag() int
+	Length() int
+	Value() []byte
+}
+
+type TLVList struct{}
+
+func New() *TLVList                                  { return nil }
+func (recs *TLVList) Get(tag int) (t TLV, err error) { return nil, nil }
+func (recs *TLVList) GetAll(tag int) (ts []TLV)      { return nil }
+func (recs *TLVList) Add(tag int, value []byte)      {}
+func (recs *TLVList) Set(tag int, value []byte)      {}
+`
+
+var (
+	_ func() *TLVList        = New
+	_ func(int) (TLV, error) = New().Get
+	_ func(int) []TLV        = New().GetAll
+	_ func(int, []byte)      = New().Add
+	_ func(int, []byte)      = New().Set
+)
+
+// stubImporter imports the stub in place of this package, and the
+// standard library as usual.
+type stubImporter struct {
+	fset *token.FileSet
+	std  types.Importer
+}
+
+func (imp stubImporter) Import(path string) (*types.Package, error) {
+	if path != "github.com/gokyle/tlv" {
+		return imp.std.Import(path)
+	}
+	file, err := parser.ParseFile(imp.fset, "stub.go", genStub, 0)
+	if err != nil {
+		return nil, err
+	}
+	conf := types.Config{}
+	return conf.Check(path, imp.fset, []*ast.File{file}, nil)
+}
+
+// typeCheck parses and type-checks generated source.
+func typeCheck(src []byte) error {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "gen.go", src, 0)
+	if err != nil {
+		return err
+	}
+	conf := types.Config{
+		Importer: stubImporter{fset, importer.ForCompiler(fset, "source", nil)},
+	}
+	_, err = conf.Check("device", fset, []*ast.File{file}, nil)
+	return err
+}
+
+func TestGenerateAccessors(t *testing.T) {
+	reg := NewRegistry()
+	reg.Register(TagTest1, "serial-number")
+	reg.Register(TagTest2, "port")
+	reg.SetKind(TagTest2, "u16")
+	reg.Register(TagTest3, "alias")
+	reg.Constrain(TagTest3, Constraint{Repeated: true})
+
+	buf := new(bytes.Buffer)
+	if err := reg.GenerateAccessors(buf, "device", "Device"); err != nil {
+		FailWithError(t, "TestGenerateAccessors", err)
+	}
+	src := buf.String()
+	for _, want := range []string{
+		"package device",
+		"\"encoding/binary\"",
+		"type Device struct",
+		"func (m *Device) SerialNumber() string",
+		"func (m *Device) HasSerialNumber() bool",
+		"func (m *Device) SetSerialNumber(v string)",
+		"func (m *Device) Port() uint16",
+		"if len(value) != 2",
+		"func (m *Device) AliasList() []string",
+		"func (m *Device) AddAlias(v string)",
+	} {
+		if !strings.Contains(src, want) {
+			FailWithError(t, "TestGenerateAccessors", noMatch)
+			t.Logf("missing %q in:\n%s", want, src)
+		}
+	}
+
+	if err := typeCheck(buf.Bytes()); err != nil {
+		FailWithError(t, "TestGenerateAccessors", err)
+	}
+
+	reg.Register(TagTest4, "serial number")
+	if err := reg.GenerateAccessors(buf, "device", "Device"); err != ErrGoName {
+		FailWithError(t, "TestGenerateAccessors", err)
+	}
+}
+
+func TestGenerateAccessorsClash(t *testing.T) {
+	for _, names := range [][]string{
+		{"foo", "has-foo"},
+		{"foo", "set foo"},
+		{"length"},
+		{"get-all"},
+		{"remove"},
+		{"set"},
+	} {
+		reg := NewRegistry()
+		for i, name := range names {
+			reg.Register(TagTest1+i, name)
+		}
+		err := reg.GenerateAccessors(new(bytes.Buffer), "device", "Device")
+		if err != ErrGoName {
+			t.Logf("names %q gave %v", names, err)
+			FailWithError(t, "TestGenerateAccessorsClash", noMatch)
+		}
+	}
+
+	// A repeated tag has no getter or setter of its own name, so
+	// these do not clash.
+	reg := NewRegistry()
+	reg.Register(TagTest1, "foo")
+	reg.Constrain(TagTest1, Constraint{Repeated: true})
+	reg.Register(TagTest2, "set-foo")
+	buf := new(bytes.Buffer)
+	if err := reg.GenerateAccessors(buf, "device", "Device"); err != nil {
+		FailWithError(t, "TestGenerateAccessorsClash", err)
+	} else if err = typeCheck(buf.Bytes()); err != nil {
+		FailWithError(t, "TestGenerateAccessorsClash", err)
+	}
+}
@@ -0,0 +1,72 @@
+package tlv
+
+import (
+	"fmt"
+	"testing"
+)
+
+type testMessage struct {
+	Name string
+}
+
+func (m *testMessage) MarshalTLV() (*TLVList, error) {
+	recs := New()
+	recs.Add(TagTest1, []byte(m.Name))
+	return recs, nil
+}
+
+func (m *testMessage) UnmarshalTLV(recs *TLVList) error {
+	rec, err := recs.Get(TagTest1)
+	if err != nil {
+		return err
+	}
+	m.Name = string(rec.Value())
+	return nil
+}
+
+func TestGRPCCodec(t *testing.T) {
+	var codec GRPCCodec
+	if codec.Name() != "tlv" {
+		FailWithError(t, "TestGRPCCodec",
+			fmt.Errorf("bad codec name %s", codec.Name()))
+	}
+
+	data, err := codec.Marshal(&testMessage{"gopher"})
+	if err != nil {
+		FailWithError(t, "TestGRPCCodec", err)
+	}
+
+	tlvl := New()
+	if err = codec.Unmarshal(data, tlvl); err != nil {
+		FailWithError(t, "TestGRPCCodec", err)
+	} else if rec, err := tlvl.Get(TagTest1); err != nil {
+		FailWithError(t, "TestGRPCCodec", err)
+	} else if string(rec.Value()) != "gopher" {
+		FailWithError(t, "TestGRPCCodec", noMatch)
+	}
+
+	tlvl.Add(TagTest2, []byte("baz quux"))
+	if data, err = codec.Marshal(tlvl); err != nil {
+		FailWithError(t, "TestGRPCCodec", err)
+	}
+
+	var msg testMessage
+	if err = codec.Unmarshal(data, &msg); err != nil {
+		FailWithError(t, "TestGRPCCodec", err)
+	} else if msg.Name != "gopher" {
+		FailWithError(t, "TestGRPCCodec", noMatch)
+	}
+
+	// Unmarshaling replaces a protected list's checksums as well.
+	tlvl.Protect()
+	if err = codec.Unmarshal(data[:0], tlvl); err != nil {
+		FailWithError(t, "TestGRPCCodec", err)
+	} else if err = tlvl.Verify(); err != nil {
+		FailWithError(t, "TestGRPCCodec", err)
+	}
+
+	if _, err = codec.Marshal("gopher"); err != ErrUnsupportedType {
+		FailWithError(t, "TestGRPCCodec",
+			fmt.Errorf("strings should not marshal"))
+	}
+}
@@ -0,0 +1,90 @@
+package tlv
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"sync"
+)
+
+// Type HashID identifies a hash or checksum algorithm by a single byte,
+// so the algorithm protecting some data can be recorded alongside it
+// and new algorithms added without changing any format. It is recorded
+// in PEM checksum headers, in signature records written by SignWith,
+// and in stream summaries.
+type HashID byte
+
+// The hash algorithms with assigned identifiers. CRC-32C and SHA-256 are
+// available by default; xxHash64 and BLAKE2b-512 must be provided with
+// RegisterHash, as their implementations are not in the standard library.
+const (
+	HashCRC32C  HashID = 1
+	HashXXH64   HashID = 2
+	HashSHA256  HashID = 3
+	HashBLAKE2b HashID = 4
+)
+
+// ErrUnknownHash is returned when a hash algorithm has not been
+// registered.
+var ErrUnknownHash = fmt.Errorf("unknown hash algorithm")
+
+type hashAlgorithm struct {
+	name string
+	new  func() hash.Hash
+}
+
+var hashes = struct {
+	sync.RWMutex
+	m map[HashID]hashAlgorithm
+}{m: map[HashID]hashAlgorithm{
+	HashCRC32C:  {"crc32c", func() hash.Hash { return crc32.New(castagnoli) }},
+	HashXXH64:   {"xxh64", nil},
+	HashSHA256:  {"sha256", sha256.New},
+	HashBLAKE2b: {"blake2b", nil},
+}}
+
+// RegisterHash makes a hash algorithm available under id and name,
+// replacing any algorithm registered before. It is used to provide the
+// implementations of HashXXH64 and HashBLAKE2b, and to add new
+// algorithms.
+func RegisterHash(id HashID, name string, new func() hash.Hash) {
+	hashes.Lock()
+	defer hashes.Unlock()
+	hashes.m[id] = hashAlgorithm{name, new}
+}
+
+// NewHash returns a new hash.Hash computing the algorithm identified by
+// id.
+func NewHash(id HashID) (hash.Hash, error) {
+	hashes.RLock()
+	defer hashes.RUnlock()
+	alg, ok := hashes.m[id]
+	if !ok || alg.new == nil {
+		return nil, ErrUnknownHash
+	}
+	return alg.new(), nil
+}
+
+// String returns the name of the algorithm identified by id.
+func (id HashID) String() string {
+	hashes.RLock()
+	defer hashes.RUnlock()
+	if alg, ok := hashes.m[id]; ok {
+		return alg.name
+	}
+	return fmt.Sprintf("hash-%d", byte(id))
+}
+
+// LookupHash returns the identifier of the algorithm registered under
+// name.
+func LookupHash(name string) (id HashID, ok bool) {
+	hashes.RLock()
+	defer hashes.RUnlock()
+	for id, alg := range hashes.m {
+		if alg.name == name {
+			return id, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,62 @@
+package tlv
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"testing"
+)
+
+func TestHashRegistry(t *testing.T) {
+	h, err := NewHash(HashCRC32C)
+	if err != nil {
+		FailWithError(t, "TestHashRegistry", err)
+	}
+	h.Write([]byte("123456789"))
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != "e3069283" {
+		FailWithError(t, "TestHashRegistry",
+			fmt.Errorf("bad CRC-32C %s", sum))
+	}
+
+	if _, err = NewHash(HashBLAKE2b); err != ErrUnknownHash {
+		FailWithError(t, "TestHashRegistry",
+			fmt.Errorf("BLAKE2b should need registering"))
+	} else if id, ok := LookupHash("sha256"); !ok || id != HashSHA256 {
+		FailWithError(t, "TestHashRegistry", noMatch)
+	} else if HashID(99).String() != "hash-99" {
+		FailWithError(t, "TestHashRegistry", noMatch)
+	}
+
+	// An algorithm added by a deployment can be selected by name.
+	RegisterHash(0x80, "sha512", sha512.New)
+	tlvl := New()
+	tlvl.Add(TagTest1, []byte("checked"))
+	buf := new(bytes.Buffer)
+	if err = WritePEM(buf, tlvl, map[string]string{PEMChecksumAlgorithm: "sha512"}); err != nil {
+		FailWithError(t, "TestHashRegistry", err)
+	}
+	block, _, err := DecodePEM(buf.Bytes())
+	if err != nil {
+		FailWithError(t, "TestHashRegistry", err)
+	} else if len(block.Headers[PEMChecksum]) != 128 {
+		FailWithError(t, "TestHashRegistry",
+			fmt.Errorf("checksum was not SHA-512"))
+	} else if block.Headers[PEMChecksumAlgorithm] != "128" {
+		FailWithError(t, "TestHashRegistry",
+			fmt.Errorf("algorithm recorded as %q", block.Headers[PEMChecksumAlgorithm]))
+	}
+
+	// Blocks identify the algorithm by HashID, not by name.
+	named := bytes.Replace(buf.Bytes(), []byte(PEMChecksumAlgorithm+": 128"),
+		[]byte(PEMChecksumAlgorithm+": sha512"), 1)
+	if _, _, err = DecodePEM(named); err != ErrUnknownHash {
+		FailWithError(t, "TestHashRegistry",
+			fmt.Errorf("expected ErrUnknownHash, got %v", err))
+	}
+
+	if err = WritePEM(buf, tlvl, map[string]string{PEMChecksumAlgorithm: "md4"}); err != ErrUnknownHash {
+		FailWithError(t, "TestHashRegistry",
+			fmt.Errorf("expected ErrUnknownHash, got %v", err))
+	}
+}
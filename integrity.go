@@ -0,0 +1,73 @@
+package tlv
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+)
+
+// ErrCorrupt is returned by Verify when a record no longer matches the
+// checksum taken when it was added to a protected TLVList.
+var ErrCorrupt = fmt.Errorf("TLV record failed integrity check")
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+func checksum(tlv TLV) uint32 {
+	var hdr [16]byte
+	binary.BigEndian.PutUint64(hdr[:], uint64(tlv.Tag()))
+	binary.BigEndian.PutUint64(hdr[8:], uint64(tlv.Length()))
+	crc := crc32.Update(0, castagnoli, hdr[:])
+	return crc32.Update(crc, castagnoli, tlv.Value())
+}
+
+// Protect turns on integrity checking for the TLVList. A CRC-32C
+// checksum is taken of every record in the list, and of every record
+// added to it later through Add or AddRecord. Verify can then be used to
+// detect records that have changed since, whether through a caller
+// modifying a slice returned by Value or through memory corruption. This
+// is intended for lists that are kept in memory for a long time. The
+// checksums are never written out, so unlike stored checksums and
+// signatures they do not take a HashID: CRC-32C is always used.
+func (recs *TLVList) Protect() {
+	if recs.sums != nil {
+		return
+	}
+	recs.sums = make([]uint32, 0, recs.Length())
+	for _, tlv := range recs.records {
+		recs.sums = append(recs.sums, checksum(tlv))
+	}
+}
+
+// protect takes the checksum of a record just added to the list, if it
+// is protected.
+func (recs *TLVList) protect(tlv TLV) {
+	if recs.sums != nil {
+		recs.sums = append(recs.sums, checksum(tlv))
+	}
+}
+
+// set replaces the record at index i, taking its checksum if the list
+// is protected.
+func (recs *TLVList) set(i int, tlv TLV) {
+	recs.records[i] = tlv
+	if recs.sums != nil {
+		recs.sums[i] = checksum(tlv)
+	}
+}
+
+// Verify checks every record in a protected TLVList against its
+// checksum, returning ErrCorrupt if any have changed. Verify always
+// succeeds on a list that is not protected.
+func (recs *TLVList) Verify() error {
+	if recs.sums == nil {
+		return nil
+	} else if len(recs.sums) != len(recs.records) {
+		return ErrCorrupt
+	}
+	for i, tlv := range recs.records {
+		if recs.sums[i] != checksum(tlv) {
+			return ErrCorrupt
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package tlv
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestProtect(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(TagTest1, []byte("foo bar"))
+	tlvl.Protect()
+	tlvl.Add(TagTest2, []byte("baz quux"))
+	tlvl.AddRecord(newTLV(TagTest3, []byte("gophers are everywhere!")))
+
+	if err := tlvl.Verify(); err != nil {
+		FailWithError(t, "TestProtect", err)
+	}
+
+	rec, err := tlvl.Get(TagTest2)
+	if err != nil {
+		FailWithError(t, "TestProtect", err)
+	}
+	rec.Value()[0] = 'B'
+	if err = tlvl.Verify(); err != ErrCorrupt {
+		FailWithError(t, "TestProtect",
+			fmt.Errorf("modified value should be detected"))
+	}
+
+	tlvl.Remove(TagTest2)
+	if err = tlvl.Verify(); err != nil {
+		FailWithError(t, "TestProtect", err)
+	} else if len(tlvl.sums) != 2 {
+		FailWithError(t, "TestProtect",
+			fmt.Errorf("checksums of removed records should be dropped"))
+	}
+
+	unprotected := New()
+	unprotected.Add(TagTest1, []byte("foo bar"))
+	if err = unprotected.Verify(); err != nil {
+		FailWithError(t, "TestProtect", err)
+	}
+}
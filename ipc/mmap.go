@@ -0,0 +1,30 @@
+//go:build unix
+
+package ipc
+
+import (
+	"os"
+	"syscall"
+)
+
+// MapFile maps size bytes of the file at path into memory for sharing
+// with other processes, creating and extending the file as needed. The
+// returned segment may be passed to New or Attach, and must be released
+// with Unmap.
+func MapFile(path string, size int) (seg []byte, err error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	if err = file.Truncate(int64(size)); err != nil {
+		return
+	}
+	return syscall.Mmap(int(file.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+}
+
+// Unmap releases a segment returned by MapFile.
+func Unmap(seg []byte) error {
+	return syscall.Munmap(seg)
+}
@@ -0,0 +1,48 @@
+//go:build unix
+
+package ipc
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+func TestMapFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ring")
+	seg, err := MapFile(path, 4096)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Unmap(seg)
+
+	producer, err := New(seg)
+	if err != nil {
+		t.Fatal(err)
+	} else if _, err = producer.Write(newRecord(7, "telemetry")); err != nil {
+		t.Fatal(err)
+	}
+
+	seg2, err := MapFile(path, 4096)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Unmap(seg2)
+
+	consumer, err := Attach(seg2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, _, err := consumer.Read()
+	if err != nil {
+		t.Fatal(err)
+	} else if rec.Tag() != 7 || !bytes.Equal(rec.Value(), []byte("telemetry")) {
+		t.Fatal("record did not match")
+	} else if producer.Len() != 0 {
+		t.Fatal("read not visible to producer")
+	}
+
+	if _, err = Attach(make([]byte, HeaderSize+64)); err != ErrSegment {
+		t.Fatalf("expected ErrSegment, got %v", err)
+	}
+}
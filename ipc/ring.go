@@ -0,0 +1,186 @@
+// Package ipc provides a single-producer, single-consumer ring of TLV
+// records in a shared memory segment, such as a memory-mapped file, for
+// low-latency communication between local processes.
+//
+// The segment begins with a header holding the ring's capacity, the
+// producer and consumer positions, and the next sequence number; the
+// rest of the segment holds the records. Positions only ever increase,
+// and are reduced modulo the capacity to find their place in the ring,
+// so records wrap around the end of the segment transparently. Each
+// record is stored as its 64-bit sequence number followed by the record
+// in the tlv.Standard format.
+package ipc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/gokyle/tlv"
+)
+
+const (
+	magic = "TLVRING1"
+
+	offCapacity = 8
+	offSequence = 16
+	offHead     = 64
+	offTail     = 128
+
+	// HeaderSize is the number of bytes at the start of a segment
+	// used for the ring's header. The producer and consumer positions
+	// are kept on separate cache lines.
+	HeaderSize = 192
+
+	frameHeader = 16
+)
+
+var (
+	// ErrFull is returned when there is not enough free space in
+	// the ring for a record. ErrEmpty is returned when there are no
+	// records to read.
+	ErrFull  = fmt.Errorf("ipc: ring is full")
+	ErrEmpty = fmt.Errorf("ipc: ring is empty")
+
+	// ErrSegment is returned when a segment is too small, is not
+	// suitably aligned, or does not hold a ring.
+	ErrSegment = fmt.Errorf("ipc: invalid ring segment")
+
+	// ErrCorrupt is returned when a record read from the ring is
+	// malformed.
+	ErrCorrupt = fmt.Errorf("ipc: corrupt ring record")
+)
+
+// Type Ring is one end of a ring in a shared memory segment. A ring must
+// have at most one producer, calling Write, and one consumer, calling
+// Read; each may be in a different process.
+type Ring struct {
+	seg  []byte
+	data []byte
+}
+
+type record struct {
+	tag   int
+	value []byte
+}
+
+func (rec record) Tag() int      { return rec.tag }
+func (rec record) Length() int   { return len(rec.value) }
+func (rec record) Value() []byte { return rec.value }
+
+func (ring *Ring) word(off int) *uint64 {
+	return (*uint64)(unsafe.Pointer(&ring.seg[off]))
+}
+
+func segment(seg []byte) (*Ring, error) {
+	if len(seg) <= HeaderSize+frameHeader || uintptr(unsafe.Pointer(&seg[0]))%8 != 0 {
+		return nil, ErrSegment
+	}
+	return &Ring{seg: seg, data: seg[HeaderSize:]}, nil
+}
+
+// New initialises a ring in seg, discarding anything it held, and
+// returns it. The segment must be 8-byte aligned, as memory-mapped
+// regions are, and the ring's capacity is len(seg) - HeaderSize.
+func New(seg []byte) (*Ring, error) {
+	ring, err := segment(seg)
+	if err != nil {
+		return nil, err
+	}
+
+	atomic.StoreUint64(ring.word(offHead), 0)
+	atomic.StoreUint64(ring.word(offTail), 0)
+	atomic.StoreUint64(ring.word(offSequence), 0)
+	binary.BigEndian.PutUint64(seg[offCapacity:], uint64(len(ring.data)))
+	copy(seg, magic)
+	return ring, nil
+}
+
+// Attach returns a ring previously initialised in seg with New, such as
+// by another process.
+func Attach(seg []byte) (*Ring, error) {
+	ring, err := segment(seg)
+	if err != nil {
+		return nil, err
+	} else if string(seg[:len(magic)]) != magic {
+		return nil, ErrSegment
+	} else if binary.BigEndian.Uint64(seg[offCapacity:]) != uint64(len(ring.data)) {
+		return nil, ErrSegment
+	}
+	return ring, nil
+}
+
+// Capacity returns the number of bytes available for records.
+func (ring *Ring) Capacity() int {
+	return len(ring.data)
+}
+
+// Len returns the number of bytes of records waiting to be read.
+func (ring *Ring) Len() int {
+	return int(atomic.LoadUint64(ring.word(offHead)) - atomic.LoadUint64(ring.word(offTail)))
+}
+
+// put copies p into the ring at pos, wrapping around the end.
+func (ring *Ring) put(pos uint64, p []byte) {
+	i := int(pos % uint64(len(ring.data)))
+	n := copy(ring.data[i:], p)
+	copy(ring.data, p[n:])
+}
+
+// get copies from the ring at pos into p, wrapping around the end.
+func (ring *Ring) get(pos uint64, p []byte) {
+	i := int(pos % uint64(len(ring.data)))
+	n := copy(p, ring.data[i:])
+	copy(p[n:], ring.data)
+}
+
+// Write appends a record to the ring, returning its sequence number. If
+// there is not enough free space for the record, ErrFull is returned
+// and the ring is unchanged.
+func (ring *Ring) Write(rec tlv.TLV) (seq uint64, err error) {
+	frame := make([]byte, frameHeader+rec.Length())
+	if _, err = tlv.EncodeAt(rec, frame, 8); err != nil {
+		return
+	}
+
+	head := atomic.LoadUint64(ring.word(offHead))
+	tail := atomic.LoadUint64(ring.word(offTail))
+	if uint64(len(frame)) > uint64(len(ring.data))-(head-tail) {
+		return 0, ErrFull
+	}
+
+	seq = atomic.LoadUint64(ring.word(offSequence))
+	binary.BigEndian.PutUint64(frame, seq)
+	ring.put(head, frame)
+	atomic.StoreUint64(ring.word(offSequence), seq+1)
+	atomic.StoreUint64(ring.word(offHead), head+uint64(len(frame)))
+	return seq, nil
+}
+
+// Read removes the oldest record from the ring, returning it with its
+// sequence number. If there are no records, ErrEmpty is returned.
+func (ring *Ring) Read() (rec tlv.TLV, seq uint64, err error) {
+	tail := atomic.LoadUint64(ring.word(offTail))
+	head := atomic.LoadUint64(ring.word(offHead))
+	if head == tail {
+		return nil, 0, ErrEmpty
+	} else if head-tail < frameHeader {
+		return nil, 0, ErrCorrupt
+	}
+
+	var hdr [frameHeader]byte
+	ring.get(tail, hdr[:])
+	seq = binary.BigEndian.Uint64(hdr[:])
+	tag, length, _, err := tlv.ParseHeader(hdr[8:])
+	if err != nil || uint64(length) > head-tail-frameHeader {
+		return nil, 0, ErrCorrupt
+	}
+
+	value := make([]byte, length)
+	ring.get(tail+frameHeader, value)
+	rec = record{tag, value}
+
+	atomic.StoreUint64(ring.word(offTail), tail+frameHeader+uint64(length))
+	return rec, seq, nil
+}
@@ -0,0 +1,50 @@
+package ipc
+
+import (
+	"testing"
+
+	"github.com/gokyle/tlv"
+)
+
+func newRecord(tag int, value string) tlv.TLV {
+	return record{tag, []byte(value)}
+}
+
+func TestRing(t *testing.T) {
+	ring, err := New(make([]byte, HeaderSize+64))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err = ring.Read(); err != ErrEmpty {
+		t.Fatalf("expected ErrEmpty, got %v", err)
+	}
+
+	// Each record takes 16 bytes of framing, so writing and reading
+	// 20-byte records repeatedly forces them to wrap around the end
+	// of the 64-byte ring.
+	for i := 0; i < 10; i++ {
+		rec := newRecord(i, "abcd")
+		if seq, err := ring.Write(rec); err != nil {
+			t.Fatal(err)
+		} else if seq != uint64(i) {
+			t.Fatalf("expected sequence %d, got %d", i, seq)
+		}
+
+		if i%2 == 0 {
+			continue
+		}
+		for j := i - 1; j <= i; j++ {
+			got, seq, err := ring.Read()
+			if err != nil {
+				t.Fatal(err)
+			} else if seq != uint64(j) || !tlv.Equals(got, newRecord(j, "abcd")) {
+				t.Fatalf("record %d did not match", j)
+			}
+		}
+	}
+
+	if _, err = ring.Write(newRecord(1, string(make([]byte, 49)))); err != ErrFull {
+		t.Fatalf("expected ErrFull, got %v", err)
+	}
+}
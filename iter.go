@@ -0,0 +1,36 @@
+//go:build go1.23
+
+package tlv
+
+import "iter"
+
+// All returns an iterator over the records in the TLVList, in order, for
+// use in a range loop:
+//
+//	for rec := range recs.All() {
+//		...
+//	}
+//
+// Records added while iterating are not visited.
+func (recs *TLVList) All() iter.Seq[TLV] {
+	return func(yield func(TLV) bool) {
+		for _, tlv := range recs.records {
+			if !yield(tlv) {
+				return
+			}
+		}
+	}
+}
+
+// ByTag returns an iterator over the records in the TLVList with the
+// given tag, in order. Unlike GetAll, it does not allocate a slice to
+// hold them.
+func (recs *TLVList) ByTag(tag int) iter.Seq[TLV] {
+	return func(yield func(TLV) bool) {
+		for _, tlv := range recs.records {
+			if tlv.Tag() == tag && !yield(tlv) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+//go:build go1.23
+
+package tlv
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestIterators(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(TagTest1, []byte("foo"))
+	tlvl.Add(TagTest2, []byte("bar"))
+	tlvl.Add(TagTest1, []byte("baz"))
+
+	var values []string
+	for rec := range tlvl.All() {
+		values = append(values, string(rec.Value()))
+	}
+	if fmt.Sprint(values) != "[foo bar baz]" {
+		FailWithError(t, "TestIterators", fmt.Errorf("All visited %v", values))
+	}
+
+	values = nil
+	for rec := range tlvl.ByTag(TagTest1) {
+		values = append(values, string(rec.Value()))
+		break
+	}
+	if fmt.Sprint(values) != "[foo]" {
+		FailWithError(t, "TestIterators", fmt.Errorf("ByTag visited %v", values))
+	}
+
+	n := 0
+	for range tlvl.ByTag(TagTest1) {
+		n++
+	}
+	if n != 2 {
+		FailWithError(t, "TestIterators", fmt.Errorf("ByTag visited %d records", n))
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		for rec := range tlvl.ByTag(TagTest2) {
+			_ = rec
+		}
+	})
+	if allocs != 0 {
+		FailWithError(t, "TestIterators", fmt.Errorf("ByTag allocated %v times", allocs))
+	}
+}
@@ -0,0 +1,40 @@
+package tlv
+
+// Join correlates two collections of lists, such as the entries exported
+// by two different systems, on the value of their keyTag records. For
+// each pair of lists from a and b whose first keyTag records hold equal
+// values, Join produces a merged list holding the records of the list
+// from a, followed by the records of the list from b other than its key
+// records. Lists without a key record are skipped.
+//
+// This is an inner join: lists with no counterpart are not included.
+// Results are ordered by their position in a, and then in b.
+func Join(a, b []*TLVList, keyTag int) []*TLVList {
+	index := make(map[string][]*TLVList)
+	for _, recs := range b {
+		if key, err := recs.Get(keyTag); err == nil {
+			k := string(key.Value())
+			index[k] = append(index[k], recs)
+		}
+	}
+
+	var joined []*TLVList
+	for _, left := range a {
+		key, err := left.Get(keyTag)
+		if err != nil {
+			continue
+		}
+
+		for _, right := range index[string(key.Value())] {
+			merged := New()
+			merged.records = append(merged.records, left.records...)
+			for _, tlv := range right.records {
+				if tlv.Tag() != keyTag {
+					merged.records = append(merged.records, tlv)
+				}
+			}
+			joined = append(joined, merged)
+		}
+	}
+	return joined
+}
@@ -0,0 +1,52 @@
+package tlv
+
+import (
+	"fmt"
+	"testing"
+)
+
+func joinTestEntry(key string, tag int, value string) *TLVList {
+	recs := New()
+	recs.Add(TagTest1, []byte(key))
+	recs.Add(tag, []byte(value))
+	return recs
+}
+
+func TestJoin(t *testing.T) {
+	a := []*TLVList{
+		joinTestEntry("alice", TagTest2, "alice@example.com"),
+		joinTestEntry("bob", TagTest2, "bob@example.com"),
+		joinTestEntry("carol", TagTest2, "carol@example.com"),
+		New(),
+	}
+	b := []*TLVList{
+		joinTestEntry("carol", TagTest3, "ops"),
+		joinTestEntry("alice", TagTest3, "dev"),
+		joinTestEntry("dave", TagTest3, "sales"),
+		joinTestEntry("alice", TagTest3, "ops"),
+	}
+
+	joined := Join(a, b, TagTest1)
+	if len(joined) != 3 {
+		FailWithError(t, "TestJoin",
+			fmt.Errorf("expected 3 joined lists, got %d", len(joined)))
+	}
+
+	expected := []struct{ key, email, team string }{
+		{"alice", "alice@example.com", "dev"},
+		{"alice", "alice@example.com", "ops"},
+		{"carol", "carol@example.com", "ops"},
+	}
+	for i, recs := range joined {
+		checkTags(t, "TestJoin", recs, TagTest1, TagTest2, TagTest3)
+		key, _ := recs.Get(TagTest1)
+		email, _ := recs.Get(TagTest2)
+		team, _ := recs.Get(TagTest3)
+		if string(key.Value()) != expected[i].key ||
+			string(email.Value()) != expected[i].email ||
+			string(team.Value()) != expected[i].team {
+			FailWithError(t, "TestJoin",
+				fmt.Errorf("joined list %d is wrong", i))
+		}
+	}
+}
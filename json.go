@@ -0,0 +1,117 @@
+package tlv
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// ErrJSON is returned when a JSON record holds neither a base64 nor a
+// hex value, or both.
+var ErrJSON = fmt.Errorf("invalid JSON TLV record")
+
+// jsonRecord is the JSON form of a record. Exactly one of Value, holding
+// standard base64, and Hex is set. Name and Desc are informational and
+// are ignored when decoding.
+type jsonRecord struct {
+	Tag   int     `json:"tag"`
+	Name  string  `json:"name,omitempty"`
+	Desc  string  `json:"description,omitempty"`
+	Value *string `json:"value,omitempty"`
+	Hex   *string `json:"hex,omitempty"`
+}
+
+func toJSONRecord(tlv TLV, useHex bool) jsonRecord {
+	jr := jsonRecord{Tag: tlv.Tag()}
+	if useHex {
+		s := hex.EncodeToString(tlv.Value())
+		jr.Hex = &s
+	} else {
+		s := base64.StdEncoding.EncodeToString(tlv.Value())
+		jr.Value = &s
+	}
+	return jr
+}
+
+func (jr jsonRecord) record() (TLV, error) {
+	var value []byte
+	var err error
+	switch {
+	case (jr.Value == nil) == (jr.Hex == nil):
+		return nil, ErrJSON
+	case jr.Value != nil:
+		value, err = base64.StdEncoding.DecodeString(*jr.Value)
+	default:
+		value, err = hex.DecodeString(*jr.Hex)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newTLV(jr.Tag, value), nil
+}
+
+// MarshalJSON encodes a record as a JSON object holding its tag as a
+// number and its value as base64.
+func (t *record) MarshalJSON() ([]byte, error) {
+	return json.Marshal(toJSONRecord(t, false))
+}
+
+func (recs *TLVList) marshalJSON(reg *Registry, useHex bool) ([]byte, error) {
+	out := make([]jsonRecord, 0, recs.Length())
+	for _, tlv := range recs.records {
+		jr := toJSONRecord(tlv, useHex)
+		if reg != nil {
+			jr.Name, _ = reg.Name(jr.Tag)
+			jr.Desc, _ = reg.Description(jr.Tag)
+		}
+		out = append(out, jr)
+	}
+	return json.Marshal(out)
+}
+
+// MarshalJSON encodes the TLVList as a JSON array of records in list
+// order, each an object holding the tag as a number and the value as
+// base64, such as {"tag":1,"value":"aGk="}.
+func (recs *TLVList) MarshalJSON() ([]byte, error) {
+	return recs.marshalJSON(nil, false)
+}
+
+// MarshalJSONHex is like MarshalJSON, but writes each value as hex under
+// the "hex" key, such as {"tag":1,"hex":"6869"}, which is easier to read
+// in debugging dumps.
+func (recs *TLVList) MarshalJSONHex() ([]byte, error) {
+	return recs.marshalJSON(nil, true)
+}
+
+// EncodeJSON is TLVList.MarshalJSON, or MarshalJSONHex if useHex is
+// set, with each record also carrying the name and description reg has
+// for its tag, such as
+//
+//	{"tag":24365,"name":"language","description":"Language Preference","hex":"656e"}
+//
+// UnmarshalJSON ignores the extra keys.
+func (reg *Registry) EncodeJSON(recs *TLVList, useHex bool) ([]byte, error) {
+	return recs.marshalJSON(reg, useHex)
+}
+
+// UnmarshalJSON replaces the contents of the TLVList with the records in
+// a JSON array written by MarshalJSON or MarshalJSONHex. Each record may
+// use either form.
+func (recs *TLVList) UnmarshalJSON(data []byte) error {
+	var in []jsonRecord
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	decoded := New()
+	for _, jr := range in {
+		tlv, err := jr.record()
+		if err != nil {
+			return err
+		}
+		decoded.AddRecord(tlv)
+	}
+	*recs = *decoded
+	return nil
+}
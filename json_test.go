@@ -0,0 +1,54 @@
+package tlv
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(TagTest2, []byte("hi"))
+	tlvl.Add(TagTest3, nil)
+	tlvl.Add(TagTest2, []byte{0xff})
+
+	out, err := json.Marshal(tlvl)
+	if err != nil {
+		FailWithError(t, "TestJSON", err)
+	} else if string(out) != `[{"tag":1,"value":"aGk="},{"tag":2,"value":""},{"tag":1,"value":"/w=="}]` {
+		FailWithError(t, "TestJSON",
+			fmt.Errorf("bad JSON %s", out))
+	}
+
+	hexOut, err := tlvl.MarshalJSONHex()
+	if err != nil {
+		FailWithError(t, "TestJSON", err)
+	} else if string(hexOut) != `[{"tag":1,"hex":"6869"},{"tag":2,"hex":""},{"tag":1,"hex":"ff"}]` {
+		FailWithError(t, "TestJSON",
+			fmt.Errorf("bad JSON %s", hexOut))
+	}
+
+	for _, in := range [][]byte{out, hexOut} {
+		rtlvl := New()
+		if err = json.Unmarshal(in, rtlvl); err != nil {
+			FailWithError(t, "TestJSON", err)
+		}
+		checkTags(t, "TestJSON", rtlvl, TagTest2, TagTest3, TagTest2)
+		if recs := rtlvl.GetAll(TagTest2); string(recs[0].Value()) != "hi" || recs[1].Value()[0] != 0xff {
+			FailWithError(t, "TestJSON", noMatch)
+		}
+	}
+
+	rec, _ := tlvl.Get(TagTest2)
+	if out, err = json.Marshal(rec); err != nil {
+		FailWithError(t, "TestJSON", err)
+	} else if string(out) != `{"tag":1,"value":"aGk="}` {
+		FailWithError(t, "TestJSON",
+			fmt.Errorf("bad JSON %s", out))
+	}
+
+	if err = json.Unmarshal([]byte(`[{"tag":1}]`), New()); err != ErrJSON {
+		FailWithError(t, "TestJSON",
+			fmt.Errorf("record without a value should fail"))
+	}
+}
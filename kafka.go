@@ -0,0 +1,43 @@
+package tlv
+
+// Type KafkaHeader is a Kafka record header, consisting of a string key
+// and a byte value. It mirrors the header types used by the common Kafka
+// clients, so converting between them only requires copying fields.
+type KafkaHeader struct {
+	Key   string
+	Value []byte
+}
+
+// KafkaHeaders converts the TLVList to a slice of Kafka record headers,
+// using reg to name each tag. Headers are produced in list order, and
+// duplicate tags become repeated headers. If a record's tag has not been
+// registered, ErrUnknownTag is returned.
+func (recs *TLVList) KafkaHeaders(reg *Registry) (hdrs []KafkaHeader, err error) {
+	hdrs = make([]KafkaHeader, 0, recs.Length())
+	for _, tlv := range recs.records {
+		name, ok := reg.Name(tlv.Tag())
+		if !ok {
+			return nil, ErrUnknownTag
+		}
+		value := make([]byte, tlv.Length())
+		copy(value, tlv.Value())
+		hdrs = append(hdrs, KafkaHeader{name, value})
+	}
+	return hdrs, nil
+}
+
+// FromKafkaHeaders builds a TLVList from a slice of Kafka record headers,
+// using reg to map header keys back to tags. Kafka messages commonly
+// carry headers added by other producers and middleware, so headers whose
+// keys have not been registered are skipped.
+func FromKafkaHeaders(hdrs []KafkaHeader, reg *Registry) *TLVList {
+	recs := New()
+	for _, hdr := range hdrs {
+		tag, ok := reg.Lookup(hdr.Key)
+		if !ok {
+			continue
+		}
+		recs.Add(tag, hdr.Value)
+	}
+	return recs
+}
@@ -0,0 +1,42 @@
+package tlv
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestKafkaHeaders(t *testing.T) {
+	reg := testRegistry()
+	tlvl := New()
+	tlvl.Add(TagTest1, []byte("foo bar"))
+	tlvl.Add(TagTest2, []byte("baz quux"))
+	tlvl.Add(TagTest1, []byte("goodbye, cruel world"))
+
+	hdrs, err := tlvl.KafkaHeaders(reg)
+	if err != nil {
+		FailWithError(t, "TestKafkaHeaders", err)
+	} else if len(hdrs) != 3 {
+		FailWithError(t, "TestKafkaHeaders",
+			fmt.Errorf("expected 3 headers, got %d", len(hdrs)))
+	} else if hdrs[2].Key != "test-one" {
+		FailWithError(t, "TestKafkaHeaders",
+			fmt.Errorf("bad header key %s", hdrs[2].Key))
+	}
+
+	hdrs = append(hdrs, KafkaHeader{"traceparent", []byte("00-01")})
+	rtlvl := FromKafkaHeaders(hdrs, reg)
+	if rtlvl.Length() != 3 {
+		FailWithError(t, "TestKafkaHeaders",
+			fmt.Errorf("expected 3 records, got %d", rtlvl.Length()))
+	} else if tlvs := rtlvl.GetAll(TagTest1); len(tlvs) != 2 {
+		FailWithError(t, "TestKafkaHeaders", noMatch)
+	} else if string(tlvs[1].Value()) != "goodbye, cruel world" {
+		FailWithError(t, "TestKafkaHeaders", noMatch)
+	}
+
+	tlvl.Add(TagTest6, []byte("unnamed"))
+	if _, err = tlvl.KafkaHeaders(reg); err != ErrUnknownTag {
+		FailWithError(t, "TestKafkaHeaders",
+			fmt.Errorf("unregistered tag should fail"))
+	}
+}
@@ -0,0 +1,51 @@
+package tlv
+
+import "fmt"
+
+// ErrQueryLimit is returned when a traversal of nested records exceeds
+// its QueryLimits.
+var ErrQueryLimit = fmt.Errorf("nested TLV query limit exceeded")
+
+// Type QueryLimits bounds the work done when descending into nested
+// records, so that queries over attacker-supplied constructed data
+// cannot be used to make a server burn CPU and memory: since every
+// level of nesting is decoded again, deeply nested data costs far more
+// to query than its size suggests. A zero field means no limit.
+type QueryLimits struct {
+	// MaxVisited is the largest number of records that may be
+	// decoded or examined by a single query.
+	MaxVisited int
+
+	// MaxResult is the largest number of records a single query may
+	// return.
+	MaxResult int
+}
+
+// DefaultQueryLimits are conservative limits for queries over untrusted
+// data.
+var DefaultQueryLimits = QueryLimits{MaxVisited: 1 << 16, MaxResult: 1 << 12}
+
+// queryBudget tracks a single query's use of its limits.
+type queryBudget struct {
+	limits  QueryLimits
+	visited int
+	results int
+}
+
+// visit accounts for n more records being examined.
+func (b *queryBudget) visit(n int) error {
+	b.visited += n
+	if b.limits.MaxVisited > 0 && b.visited > b.limits.MaxVisited {
+		return ErrQueryLimit
+	}
+	return nil
+}
+
+// result accounts for one more record being returned.
+func (b *queryBudget) result() error {
+	b.results++
+	if b.limits.MaxResult > 0 && b.results > b.limits.MaxResult {
+		return ErrQueryLimit
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+// Package lite is a minimal encoder and decoder for the tlv package's
+// Standard wire format, for firmware and other constrained targets built
+// with TinyGo. It depends only on errors and io: there is no reflection,
+// no record lists, no fmt, and decoding does not allocate, reading
+// values into a buffer supplied by the caller.
+//
+// Records written by this package can be read by the tlv package, and
+// vice versa: each is a 32-bit big-endian tag followed by a 32-bit
+// big-endian length and the value. Tags are signed, as in the tlv
+// package, and lengths must not be negative.
+package lite
+
+import (
+	"errors"
+	"io"
+)
+
+// HeaderSize is the size of a record header.
+const HeaderSize = 8
+
+// ErrShortBuffer is returned when a value does not fit in the buffer it
+// is read into; ErrLength is returned when a record's length is negative
+// or its value is truncated.
+var (
+	ErrShortBuffer = errors.New("lite: buffer too small for value")
+	ErrLength      = errors.New("lite: invalid record length")
+)
+
+func putUint32(b []byte, v uint32) {
+	b[0], b[1], b[2], b[3] = byte(v>>24), byte(v>>16), byte(v>>8), byte(v)
+}
+
+func getUint32(b []byte) uint32 {
+	return uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
+}
+
+// Append appends a record to buf and returns the extended buffer.
+func Append(buf []byte, tag int32, value []byte) []byte {
+	var hdr [HeaderSize]byte
+	putUint32(hdr[:], uint32(tag))
+	putUint32(hdr[4:], uint32(len(value)))
+	buf = append(buf, hdr[:]...)
+	return append(buf, value...)
+}
+
+// Parse parses the record at the start of buf, returning its tag, its
+// value, which aliases buf, and the rest of buf after the record. If buf
+// is empty, io.EOF is returned.
+func Parse(buf []byte) (tag int32, value, rest []byte, err error) {
+	if len(buf) == 0 {
+		return 0, nil, nil, io.EOF
+	} else if len(buf) < HeaderSize {
+		return 0, nil, nil, io.ErrUnexpectedEOF
+	}
+
+	tag = int32(getUint32(buf))
+	length := int32(getUint32(buf[4:]))
+	if length < 0 || int64(length) > int64(len(buf)-HeaderSize) {
+		return 0, nil, nil, ErrLength
+	}
+	end := HeaderSize + int(length)
+	return tag, buf[HeaderSize:end], buf[end:], nil
+}
+
+// Type Encoder writes records to an io.Writer.
+type Encoder struct {
+	w   io.Writer
+	hdr [HeaderSize]byte
+}
+
+// NewEncoder returns an Encoder writing to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes a single record.
+func (enc *Encoder) Encode(tag int32, value []byte) error {
+	putUint32(enc.hdr[:], uint32(tag))
+	putUint32(enc.hdr[4:], uint32(len(value)))
+	if _, err := enc.w.Write(enc.hdr[:]); err != nil {
+		return err
+	}
+	_, err := enc.w.Write(value)
+	return err
+}
+
+// Type Decoder reads records from an io.Reader, never reading past the
+// end of the record being decoded.
+type Decoder struct {
+	r   io.Reader
+	hdr [HeaderSize]byte
+}
+
+// NewDecoder returns a Decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// Decode reads the next record, copying its value into buf and returning
+// the tag and the value's length. At the end of the stream, io.EOF is
+// returned. If the value is longer than buf, ErrShortBuffer is returned
+// and the value is skipped, so decoding can continue with the next
+// record.
+func (dec *Decoder) Decode(buf []byte) (tag int32, n int, err error) {
+	if _, err = io.ReadFull(dec.r, dec.hdr[:]); err != nil {
+		return
+	}
+	tag = int32(getUint32(dec.hdr[:]))
+	length := int32(getUint32(dec.hdr[4:]))
+	if length < 0 {
+		return 0, 0, ErrLength
+	}
+
+	if int(length) > len(buf) {
+		if err = dec.skip(int(length)); err != nil {
+			return 0, 0, err
+		}
+		return tag, int(length), ErrShortBuffer
+	}
+	if _, err = io.ReadFull(dec.r, buf[:length]); err != nil {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	return tag, int(length), nil
+}
+
+// skip discards n bytes of input, using the header buffer as scratch
+// space so that nothing is allocated.
+func (dec *Decoder) skip(n int) error {
+	for n > 0 {
+		m := n
+		if m > len(dec.hdr) {
+			m = len(dec.hdr)
+		}
+		if _, err := io.ReadFull(dec.r, dec.hdr[:m]); err != nil {
+			return io.ErrUnexpectedEOF
+		}
+		n -= m
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package lite
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/gokyle/tlv"
+)
+
+func TestInterop(t *testing.T) {
+	recs := tlv.New()
+	recs.Add(1, []byte("foo bar"))
+	recs.Add(-2, nil)
+	recs.Add(3, []byte("gophers are everywhere!"))
+
+	buf := new(bytes.Buffer)
+	if err := recs.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	raw := buf.Bytes()
+
+	var built []byte
+	built = Append(built, 1, []byte("foo bar"))
+	built = Append(built, -2, nil)
+	built = Append(built, 3, []byte("gophers are everywhere!"))
+	if !bytes.Equal(built, raw) {
+		t.Fatal("Append does not match the tlv package")
+	}
+
+	rest := raw
+	for _, want := range []int32{1, -2, 3} {
+		var tag int32
+		var err error
+		if tag, _, rest, err = Parse(rest); err != nil {
+			t.Fatal(err)
+		} else if tag != want {
+			t.Fatalf("parsed tag %d, expected %d", tag, want)
+		}
+	}
+	if _, _, _, err := Parse(rest); err != io.EOF {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	out := new(bytes.Buffer)
+	enc := NewEncoder(out)
+	enc.Encode(1, []byte("a value that is too long"))
+	enc.Encode(2, []byte("fits"))
+
+	dec := NewDecoder(bytes.NewReader(out.Bytes()))
+	var buf [8]byte
+	if _, _, err := dec.Decode(buf[:]); err != ErrShortBuffer {
+		t.Fatalf("expected ErrShortBuffer, got %v", err)
+	}
+	tag, n, err := dec.Decode(buf[:])
+	if err != nil {
+		t.Fatal(err)
+	} else if tag != 2 || string(buf[:n]) != "fits" {
+		t.Fatalf("decoded %d %q", tag, buf[:n])
+	}
+	if _, _, err = dec.Decode(buf[:]); err != io.EOF {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+
+	rd := bytes.NewReader(nil)
+	dec = NewDecoder(rd)
+	allocs := testing.AllocsPerRun(100, func() {
+		rd.Reset(out.Bytes())
+		dec.Decode(buf[:])
+		dec.Decode(buf[:])
+	})
+	if allocs > 0 {
+		t.Fatalf("decoding allocated %v times", allocs)
+	}
+}
@@ -0,0 +1,121 @@
+package tlv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// LLDP is the LLDP TLV format (IEEE 802.1AB): a 16-bit big-endian header
+// holding a 7-bit type and a 9-bit length. The End of LLDPDU TLV is read
+// as the end of the stream. Tags must be between 1 and 127, and values
+// may be at most 511 bytes long.
+var LLDP Format = lldpFormat{}
+
+// The mandatory LLDP TLV types, which must appear at the start of every
+// LLDPDU in this order, and the End of LLDPDU TLV that terminates it.
+const (
+	LLDPEnd       = 0
+	LLDPChassisID = 1
+	LLDPPortID    = 2
+	LLDPTTL       = 3
+)
+
+// ErrLLDPDU is returned when an LLDPDU is missing one of its mandatory
+// TLVs.
+var ErrLLDPDU = fmt.Errorf("malformed LLDPDU")
+
+type lldpFormat struct{}
+
+func (lldpFormat) ReadHeader(r io.Reader) (tag, length int, err error) {
+	var hdr [2]byte
+	if _, err = io.ReadFull(r, hdr[:]); err != nil {
+		return
+	}
+
+	packed := binary.BigEndian.Uint16(hdr[:])
+	tag, length = int(packed>>9), int(packed&0x1ff)
+	if tag == LLDPEnd {
+		if length != 0 {
+			return 0, 0, ErrLengthRange
+		}
+		return 0, 0, io.EOF
+	}
+	return
+}
+
+func (lldpFormat) WriteHeader(w io.Writer, tag, length int) error {
+	if tag <= LLDPEnd || tag > 0x7f {
+		return ErrTagRange
+	} else if length < 0 || length > 0x1ff {
+		return ErrLengthRange
+	}
+
+	var hdr [2]byte
+	binary.BigEndian.PutUint16(hdr[:], uint16(tag<<9|length))
+	_, err := w.Write(hdr[:])
+	return err
+}
+
+// DecodeLLDPDU decodes an LLDPDU, checking that it begins with the
+// Chassis ID, Port ID, and Time To Live TLVs and is terminated by an End
+// of LLDPDU TLV. Any bytes following the End of LLDPDU TLV, such as
+// frame padding, are ignored.
+func DecodeLLDPDU(data []byte) (*TLVList, error) {
+	r := bytes.NewReader(data)
+	recs := New()
+	for {
+		remaining := r.Len()
+		tag, length, err := LLDP.ReadHeader(r)
+		if err == io.EOF {
+			if remaining == 0 {
+				return nil, ErrLLDPDU
+			}
+			break
+		} else if err != nil {
+			return nil, ErrTLVRead
+		}
+
+		value := make([]byte, length)
+		if _, err = io.ReadFull(r, value); err != nil {
+			return nil, ErrTLVRead
+		}
+		recs.Add(tag, value)
+	}
+
+	if !recs.hasPrefix(LLDPChassisID, LLDPPortID, LLDPTTL) {
+		return nil, ErrLLDPDU
+	}
+	return recs, nil
+}
+
+func (recs *TLVList) hasPrefix(tags ...int) bool {
+	if len(tags) > len(recs.records) {
+		return false
+	}
+	for i, tag := range tags {
+		if recs.records[i].Tag() != tag {
+			return false
+		}
+	}
+	return true
+}
+
+// EncodeLLDPDU encodes recs as an LLDPDU, terminated by an End of LLDPDU
+// TLV. The list must begin with the Chassis ID, Port ID, and Time To
+// Live TLVs.
+func EncodeLLDPDU(recs *TLVList) ([]byte, error) {
+	if !recs.hasPrefix(LLDPChassisID, LLDPPortID, LLDPTTL) {
+		return nil, ErrLLDPDU
+	}
+
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+	enc.Format = LLDP
+	if err := enc.EncodeList(recs); err != nil {
+		return nil, err
+	}
+	buf.Write([]byte{0, 0})
+	return buf.Bytes(), nil
+}
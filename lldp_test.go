@@ -0,0 +1,53 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// An LLDPDU with a MAC address chassis ID, an interface name port ID, a
+// TTL of 120 seconds and a system name, followed by frame padding.
+var lldpdu = []byte{
+	0x02, 0x07, 0x04, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55,
+	0x04, 0x05, 0x05, 'e', 't', 'h', '0',
+	0x06, 0x02, 0x00, 0x78,
+	0x0a, 0x06, 'g', 'o', 'p', 'h', 'e', 'r',
+	0x00, 0x00,
+	0x00, 0x00, 0x00,
+}
+
+func TestLLDP(t *testing.T) {
+	du, err := DecodeLLDPDU(lldpdu)
+	if err != nil {
+		FailWithError(t, "TestLLDP", err)
+	}
+	checkTags(t, "TestLLDP", du, LLDPChassisID, LLDPPortID, LLDPTTL, 5)
+	if name, err := du.Get(5); err != nil {
+		FailWithError(t, "TestLLDP", err)
+	} else if string(name.Value()) != "gopher" {
+		FailWithError(t, "TestLLDP", noMatch)
+	}
+
+	raw, err := EncodeLLDPDU(du)
+	if err != nil {
+		FailWithError(t, "TestLLDP", err)
+	} else if !bytes.Equal(raw, lldpdu[:len(lldpdu)-3]) {
+		FailWithError(t, "TestLLDP",
+			fmt.Errorf("LLDPDU did not round-trip"))
+	}
+
+	if _, err = DecodeLLDPDU(lldpdu[:28]); err != ErrLLDPDU {
+		FailWithError(t, "TestLLDP",
+			fmt.Errorf("missing End of LLDPDU should fail"))
+	} else if _, err = DecodeLLDPDU(lldpdu[9:]); err != ErrLLDPDU {
+		FailWithError(t, "TestLLDP",
+			fmt.Errorf("missing chassis ID should fail"))
+	}
+
+	du.Remove(LLDPTTL)
+	if _, err = EncodeLLDPDU(du); err != ErrLLDPDU {
+		FailWithError(t, "TestLLDP",
+			fmt.Errorf("missing TTL should fail"))
+	}
+}
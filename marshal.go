@@ -0,0 +1,466 @@
+package tlv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupportedType is returned when a value that cannot be converted
+// to or from a TLVList is marshaled or unmarshaled.
+var ErrUnsupportedType = fmt.Errorf("type does not support TLV marshaling")
+
+// Type Marshaler is implemented by types that can represent themselves
+// as a TLVList.
+type Marshaler interface {
+	MarshalTLV() (*TLVList, error)
+}
+
+// Type Unmarshaler is implemented by types that can populate themselves
+// from a TLVList.
+type Unmarshaler interface {
+	UnmarshalTLV(recs *TLVList) error
+}
+
+// decodeBytes decodes a complete TLVList held in memory. No encoded
+// value can be longer than the input itself, so the Decoder is limited
+// accordingly to avoid large allocations driven by corrupt or hostile
+// headers. Compressed records may legitimately inflate past the input
+// size, and are held to the default decompression limit instead.
+func decodeBytes(data []byte) (*TLVList, error) {
+	dec := NewDecoder(bytes.NewReader(data))
+	dec.MaxLength = len(data)
+	dec.MaxDecompressed = defaultMaxDecompressed
+	return dec.DecodeList()
+}
+
+// ErrStructTag is returned when a struct field's tlv tag is malformed.
+var ErrStructTag = fmt.Errorf("invalid tlv struct tag")
+
+// ErrValueSize is returned when a record's value is the wrong size for
+// the struct field it is unmarshaled into.
+var ErrValueSize = fmt.Errorf("TLV value has the wrong size for field")
+
+// Type FieldError reports the struct field, and its TLV tag, that
+// could not be marshaled or unmarshaled.
+type FieldError struct {
+	Struct string
+	Field  string
+	Tag    int
+	Err    error
+}
+
+// Method Error describes the failure, naming the field and tag.
+func (err *FieldError) Error() string {
+	return fmt.Sprintf("tlv: field %s.%s (tag %#x): %v", err.Struct,
+		err.Field, err.Tag, err.Err)
+}
+
+// Method Unwrap returns the underlying error.
+func (err *FieldError) Unwrap() error {
+	return err.Err
+}
+
+// structField describes a struct field carried in a TLV record.
+type structField struct {
+	index     int
+	name      string
+	tag       int
+	omitEmpty bool
+}
+
+var listType = reflect.TypeOf(TLVList{})
+
+// structFields returns the fields of t that have a tlv struct tag.
+// Unexported fields and fields tagged "-" are skipped.
+func structFields(t reflect.Type) ([]structField, error) {
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		spec, ok := f.Tag.Lookup("tlv")
+		if !ok || spec == "-" || f.PkgPath != "" {
+			continue
+		}
+
+		parts := strings.Split(spec, ",")
+		tag, err := strconv.ParseInt(parts[0], 0, 32)
+		if err != nil {
+			return nil, &FieldError{t.Name(), f.Name, -1, ErrStructTag}
+		}
+		field := structField{index: i, name: f.Name, tag: int(tag)}
+		for _, opt := range parts[1:] {
+			if opt != "omitempty" {
+				return nil, &FieldError{t.Name(), f.Name, field.tag, ErrStructTag}
+			}
+			field.omitEmpty = true
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// Marshal encodes v, a struct or a pointer to one, in the Standard
+// format; see MarshalList.
+func Marshal(v interface{}) ([]byte, error) {
+	recs, err := MarshalList(v)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	if err = recs.Write(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// MarshalList converts v, a struct or a pointer to one, to a TLVList,
+// with a record for each field carrying a struct tag such as `tlv:"3"`
+// or `tlv:"0x5f2d,omitempty"`; other fields are ignored. Values are
+// encoded as follows:
+//
+//   - integers as big-endian numbers the size of their type, with int,
+//     uint and uintptr taking eight bytes on every platform;
+//   - floats as big-endian IEEE 754 numbers;
+//   - bools as a single byte, 0 or 1;
+//   - strings, byte slices and byte arrays as their bytes;
+//   - structs, Marshalers and TLVLists as Constructed records;
+//   - other slices and arrays as one record per element;
+//   - pointers as the value they point to, or no record if nil.
+//
+// With omitempty, a field holding its zero value is left out. If v is a
+// Marshaler or a *TLVList, it is used as is.
+func MarshalList(v interface{}) (*TLVList, error) {
+	switch v := v.(type) {
+	case *TLVList:
+		return v, nil
+	case Marshaler:
+		return v.MarshalTLV()
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, ErrUnsupportedType
+	}
+	return marshalStruct(rv)
+}
+
+// marshalStruct converts a struct value to a TLVList.
+func marshalStruct(rv reflect.Value) (*TLVList, error) {
+	fields, err := structFields(rv.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	recs := New()
+	for _, field := range fields {
+		fv := rv.Field(field.index)
+		if field.omitEmpty && fv.IsZero() {
+			continue
+		}
+		if err = marshalField(recs, field.tag, fv); err != nil {
+			if _, ok := err.(*FieldError); !ok {
+				err = &FieldError{rv.Type().Name(), field.name, field.tag, err}
+			}
+			return nil, err
+		}
+	}
+	return recs, nil
+}
+
+// marshalField adds the records carrying fv to recs.
+func marshalField(recs *TLVList, tag int, fv reflect.Value) error {
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return nil
+		}
+		fv = fv.Elem()
+	}
+
+	if fv.CanInterface() {
+		var m interface{} = fv.Interface()
+		if fv.CanAddr() {
+			m = fv.Addr().Interface()
+		}
+		if m, ok := m.(Marshaler); ok {
+			children, err := m.MarshalTLV()
+			if err != nil {
+				return err
+			}
+			recs.AddList(tag, children)
+			return nil
+		}
+	}
+
+	switch fv.Kind() {
+	case reflect.Bool:
+		var b byte
+		if fv.Bool() {
+			b = 1
+		}
+		recs.Add(tag, []byte{b})
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		recs.Add(tag, putUint(uint64(fv.Int()), intSize(fv.Kind())))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		recs.Add(tag, putUint(fv.Uint(), intSize(fv.Kind())))
+	case reflect.Float32:
+		recs.Add(tag, putUint(uint64(math.Float32bits(float32(fv.Float()))), 4))
+	case reflect.Float64:
+		recs.Add(tag, putUint(math.Float64bits(fv.Float()), 8))
+	case reflect.String:
+		recs.Add(tag, []byte(fv.String()))
+	case reflect.Slice, reflect.Array:
+		if fv.Type().Elem().Kind() == reflect.Uint8 {
+			value := make([]byte, fv.Len())
+			reflect.Copy(reflect.ValueOf(value), fv)
+			recs.Add(tag, value)
+			return nil
+		}
+		for i := 0; i < fv.Len(); i++ {
+			if err := marshalField(recs, tag, fv.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		if fv.Type() == listType {
+			list := fv.Interface().(TLVList)
+			recs.AddList(tag, &list)
+			return nil
+		}
+		children, err := marshalStruct(fv)
+		if err != nil {
+			return err
+		}
+		recs.AddList(tag, children)
+	default:
+		return ErrUnsupportedType
+	}
+	return nil
+}
+
+// intSize returns the number of bytes an integer of kind k is encoded
+// in. Int, uint and uintptr vary in size between platforms, so they
+// always take eight bytes, to decode the same everywhere.
+func intSize(k reflect.Kind) int {
+	switch k {
+	case reflect.Int8, reflect.Uint8:
+		return 1
+	case reflect.Int16, reflect.Uint16:
+		return 2
+	case reflect.Int32, reflect.Uint32:
+		return 4
+	}
+	return 8
+}
+
+// putUint returns v as a big-endian number of size bytes.
+func putUint(v uint64, size int) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	return b[8-size:]
+}
+
+// Unmarshal decodes data, in the Standard format, into v, which must be
+// a pointer to a struct; see UnmarshalList.
+func Unmarshal(data []byte, v interface{}) error {
+	recs, err := decodeBytes(data)
+	if err != nil {
+		return err
+	}
+	return UnmarshalList(recs, v)
+}
+
+// UnmarshalList populates the tagged fields of the struct v points to
+// from recs, reversing MarshalList. Fields whose tag is absent from recs
+// are left as they are, so a pointer field stays nil unless its record
+// is present. A slice field, other than a byte slice, receives one
+// element per record with its tag; any other field takes the first.
+// Integers may be shorter than their encoded size, and signed integers
+// are sign-extended; a value that does not fit its field, such as a
+// large int on a 32-bit platform, is rejected with ErrValueSize.
+// Constructed fields are decoded from the record's children, or from
+// its value in the Standard format. Errors are reported as a
+// *FieldError. If v is an Unmarshaler or a *TLVList, it is populated
+// directly.
+func UnmarshalList(recs *TLVList, v interface{}) error {
+	switch v := v.(type) {
+	case *TLVList:
+		v.records = append([]TLV(nil), recs.records...)
+		v.sums = nil
+		if recs.sums != nil {
+			v.sums = append([]uint32{}, recs.sums...)
+		}
+		return nil
+	case Unmarshaler:
+		return v.UnmarshalTLV(recs)
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return ErrUnsupportedType
+	}
+	return unmarshalStruct(recs, rv.Elem())
+}
+
+// unmarshalStruct populates a struct value from recs.
+func unmarshalStruct(recs *TLVList, rv reflect.Value) error {
+	fields, err := structFields(rv.Type())
+	if err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		found := recs.GetAll(field.tag)
+		if len(found) == 0 {
+			continue
+		}
+
+		fv := rv.Field(field.index)
+		if err = unmarshalField(found, fv); err != nil {
+			if _, ok := err.(*FieldError); !ok {
+				err = &FieldError{rv.Type().Name(), field.name, field.tag, err}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// unmarshalField sets fv from the records carrying its tag.
+func unmarshalField(found []TLV, fv reflect.Value) error {
+	t := fv.Type()
+	switch {
+	case t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8:
+		elems := reflect.MakeSlice(t, len(found), len(found))
+		for i, rec := range found {
+			if err := unmarshalValue(rec, elems.Index(i)); err != nil {
+				return err
+			}
+		}
+		fv.Set(elems)
+		return nil
+	case t.Kind() == reflect.Array && t.Elem().Kind() != reflect.Uint8:
+		if len(found) > fv.Len() {
+			return ErrValueSize
+		}
+		for i, rec := range found {
+			if err := unmarshalValue(rec, fv.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return unmarshalValue(found[0], fv)
+}
+
+// unmarshalValue sets fv from a single record.
+func unmarshalValue(rec TLV, fv reflect.Value) error {
+	if fv.Kind() == reflect.Ptr {
+		elem := reflect.New(fv.Type().Elem())
+		if err := unmarshalValue(rec, elem.Elem()); err != nil {
+			return err
+		}
+		fv.Set(elem)
+		return nil
+	}
+
+	if u, ok := fv.Addr().Interface().(Unmarshaler); ok {
+		children, err := childList(rec)
+		if err != nil {
+			return err
+		}
+		return u.UnmarshalTLV(children)
+	}
+
+	value := rec.Value()
+	switch fv.Kind() {
+	case reflect.Bool:
+		if len(value) != 1 {
+			return ErrValueSize
+		}
+		fv.SetBool(value[0] != 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := getUint(value, intSize(fv.Kind()))
+		if err != nil {
+			return err
+		}
+		shift := 64 - 8*uint(len(value))
+		v := int64(n<<shift) >> shift
+		if fv.OverflowInt(v) {
+			return ErrValueSize
+		}
+		fv.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := getUint(value, intSize(fv.Kind()))
+		if err != nil {
+			return err
+		} else if fv.OverflowUint(n) {
+			return ErrValueSize
+		}
+		fv.SetUint(n)
+	case reflect.Float32:
+		if len(value) != 4 {
+			return ErrValueSize
+		}
+		fv.SetFloat(float64(math.Float32frombits(binary.BigEndian.Uint32(value))))
+	case reflect.Float64:
+		if len(value) != 8 {
+			return ErrValueSize
+		}
+		fv.SetFloat(math.Float64frombits(binary.BigEndian.Uint64(value)))
+	case reflect.String:
+		fv.SetString(string(value))
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() != reflect.Uint8 {
+			return ErrUnsupportedType
+		}
+		b := reflect.MakeSlice(fv.Type(), len(value), len(value))
+		reflect.Copy(b, reflect.ValueOf(value))
+		fv.Set(b)
+	case reflect.Array:
+		if fv.Type().Elem().Kind() != reflect.Uint8 {
+			return ErrUnsupportedType
+		} else if len(value) != fv.Len() {
+			return ErrValueSize
+		}
+		reflect.Copy(fv, reflect.ValueOf(value))
+	case reflect.Struct:
+		children, err := childList(rec)
+		if err != nil {
+			return err
+		}
+		if fv.Type() == listType {
+			fv.Set(reflect.ValueOf(*children))
+			return nil
+		}
+		return unmarshalStruct(children, fv)
+	default:
+		return ErrUnsupportedType
+	}
+	return nil
+}
+
+// childList returns the records held by a constructed record.
+func childList(rec TLV) (*TLVList, error) {
+	if c, ok := rec.(Constructed); ok {
+		return c.Children(), nil
+	}
+	return decodeBytes(rec.Value())
+}
+
+// getUint reads a big-endian number of at most size bytes.
+func getUint(value []byte, size int) (uint64, error) {
+	if len(value) == 0 || len(value) > size {
+		return 0, ErrValueSize
+	}
+	var b [8]byte
+	copy(b[8-len(value):], value)
+	return binary.BigEndian.Uint64(b[:]), nil
+}
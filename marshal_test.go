@@ -0,0 +1,206 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+type marshalAddress struct {
+	Street string `tlv:"1"`
+	Zip    uint16 `tlv:"2"`
+}
+
+type marshalPerson struct {
+	Name    string          `tlv:"0"`
+	Age     int8            `tlv:"1"`
+	Admin   bool            `tlv:"2"`
+	Home    *marshalAddress `tlv:"3"`
+	Work    *marshalAddress `tlv:"4"`
+	Phones  []string        `tlv:"5"`
+	Key     []byte          `tlv:"0x10,omitempty"`
+	Score   float64         `tlv:"0x11,omitempty"`
+	Comment string
+	secret  string `tlv:"6"`
+}
+
+func TestMarshal(t *testing.T) {
+	p := &marshalPerson{
+		Name:   "gopher",
+		Age:    13,
+		Admin:  true,
+		Home:   &marshalAddress{"Main St", 0x1234},
+		Phones: []string{"555-0100", "555-0199"},
+	}
+	recs, err := MarshalList(p)
+	if err != nil {
+		FailWithError(t, "TestMarshal", err)
+	}
+
+	want := New()
+	want.Add(0, []byte("gopher"))
+	want.Add(1, []byte{13})
+	want.Add(2, []byte{1})
+	home := New()
+	home.Add(1, []byte("Main St"))
+	home.Add(2, []byte{0x12, 0x34})
+	want.AddList(3, home)
+	want.Add(5, []byte("555-0100"))
+	want.Add(5, []byte("555-0199"))
+	if !CompareLists(want, recs, CompareRules{}).Equal() {
+		FailWithError(t, "TestMarshal", noMatch)
+	}
+
+	data, err := Marshal(p)
+	if err != nil {
+		FailWithError(t, "TestMarshal", err)
+	}
+	buf := new(bytes.Buffer)
+	want.Write(buf)
+	if !bytes.Equal(data, buf.Bytes()) {
+		FailWithError(t, "TestMarshal", noMatch)
+	}
+
+	// Platform-sized integers take eight bytes everywhere.
+	sized := struct {
+		N int     `tlv:"1"`
+		U uint    `tlv:"2"`
+		P uintptr `tlv:"3"`
+	}{-2, 3, 4}
+	if recs, err = MarshalList(sized); err != nil {
+		FailWithError(t, "TestMarshal", err)
+	}
+	want = New()
+	want.Add(1, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfe})
+	want.Add(2, []byte{0, 0, 0, 0, 0, 0, 0, 3})
+	want.Add(3, []byte{0, 0, 0, 0, 0, 0, 0, 4})
+	if !CompareLists(want, recs, CompareRules{}).Equal() {
+		FailWithError(t, "TestMarshal", noMatch)
+	}
+
+	if _, err = Marshal("gopher"); err != ErrUnsupportedType {
+		FailWithError(t, "TestMarshal", fmt.Errorf("expected ErrUnsupportedType, got %v", err))
+	}
+
+	bad := struct {
+		Ch chan int `tlv:"7"`
+	}{}
+	_, err = Marshal(bad)
+	if ferr, ok := err.(*FieldError); !ok || ferr.Field != "Ch" || ferr.Tag != 7 || ferr.Err != ErrUnsupportedType {
+		FailWithError(t, "TestMarshal", fmt.Errorf("bad field error: %v", err))
+	}
+
+	badTag := struct {
+		N int `tlv:"seven"`
+	}{}
+	if _, err = Marshal(badTag); err == nil || err.(*FieldError).Err != ErrStructTag {
+		FailWithError(t, "TestMarshal", fmt.Errorf("expected ErrStructTag, got %v", err))
+	}
+}
+
+type unmarshalUpper string
+
+func (u *unmarshalUpper) UnmarshalTLV(recs *TLVList) error {
+	rec, err := recs.Get(0)
+	if err != nil {
+		return err
+	}
+	*u = unmarshalUpper(bytes.ToUpper(rec.Value()))
+	return nil
+}
+
+func TestUnmarshal(t *testing.T) {
+	p := &marshalPerson{
+		Name:   "gopher",
+		Age:    -3,
+		Home:   &marshalAddress{"Main St", 0x1234},
+		Phones: []string{"555-0100", "555-0199"},
+		Key:    []byte{1, 2, 3},
+		Score:  0.5,
+	}
+	data, err := Marshal(p)
+	if err != nil {
+		FailWithError(t, "TestUnmarshal", err)
+	}
+
+	var q marshalPerson
+	if err = Unmarshal(data, &q); err != nil {
+		FailWithError(t, "TestUnmarshal", err)
+	}
+	if q.Name != p.Name || q.Age != p.Age || q.Admin || q.Score != p.Score ||
+		!bytes.Equal(q.Key, p.Key) || fmt.Sprint(q.Phones) != fmt.Sprint(p.Phones) {
+		FailWithError(t, "TestUnmarshal", fmt.Errorf("got %+v", q))
+	}
+	if q.Home == nil || *q.Home != *p.Home {
+		FailWithError(t, "TestUnmarshal", fmt.Errorf("bad home address %v", q.Home))
+	}
+	if q.Work != nil {
+		FailWithError(t, "TestUnmarshal", fmt.Errorf("absent field was set"))
+	}
+
+	// Short integers are sign-extended.
+	recs := New()
+	recs.Add(1, []byte{0xfe})
+	if err = UnmarshalList(recs, &q); err != nil || q.Age != -2 {
+		FailWithError(t, "TestUnmarshal", fmt.Errorf("bad age %d: %v", q.Age, err))
+	}
+
+	recs = New()
+	recs.Add(1, []byte{1, 2})
+	err = UnmarshalList(recs, &q)
+	if ferr, ok := err.(*FieldError); !ok || ferr.Field != "Age" || ferr.Tag != 1 || ferr.Err != ErrValueSize {
+		FailWithError(t, "TestUnmarshal", fmt.Errorf("bad field error: %v", err))
+	}
+
+	// Platform-sized integers accept eight bytes everywhere.
+	var sized struct {
+		N int  `tlv:"1"`
+		U uint `tlv:"2"`
+	}
+	recs = New()
+	recs.Add(1, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfe})
+	recs.Add(2, []byte{0, 0, 0, 0, 0, 0, 0, 3})
+	if err = UnmarshalList(recs, &sized); err != nil || sized.N != -2 || sized.U != 3 {
+		FailWithError(t, "TestUnmarshal", fmt.Errorf("got %+v: %v", sized, err))
+	}
+	recs = New()
+	recs.Add(1, []byte{1, 0, 0, 0, 0})
+	err = UnmarshalList(recs, &sized)
+	if strconv.IntSize == 32 {
+		if ferr, ok := err.(*FieldError); !ok || ferr.Err != ErrValueSize {
+			FailWithError(t, "TestUnmarshal", fmt.Errorf("expected ErrValueSize, got %v", err))
+		}
+	} else if err != nil || int64(sized.N) != 1<<32 {
+		FailWithError(t, "TestUnmarshal", fmt.Errorf("got %+v: %v", sized, err))
+	}
+
+	inner := New()
+	inner.Add(0, []byte("shout"))
+	recs = New()
+	recs.AddList(9, inner)
+	var withUnmarshaler struct {
+		Word unmarshalUpper `tlv:"9"`
+	}
+	if err = UnmarshalList(recs, &withUnmarshaler); err != nil || withUnmarshaler.Word != "SHOUT" {
+		FailWithError(t, "TestUnmarshal", fmt.Errorf("got %q: %v", withUnmarshaler.Word, err))
+	}
+
+	// A TLVList is replaced, checksums and all, without sharing
+	// storage with the source.
+	dst := New()
+	dst.Add(TagTest2, []byte("stale"))
+	dst.Protect()
+	if err = UnmarshalList(inner, dst); err != nil {
+		FailWithError(t, "TestUnmarshal", err)
+	} else if err = dst.Verify(); err != nil {
+		FailWithError(t, "TestUnmarshal", err)
+	}
+	dst.Add(TagTest2, []byte("new"))
+	checkTags(t, "TestUnmarshal", dst, 0, TagTest2)
+	checkTags(t, "TestUnmarshal", inner, 0)
+
+	if err = Unmarshal(data, q); err != ErrUnsupportedType {
+		FailWithError(t, "TestUnmarshal", fmt.Errorf("expected ErrUnsupportedType, got %v", err))
+	}
+}
@@ -0,0 +1,328 @@
+package tlv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+)
+
+// Matter tag forms, as held in a MatterElement's TagControl. Each
+// profile form covers both the short and long encodings of the tag
+// number; the shortest that fits is used when writing.
+const (
+	MatterAnonymous       = 0
+	MatterContext         = 1
+	MatterCommonProfile   = 2
+	MatterImplicitProfile = 4
+	MatterFullyQualified  = 6
+)
+
+// Matter element types, as held in a MatterElement's Type. Integers and
+// strings are given their base type, regardless of the width of their
+// value or length as read; the shortest width that fits is used when
+// writing.
+const (
+	MatterInt       = 0x00
+	MatterUint      = 0x04
+	MatterFalse     = 0x08
+	MatterTrue      = 0x09
+	MatterFloat32   = 0x0a
+	MatterFloat64   = 0x0b
+	MatterUTF8      = 0x0c
+	MatterBytes     = 0x10
+	MatterNull      = 0x14
+	MatterStructure = 0x15
+	MatterArray     = 0x16
+	MatterList      = 0x17
+
+	matterEnd = 0x18
+)
+
+var matterTagSize = [8]int{0, 1, 2, 4, 2, 4, 6, 8}
+
+// Type MatterElement is an element of the Matter (formerly CHIP) TLV
+// encoding. It implements TLV, using the tag number as the tag and the
+// element's data as the value, so elements can be held in a TLVList
+// alongside other records; anonymous elements have the tag -1.
+//
+// Data holds an integer's little-endian bytes, a float's IEEE 754 bytes,
+// a string's bytes, or a container's encoded members, which can be read
+// with Members. Booleans and nulls have no data.
+type MatterElement struct {
+	TagControl byte
+	Vendor     uint16
+	Profile    uint16
+	TagNumber  uint32
+	Type       byte
+	Data       []byte
+}
+
+// Method Tag returns the element's tag number, or -1 for an anonymous
+// element.
+func (elem *MatterElement) Tag() int {
+	if elem.TagControl == MatterAnonymous {
+		return -1
+	}
+	return int(elem.TagNumber)
+}
+
+// Method Length returns the length of the element's data.
+func (elem *MatterElement) Length() int {
+	return len(elem.Data)
+}
+
+// Method Value returns the element's data.
+func (elem *MatterElement) Value() []byte {
+	return elem.Data
+}
+
+// Members returns the members of a structure, array, or list element.
+func (elem *MatterElement) Members() (*TLVList, error) {
+	if elem.Type < MatterStructure || elem.Type > MatterList {
+		return nil, ErrUnsupportedType
+	}
+	return parseMatterList(elem.Data)
+}
+
+// ReadMatter reads Matter TLV elements until the end of r, returning
+// each as a *MatterElement. Containers may be nested at most 32 levels
+// deep; deeper ones are rejected with ErrTooLarge.
+func ReadMatter(r io.Reader) (*TLVList, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return parseMatterList(data)
+}
+
+func parseMatterList(data []byte) (*TLVList, error) {
+	recs := New()
+	for pos := 0; pos < len(data); {
+		elem, n, err := parseMatter(data[pos:], 0)
+		if err != nil {
+			return nil, err
+		} else if elem == nil {
+			return nil, ErrTLVRead
+		}
+		recs.records = append(recs.records, elem)
+		pos += n
+	}
+	return recs, nil
+}
+
+func matterUint(b []byte) uint64 {
+	var buf [8]byte
+	copy(buf[:], b)
+	return binary.LittleEndian.Uint64(buf[:])
+}
+
+// parseMatter parses the element at the start of data, returning it and
+// its encoded size. An end-of-container marker is returned as a nil
+// element. depth is the number of containers enclosing the element.
+func parseMatter(data []byte, depth int) (elem *MatterElement, n int, err error) {
+	if len(data) == 0 {
+		return nil, 0, ErrTLVRead
+	}
+	control := data[0]
+	if control == matterEnd {
+		return nil, 1, nil
+	}
+
+	elem = new(MatterElement)
+	form, typ := control>>5, control&0x1f
+	elem.TagControl = form &^ 1
+	if form == MatterContext {
+		elem.TagControl = form
+	}
+	pos := 1 + matterTagSize[form]
+	if len(data) < pos {
+		return nil, 0, ErrTLVRead
+	}
+	tag := data[1:pos]
+	switch form {
+	case 1, 2, 3, 4, 5:
+		elem.TagNumber = uint32(matterUint(tag))
+	case 6, 7:
+		elem.Vendor = binary.LittleEndian.Uint16(tag)
+		elem.Profile = binary.LittleEndian.Uint16(tag[2:])
+		elem.TagNumber = uint32(matterUint(tag[4:]))
+	}
+
+	var size uint64
+	switch {
+	case typ < MatterFalse:
+		elem.Type = typ &^ 3
+		size = 1 << (typ & 3)
+	case typ == MatterFalse, typ == MatterTrue, typ == MatterNull:
+		elem.Type = typ
+	case typ == MatterFloat32:
+		elem.Type, size = typ, 4
+	case typ == MatterFloat64:
+		elem.Type, size = typ, 8
+	case typ < MatterNull:
+		elem.Type = typ &^ 3
+		width := 1 << (typ & 3)
+		if len(data) < pos+width {
+			return nil, 0, ErrTLVRead
+		}
+		size = matterUint(data[pos : pos+width])
+		pos += width
+	case typ <= MatterList:
+		if depth >= maxNestDepth {
+			return nil, 0, ErrTooLarge
+		}
+		elem.Type = typ
+		start := pos
+		for {
+			member, m, err := parseMatter(data[pos:], depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			pos += m
+			if member == nil {
+				break
+			}
+		}
+		elem.Data = data[start : pos-1]
+		return elem, pos, nil
+	default:
+		return nil, 0, ErrTLVRead
+	}
+
+	if size > uint64(len(data)-pos) {
+		return nil, 0, ErrTLVRead
+	}
+	elem.Data = data[pos : pos+int(size)]
+	return elem, pos + int(size), nil
+}
+
+// WriteMatter writes the records in recs as Matter TLV elements in
+// canonical form: tags, integers, and string lengths are written in the
+// shortest form that holds them, and container members are
+// canonicalized in turn. Records that are not *MatterElement values are
+// written as context-tagged octet strings.
+func WriteMatter(w io.Writer, recs *TLVList) error {
+	buf := new(bytes.Buffer)
+	if err := writeMatterList(buf, recs); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writeMatterList(buf *bytes.Buffer, recs *TLVList) error {
+	for _, tlv := range recs.records {
+		elem, ok := tlv.(*MatterElement)
+		if !ok {
+			if tlv.Tag() < 0 || tlv.Tag() > 0xff {
+				return ErrTagRange
+			}
+			elem = &MatterElement{TagControl: MatterContext, TagNumber: uint32(tlv.Tag()),
+				Type: MatterBytes, Data: tlv.Value()}
+		}
+		if err := writeMatter(buf, elem); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matterWidth returns the code for the shortest of 1, 2, 4, or 8 bytes
+// that holds n.
+func matterWidth(n uint64) byte {
+	switch {
+	case n <= 0xff:
+		return 0
+	case n <= 0xffff:
+		return 1
+	case n <= 0xffffffff:
+		return 2
+	}
+	return 3
+}
+
+func writeMatter(buf *bytes.Buffer, elem *MatterElement) error {
+	var tag [8]byte
+	form := elem.TagControl
+	wide := elem.TagNumber > 0xffff
+	switch form {
+	case MatterAnonymous:
+	case MatterContext:
+		if elem.TagNumber > 0xff {
+			return ErrTagRange
+		}
+		tag[0] = byte(elem.TagNumber)
+	case MatterCommonProfile, MatterImplicitProfile:
+		binary.LittleEndian.PutUint32(tag[:], elem.TagNumber)
+	case MatterFullyQualified:
+		binary.LittleEndian.PutUint16(tag[:], elem.Vendor)
+		binary.LittleEndian.PutUint16(tag[2:], elem.Profile)
+		binary.LittleEndian.PutUint32(tag[4:], elem.TagNumber)
+	default:
+		return ErrTagRange
+	}
+	if wide && form != MatterContext && form != MatterAnonymous {
+		form++
+	}
+
+	typ, data := elem.Type, elem.Data
+	var length []byte
+	switch typ {
+	case MatterInt:
+		if len(data) > 8 {
+			return ErrLengthRange
+		}
+		v := matterUint(data)
+		if len(data) > 0 && len(data) < 8 && data[len(data)-1]&0x80 != 0 {
+			v |= ^uint64(0) << (8 * uint(len(data)))
+		}
+		s := int64(v)
+		if s < 0 {
+			s = ^s
+		}
+		typ |= matterWidth(uint64(s) << 1)
+		data = make([]byte, 8)
+		binary.LittleEndian.PutUint64(data, v)
+		data = data[:1<<(typ&3)]
+	case MatterUint:
+		if len(data) > 8 {
+			return ErrLengthRange
+		}
+		v := matterUint(data)
+		typ |= matterWidth(v)
+		data = make([]byte, 8)
+		binary.LittleEndian.PutUint64(data, v)
+		data = data[:1<<(typ&3)]
+	case MatterFalse, MatterTrue, MatterNull:
+		data = nil
+	case MatterFloat32, MatterFloat64:
+		if len(data) != 4<<(typ-MatterFloat32) {
+			return ErrLengthRange
+		}
+	case MatterUTF8, MatterBytes:
+		typ |= matterWidth(uint64(len(data)))
+		length = make([]byte, 8)
+		binary.LittleEndian.PutUint64(length, uint64(len(data)))
+		length = length[:1<<(typ&3)]
+	case MatterStructure, MatterArray, MatterList:
+		members, err := parseMatterList(data)
+		if err != nil {
+			return err
+		}
+		buf.WriteByte(form<<5 | typ)
+		buf.Write(tag[:matterTagSize[form]])
+		if err = writeMatterList(buf, members); err != nil {
+			return err
+		}
+		return buf.WriteByte(matterEnd)
+	default:
+		return ErrUnsupportedType
+	}
+
+	buf.WriteByte(form<<5 | typ)
+	buf.Write(tag[:matterTagSize[form]])
+	buf.Write(length)
+	buf.Write(data)
+	return nil
+}
@@ -0,0 +1,98 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// A Matter structure holding context tag 1 as a 4-byte unsigned integer
+// 42, context tag 2 as true, and context tag 3 as an array of two signed
+// integers, -1 and 300; followed by a fully qualified UTF-8 string.
+var matterElements = []byte{
+	0x15,
+	0x26, 0x01, 0x2a, 0x00, 0x00, 0x00,
+	0x29, 0x02,
+	0x36, 0x03,
+	0x01, 0xff, 0xff,
+	0x01, 0x2c, 0x01,
+	0x18,
+	0x18,
+	0xcc, 0xf1, 0xff, 0x01, 0x00, 0x05, 0x00, 0x02, 'g', 'o',
+}
+
+// The same elements in canonical form.
+var matterCanonical = []byte{
+	0x15,
+	0x24, 0x01, 0x2a,
+	0x29, 0x02,
+	0x36, 0x03,
+	0x00, 0xff,
+	0x01, 0x2c, 0x01,
+	0x18,
+	0x18,
+	0xcc, 0xf1, 0xff, 0x01, 0x00, 0x05, 0x00, 0x02, 'g', 'o',
+}
+
+func TestMatter(t *testing.T) {
+	elems, err := ReadMatter(bytes.NewReader(matterElements))
+	if err != nil {
+		FailWithError(t, "TestMatter", err)
+	}
+	checkTags(t, "TestMatter", elems, -1, 5)
+
+	name, _ := elems.Get(5)
+	if elem := name.(*MatterElement); elem.Vendor != 0xfff1 || elem.Profile != 1 || string(elem.Data) != "go" {
+		FailWithError(t, "TestMatter", noMatch)
+	}
+
+	st, _ := elems.Get(-1)
+	members, err := st.(*MatterElement).Members()
+	if err != nil {
+		FailWithError(t, "TestMatter", err)
+	}
+	checkTags(t, "TestMatter", members, 1, 2, 3)
+	arr, _ := members.Get(3)
+	items, err := arr.(*MatterElement).Members()
+	if err != nil {
+		FailWithError(t, "TestMatter", err)
+	} else if items.Length() != 2 {
+		FailWithError(t, "TestMatter",
+			fmt.Errorf("expected 2 array items, got %d", items.Length()))
+	}
+
+	buf := new(bytes.Buffer)
+	if err = WriteMatter(buf, elems); err != nil {
+		FailWithError(t, "TestMatter", err)
+	} else if !bytes.Equal(buf.Bytes(), matterCanonical) {
+		FailWithError(t, "TestMatter",
+			fmt.Errorf("bad canonical form % x", buf.Bytes()))
+	}
+
+	if _, err = ReadMatter(bytes.NewReader(matterElements[:8])); err != ErrTLVRead {
+		FailWithError(t, "TestMatter",
+			fmt.Errorf("unterminated structure should fail"))
+	}
+}
+
+func TestMatterDepth(t *testing.T) {
+	nested := func(depth int) []byte {
+		data := bytes.Repeat([]byte{MatterArray}, depth)
+		return append(data, bytes.Repeat([]byte{matterEnd}, depth)...)
+	}
+
+	if _, err := ReadMatter(bytes.NewReader(nested(maxNestDepth))); err != nil {
+		FailWithError(t, "TestMatterDepth", err)
+	}
+	if _, err := ReadMatter(bytes.NewReader(nested(maxNestDepth + 1))); err != ErrTooLarge {
+		FailWithError(t, "TestMatterDepth",
+			fmt.Errorf("expected ErrTooLarge, got %v", err))
+	}
+
+	// Unterminated containers must not exhaust the stack.
+	deep := bytes.Repeat([]byte{MatterArray}, 1<<20)
+	if _, err := ReadMatter(bytes.NewReader(deep)); err != ErrTooLarge {
+		FailWithError(t, "TestMatterDepth",
+			fmt.Errorf("expected ErrTooLarge, got %v", err))
+	}
+}
@@ -0,0 +1,77 @@
+package tlv
+
+// Type MergeAction determines what Merge does with a tag found in both
+// lists being merged.
+type MergeAction int
+
+const (
+	// MergeKeepFirst keeps the records already in the list and drops
+	// the other list's records with the tag.
+	MergeKeepFirst MergeAction = iota
+
+	// MergeKeepLast removes the records already in the list and adds
+	// the other list's records with the tag in their place.
+	MergeKeepLast
+
+	// MergeKeepBoth keeps the records already in the list and adds
+	// the other list's records with the tag as well.
+	MergeKeepBoth
+
+	// MergeError causes Merge to fail with ErrConflict.
+	MergeError
+)
+
+// Type MergePolicy selects the MergeAction taken for each tag found in
+// both lists being merged. Tags missing from Tags use Default, so the
+// zero policy keeps the first list's records throughout.
+type MergePolicy struct {
+	Default MergeAction
+	Tags    map[int]MergeAction
+}
+
+// action returns the action taken for tag.
+func (policy MergePolicy) action(tag int) MergeAction {
+	if action, ok := policy.Tags[tag]; ok {
+		return action
+	}
+	return policy.Default
+}
+
+// Merge adds the records of other to the TLVList, in order, using policy
+// to settle tags present in both lists; records whose tags are only in
+// other are always added. This suits layered configuration: merging
+// overrides into defaults with MergeKeepLast leaves each overridden tag
+// holding only the override's records, at the end of the list. If any
+// tag in both lists has the MergeError action, ErrConflict is returned
+// and the list is left unchanged. Records are shared with other, not
+// copied.
+func (recs *TLVList) Merge(other *TLVList, policy MergePolicy) error {
+	present := make(map[int]bool, len(recs.records))
+	for _, tlv := range recs.records {
+		present[tlv.Tag()] = true
+	}
+	for _, tlv := range other.records {
+		if present[tlv.Tag()] && policy.action(tlv.Tag()) == MergeError {
+			return ErrConflict
+		}
+	}
+
+	incoming := append([]TLV(nil), other.records...)
+	replaced := make(map[int]bool)
+	for _, tlv := range incoming {
+		tag := tlv.Tag()
+		if present[tag] {
+			switch policy.action(tag) {
+			case MergeKeepFirst:
+				continue
+			case MergeKeepLast:
+				if !replaced[tag] {
+					recs.Remove(tag)
+					replaced[tag] = true
+				}
+			}
+		}
+		recs.AddRecord(tlv)
+	}
+	return nil
+}
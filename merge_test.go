@@ -0,0 +1,52 @@
+package tlv
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	defaults := func() *TLVList {
+		tlvl := New()
+		tlvl.Add(TagTest1, []byte("host"))
+		tlvl.Add(TagTest2, []byte("80"))
+		tlvl.Add(TagTest3, []byte("a"))
+		return tlvl
+	}
+	overrides := New()
+	overrides.Add(TagTest2, []byte("8080"))
+	overrides.Add(TagTest3, []byte("b"))
+	overrides.Add(TagTest3, []byte("c"))
+	overrides.Add(TagTest4, []byte("new"))
+
+	tests := []struct {
+		policy MergePolicy
+		want   string
+	}{
+		{MergePolicy{}, "0=str:host, 1=str:80, 2=str:a, 3=str:new"},
+		{MergePolicy{Default: MergeKeepLast}, "0=str:host, 1=str:8080, 2=str:b, 2=str:c, 3=str:new"},
+		{MergePolicy{Default: MergeKeepBoth}, "0=str:host, 1=str:80, 2=str:a, 1=str:8080, 2=str:b, 2=str:c, 3=str:new"},
+		{MergePolicy{Default: MergeKeepLast, Tags: map[int]MergeAction{TagTest3: MergeKeepBoth}},
+			"0=str:host, 2=str:a, 1=str:8080, 2=str:b, 2=str:c, 3=str:new"},
+	}
+	for i, test := range tests {
+		tlvl := defaults()
+		if err := tlvl.Merge(overrides, test.policy); err != nil {
+			FailWithError(t, "TestMerge", err)
+		} else if got := tlvl.Spec(); got != test.want {
+			FailWithError(t, "TestMerge", fmt.Errorf("policy %d: got %s", i, got))
+		}
+	}
+
+	tlvl := defaults()
+	policy := MergePolicy{Default: MergeKeepLast, Tags: map[int]MergeAction{TagTest3: MergeError}}
+	if err := tlvl.Merge(overrides, policy); err != ErrConflict {
+		FailWithError(t, "TestMerge", fmt.Errorf("expected ErrConflict, got %v", err))
+	} else if tlvl.Spec() != defaults().Spec() {
+		FailWithError(t, "TestMerge", fmt.Errorf("failed merge changed the list"))
+	}
+
+	if err := tlvl.Merge(tlvl, MergePolicy{Default: MergeKeepBoth}); err != nil || tlvl.Length() != 6 {
+		FailWithError(t, "TestMerge", fmt.Errorf("self merge gave %d records: %v", tlvl.Length(), err))
+	}
+}
@@ -0,0 +1,43 @@
+package tlv
+
+import "bytes"
+
+// MaxMessageLength and MaxMessageRecords are the limits applied by
+// NewMessageDecoder. MaxMessageLength matches the default maximum NATS
+// payload size.
+const (
+	MaxMessageLength  = 1 << 20
+	MaxMessageRecords = 4096
+)
+
+// EncodeMessage encodes a TLVList as a message payload, such as the data
+// of a NATS message. Message payloads use the Varint format, which
+// keeps the overhead of small records low.
+func EncodeMessage(recs *TLVList) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+	enc.Format = Varint
+	if err := enc.EncodeList(recs); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// NewMessageDecoder returns a Decoder for a message payload produced by
+// EncodeMessage. The Decoder is limited to MaxMessageRecords records and
+// values of at most MaxMessageLength bytes, which is appropriate for
+// subjects that untrusted clients may publish to; the limits may be
+// adjusted before decoding.
+func NewMessageDecoder(data []byte) *Decoder {
+	dec := NewDecoder(bytes.NewReader(data))
+	dec.Format = Varint
+	dec.MaxLength = MaxMessageLength
+	dec.MaxRecords = MaxMessageRecords
+	return dec
+}
+
+// DecodeMessage decodes a message payload produced by EncodeMessage,
+// applying the limits described in NewMessageDecoder.
+func DecodeMessage(data []byte) (*TLVList, error) {
+	return NewMessageDecoder(data).DecodeList()
+}
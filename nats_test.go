@@ -0,0 +1,81 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestMessage(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(TagTest1, []byte("foo bar"))
+	tlvl.Add(TagTest2, []byte("baz quux"))
+
+	data, err := EncodeMessage(tlvl)
+	if err != nil {
+		FailWithError(t, "TestMessage", err)
+	}
+
+	rtlvl, err := DecodeMessage(data)
+	if err != nil {
+		FailWithError(t, "TestMessage", err)
+	} else if rtlvl.Length() != 2 {
+		FailWithError(t, "TestMessage", noMatch)
+	}
+
+	// A header claiming a huge value must be rejected up front.
+	hostile := []byte{1, 0xff, 0xff, 0xff, 0xff, 0x07}
+	if _, err = DecodeMessage(hostile); err != ErrTooLarge {
+		FailWithError(t, "TestMessage",
+			fmt.Errorf("oversized record should be rejected"))
+	}
+
+	hostile = bytes.Repeat([]byte{1, 0}, MaxMessageRecords+1)
+	if _, err = DecodeMessage(hostile); err != ErrTooLarge {
+		FailWithError(t, "TestMessage",
+			fmt.Errorf("too many records should be rejected"))
+	}
+}
+
+// This example shows the request/reply pattern: the reply function is
+// the body of a handler that would be passed to a NATS subscription, and
+// the request would be sent with the connection's Request method.
+func ExampleDecodeMessage() {
+	const (
+		TagName = iota + 1
+		TagGreeting
+	)
+
+	reply := func(data []byte) []byte {
+		req, err := DecodeMessage(data)
+		if err != nil {
+			return nil
+		}
+		name, err := req.Get(TagName)
+		if err != nil {
+			return nil
+		}
+
+		resp := New()
+		resp.Add(TagGreeting, append([]byte("hello, "), name.Value()...))
+		out, _ := EncodeMessage(resp)
+		return out
+	}
+
+	req := New()
+	req.Add(TagName, []byte("gopher"))
+	data, err := EncodeMessage(req)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	resp, err := DecodeMessage(reply(data))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	greeting, _ := resp.Get(TagGreeting)
+	fmt.Println(string(greeting.Value()))
+	// Output: hello, gopher
+}
@@ -0,0 +1,213 @@
+package tlv
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// NDEF type name formats, which describe how an NDEF record's type is to
+// be interpreted.
+const (
+	NDEFEmpty     = 0x00
+	NDEFWellKnown = 0x01
+	NDEFMedia     = 0x02
+	NDEFURI       = 0x03
+	NDEFExternal  = 0x04
+	NDEFUnknown   = 0x05
+	NDEFUnchanged = 0x06
+)
+
+// NDEF record header flags.
+const (
+	ndefMB  = 0x80
+	ndefME  = 0x40
+	ndefCF  = 0x20
+	ndefSR  = 0x10
+	ndefIL  = 0x08
+	ndefTNF = 0x07
+)
+
+// ErrNDEF is returned when an NDEF message is malformed, such as when
+// its first record is not marked as the start of the message or a
+// chunked record is not terminated.
+var ErrNDEF = fmt.Errorf("malformed NDEF message")
+
+// Type NDEFRecord is an NFC Data Exchange Format record. It implements
+// TLV, using the type name format as the tag and the payload as the
+// value, so NDEF records can be held in a TLVList alongside other
+// records.
+type NDEFRecord struct {
+	TNF     byte
+	Type    []byte
+	ID      []byte
+	Payload []byte
+}
+
+// Method Tag returns the record's type name format.
+func (rec *NDEFRecord) Tag() int {
+	return int(rec.TNF)
+}
+
+// Method Length returns the length of the record's payload.
+func (rec *NDEFRecord) Length() int {
+	return len(rec.Payload)
+}
+
+// Method Value returns the record's payload.
+func (rec *NDEFRecord) Value() []byte {
+	return rec.Payload
+}
+
+// ReadNDEF reads an NDEF message, returning each of its records as an
+// *NDEFRecord. Chunked records are reassembled. Reading stops at the
+// record marked as the end of the message.
+func ReadNDEF(r io.Reader) (recs *TLVList, err error) {
+	recs = New()
+	var chunked *NDEFRecord
+	for first := true; ; first = false {
+		var rec *NDEFRecord
+		var flags byte
+		if rec, flags, err = readNDEFRecord(r); err != nil {
+			if err == io.EOF {
+				err = ErrNDEF
+			}
+			return nil, err
+		} else if first != (flags&ndefMB != 0) {
+			return nil, ErrNDEF
+		}
+
+		switch {
+		case chunked != nil:
+			if rec.TNF != NDEFUnchanged || len(rec.Type) != 0 || len(rec.ID) != 0 {
+				return nil, ErrNDEF
+			}
+			chunked.Payload = append(chunked.Payload, rec.Payload...)
+			rec = chunked
+		case rec.TNF == NDEFUnchanged:
+			return nil, ErrNDEF
+		}
+
+		chunked = nil
+		if flags&ndefCF != 0 {
+			chunked = rec
+		} else {
+			recs.records = append(recs.records, rec)
+		}
+
+		if flags&ndefME != 0 {
+			if chunked != nil {
+				return nil, ErrNDEF
+			}
+			return recs, nil
+		}
+	}
+}
+
+func readNDEFRecord(r io.Reader) (rec *NDEFRecord, flags byte, err error) {
+	var hdr [7]byte
+	if _, err = io.ReadFull(r, hdr[:2]); err == io.ErrUnexpectedEOF {
+		return nil, 0, ErrTLVRead
+	} else if err != nil {
+		return
+	}
+
+	flags = hdr[0]
+	typeLength := int(hdr[1])
+	n := 4
+	if flags&ndefSR != 0 {
+		n = 1
+	}
+	if flags&ndefIL != 0 {
+		n++
+	}
+	if _, err = io.ReadFull(r, hdr[2:2+n]); err != nil {
+		return nil, 0, ErrTLVRead
+	}
+
+	var payloadLength uint64
+	if flags&ndefSR != 0 {
+		payloadLength = uint64(hdr[2])
+	} else {
+		payloadLength = uint64(binary.BigEndian.Uint32(hdr[2:]))
+	}
+	var idLength int
+	if flags&ndefIL != 0 {
+		idLength = int(hdr[1+n])
+	}
+	// The sum is checked, not the payload length alone, as it must
+	// fit in an int on 32-bit platforms too.
+	length := uint64(typeLength+idLength) + payloadLength
+	if length > maxChunk {
+		return nil, 0, ErrLengthRange
+	}
+
+	body, err := readValue(r, int(length))
+	if err != nil {
+		return nil, 0, ErrTLVRead
+	}
+
+	rec = &NDEFRecord{TNF: flags & ndefTNF}
+	rec.Type = body[:typeLength]
+	rec.ID = body[typeLength : typeLength+idLength]
+	rec.Payload = body[typeLength+idLength:]
+	return rec, flags, nil
+}
+
+// WriteNDEF writes the records in recs as an NDEF message, marking the
+// first and last records as the start and end of the message and using
+// the short record form where the payload allows. Records that are not
+// *NDEFRecord values are written with their tag as the type name format
+// and no type.
+func WriteNDEF(w io.Writer, recs *TLVList) (err error) {
+	for i, tlv := range recs.records {
+		rec, ok := tlv.(*NDEFRecord)
+		if !ok {
+			if tlv.Tag() < 0 || tlv.Tag() >= NDEFUnchanged {
+				return ErrTagRange
+			}
+			rec = &NDEFRecord{TNF: byte(tlv.Tag()), Payload: tlv.Value()}
+		}
+
+		var flags byte
+		if i == 0 {
+			flags |= ndefMB
+		}
+		if i == len(recs.records)-1 {
+			flags |= ndefME
+		}
+		if err = writeNDEFRecord(w, rec, flags); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func writeNDEFRecord(w io.Writer, rec *NDEFRecord, flags byte) error {
+	if rec.TNF >= NDEFUnchanged {
+		return ErrTagRange
+	} else if len(rec.Type) > 0xff || len(rec.ID) > 0xff || uint64(len(rec.Payload)) > 0xffffffff {
+		return ErrLengthRange
+	}
+
+	hdr := []byte{flags | rec.TNF, byte(len(rec.Type))}
+	if len(rec.Payload) <= 0xff {
+		hdr[0] |= ndefSR
+		hdr = append(hdr, byte(len(rec.Payload)))
+	} else {
+		var length [4]byte
+		binary.BigEndian.PutUint32(length[:], uint32(len(rec.Payload)))
+		hdr = append(hdr, length[:]...)
+	}
+	if len(rec.ID) > 0 {
+		hdr[0] |= ndefIL
+		hdr = append(hdr, byte(len(rec.ID)))
+	}
+
+	for _, p := range [][]byte{hdr, rec.Type, rec.ID, rec.Payload} {
+		if _, err := w.Write(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// An NDEF message holding a well-known URI record for https://go.dev
+// and a media record with an ID, whose payload is split across two
+// chunks.
+var ndefMessage = []byte{
+	0x91, 0x01, 0x07, 'U', 0x04, 'g', 'o', '.', 'd', 'e', 'v',
+	0x3a, 0x0a, 0x02, 0x01, 't', 'e', 'x', 't', '/', 'p', 'l', 'a', 'i', 'n', 'a', 'h', 'i',
+	0x56, 0x00, 0x04, ' ', 'g', 'o', '!',
+}
+
+func TestNDEF(t *testing.T) {
+	msg, err := ReadNDEF(bytes.NewReader(ndefMessage))
+	if err != nil {
+		FailWithError(t, "TestNDEF", err)
+	}
+	checkTags(t, "TestNDEF", msg, NDEFWellKnown, NDEFMedia)
+
+	uri, _ := msg.Get(NDEFWellKnown)
+	if string(uri.(*NDEFRecord).Type) != "U" || string(uri.Value()[1:]) != "go.dev" {
+		FailWithError(t, "TestNDEF", noMatch)
+	}
+	text, _ := msg.Get(NDEFMedia)
+	if rec := text.(*NDEFRecord); string(rec.Payload) != "hi go!" || string(rec.ID) != "a" {
+		FailWithError(t, "TestNDEF",
+			fmt.Errorf("chunked record was not reassembled: %q", rec.Payload))
+	}
+
+	buf := new(bytes.Buffer)
+	if err = WriteNDEF(buf, msg); err != nil {
+		FailWithError(t, "TestNDEF", err)
+	}
+	rmsg, err := ReadNDEF(buf)
+	if err != nil {
+		FailWithError(t, "TestNDEF", err)
+	} else if !Equals(rmsg.records[rmsg.Length()-1], text) {
+		FailWithError(t, "TestNDEF", noMatch)
+	}
+
+	if _, err = ReadNDEF(bytes.NewReader(ndefMessage[11:])); err != ErrNDEF {
+		FailWithError(t, "TestNDEF",
+			fmt.Errorf("message without MB should fail"))
+	} else if _, err = ReadNDEF(bytes.NewReader(ndefMessage[:28])); err != ErrNDEF {
+		FailWithError(t, "TestNDEF",
+			fmt.Errorf("unterminated message should fail"))
+	}
+}
+
+func TestNDEFLongRecord(t *testing.T) {
+	msg := New()
+	msg.AddRecord(&NDEFRecord{TNF: NDEFExternal, Type: []byte("go.dev:blob"), Payload: make([]byte, 1000)})
+	msg.Add(NDEFEmpty, nil)
+
+	buf := new(bytes.Buffer)
+	if err := WriteNDEF(buf, msg); err != nil {
+		FailWithError(t, "TestNDEFLongRecord", err)
+	}
+	rmsg, err := ReadNDEF(buf)
+	if err != nil {
+		FailWithError(t, "TestNDEFLongRecord", err)
+	}
+	checkTags(t, "TestNDEFLongRecord", rmsg, NDEFExternal, NDEFEmpty)
+	if blob, _ := rmsg.Get(NDEFExternal); blob.Length() != 1000 {
+		FailWithError(t, "TestNDEFLongRecord", noMatch)
+	}
+}
+
+func TestNDEFHostileLength(t *testing.T) {
+	// A record claiming a 2 GB payload, followed by nothing.
+	hostile := []byte{0xc1, 0x00, 0x7f, 0xff, 0xff, 0xff}
+	var err error
+	n := allocatedBy(func() {
+		_, err = ReadNDEF(bytes.NewReader(hostile))
+	})
+	if err != ErrTLVRead {
+		FailWithError(t, "TestNDEFHostileLength",
+			fmt.Errorf("expected ErrTLVRead, got %v", err))
+	} else if n > 1<<20 {
+		FailWithError(t, "TestNDEFHostileLength",
+			fmt.Errorf("%d bytes allocated for a short input", n))
+	}
+
+	// With the type, the length no longer fits in 31 bits.
+	hostile = []byte{0xc1, 0xff, 0x7f, 0xff, 0xff, 0xff}
+	if _, err = ReadNDEF(bytes.NewReader(hostile)); err != ErrLengthRange {
+		FailWithError(t, "TestNDEFHostileLength",
+			fmt.Errorf("expected ErrLengthRange, got %v", err))
+	}
+}
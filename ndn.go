@@ -0,0 +1,96 @@
+package tlv
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// NDN is the Named Data Networking TLV format, in which the tag (the TLV
+// type) and the length are each written as a VAR-NUMBER: values below
+// 253 take a single byte, and larger values are written as a marker byte
+// of 253, 254 or 255 followed by a 2, 4 or 8 byte big-endian integer.
+//
+// NDN requires the shortest encoding of each number, and the format
+// rejects any other encoding, so a packet decoded in this format is
+// always re-encoded byte for byte. Nested TLVs are held in their parent's
+// value and may be decoded in turn.
+var NDN Format = ndnFormat{}
+
+type ndnFormat struct{}
+
+func (ndnFormat) ReadHeader(r io.Reader) (tag, length int, err error) {
+	br := asByteReader(r)
+	n, err := readNDNNumber(br)
+	if err != nil {
+		if err == ErrLengthRange {
+			err = ErrTagRange
+		}
+		return
+	}
+	tag = int(n)
+
+	n, err = readNDNNumber(br)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	length = int(n)
+	return
+}
+
+func (ndnFormat) WriteHeader(w io.Writer, tag, length int) error {
+	if tag < 0 {
+		return ErrTagRange
+	} else if length < 0 {
+		return ErrLengthRange
+	}
+
+	var hdr [18]byte
+	n := putNDNNumber(hdr[:], uint64(tag))
+	n += putNDNNumber(hdr[n:], uint64(length))
+	_, err := w.Write(hdr[:n])
+	return err
+}
+
+// readNDNNumber reads a VAR-NUMBER, rejecting encodings that are not the
+// shortest possible or that do not fit in an int.
+func readNDNNumber(br io.ByteReader) (n uint64, err error) {
+	b, err := br.ReadByte()
+	if err != nil {
+		return
+	} else if b < 253 {
+		return uint64(b), nil
+	}
+
+	size := 2 << (b - 253)
+	for i := 0; i < size; i++ {
+		if b, err = br.ReadByte(); err != nil {
+			return 0, io.ErrUnexpectedEOF
+		}
+		n = n<<8 | uint64(b)
+	}
+
+	if n > maxInt || n < 253 || (size > 2 && n>>uint(4*size) == 0) {
+		return 0, ErrLengthRange
+	}
+	return
+}
+
+func putNDNNumber(b []byte, n uint64) int {
+	switch {
+	case n < 253:
+		b[0] = byte(n)
+		return 1
+	case n <= 0xffff:
+		b[0] = 253
+		binary.BigEndian.PutUint16(b[1:], uint16(n))
+		return 3
+	case n <= 0xffffffff:
+		b[0] = 254
+		binary.BigEndian.PutUint32(b[1:], uint32(n))
+		return 5
+	default:
+		b[0] = 255
+		binary.BigEndian.PutUint64(b[1:], n)
+		return 9
+	}
+}
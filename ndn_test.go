@@ -0,0 +1,86 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// An NDN Interest for /ndn/test with a nonce and an InterestLifetime.
+var ndnInterest = []byte{
+	0x05, 0x17,
+	0x07, 0x0b,
+	0x08, 0x03, 'n', 'd', 'n',
+	0x08, 0x04, 't', 'e', 's', 't',
+	0x0a, 0x04, 0x01, 0x02, 0x03, 0x04,
+	0x0c, 0x02, 0x0f, 0xa0,
+}
+
+func TestNDN(t *testing.T) {
+	pkt := decodeFormat(t, "TestNDN", NDN, ndnInterest)
+	interest, err := pkt.Get(0x05)
+	if err != nil {
+		FailWithError(t, "TestNDN", err)
+	}
+
+	fields := decodeFormat(t, "TestNDN", NDN, interest.Value())
+	name, err := fields.Get(0x07)
+	if err != nil {
+		FailWithError(t, "TestNDN", err)
+	}
+	components := decodeFormat(t, "TestNDN", NDN, name.Value())
+	if tlvs := components.GetAll(0x08); len(tlvs) != 2 {
+		FailWithError(t, "TestNDN", noMatch)
+	} else if string(tlvs[1].Value()) != "test" {
+		FailWithError(t, "TestNDN", noMatch)
+	}
+
+	// Re-encode from the innermost level outwards.
+	nameValue := encodeFormat(t, "TestNDN", NDN, components)
+	rebuilt := New()
+	for _, tlv := range fields.records {
+		if tlv.Tag() == 0x07 {
+			rebuilt.Add(0x07, nameValue)
+		} else {
+			rebuilt.AddRecord(tlv)
+		}
+	}
+	out := New()
+	out.Add(0x05, encodeFormat(t, "TestNDN", NDN, rebuilt))
+	if !bytes.Equal(encodeFormat(t, "TestNDN", NDN, out), ndnInterest) {
+		FailWithError(t, "TestNDN",
+			fmt.Errorf("NDN packet did not round-trip"))
+	}
+}
+
+func TestNDNNumbers(t *testing.T) {
+	for _, u := range []uint64{0, 252, 253, 0xffff, 0x10000, 0xffffffff, 0x100000000} {
+		if u > maxInt {
+			continue
+		}
+		n := int(u)
+		buf := new(bytes.Buffer)
+		if err := NDN.WriteHeader(buf, n, n); err != nil {
+			FailWithError(t, "TestNDNNumbers", err)
+		}
+		tag, length, err := NDN.ReadHeader(buf)
+		if err != nil {
+			FailWithError(t, "TestNDNNumbers", err)
+		} else if tag != n || length != n {
+			FailWithError(t, "TestNDNNumbers",
+				fmt.Errorf("%d decoded as %d, %d", n, tag, length))
+		}
+	}
+
+	nonMinimal := [][]byte{
+		{0xfd, 0x00, 0x05, 0x00},
+		{0x05, 0xfe, 0x00, 0x00, 0x01, 0x00},
+	}
+	for _, raw := range nonMinimal {
+		_, _, err := NDN.ReadHeader(bytes.NewReader(raw))
+		if err != ErrTagRange && err != ErrLengthRange {
+			FailWithError(t, "TestNDNNumbers",
+				fmt.Errorf("non-minimal number %x accepted", raw))
+		}
+	}
+}
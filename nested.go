@@ -0,0 +1,157 @@
+package tlv
+
+import (
+	"bytes"
+	"io"
+)
+
+// Type Constructed is a record whose value is itself a list of records,
+// such as a BER constructed data object or a Matter structure. Its
+// Value is the encoding of its children in the Standard format; an
+// Encoder writes the children in its own format instead. Containers
+// whose value holds more than their children, such as RIFF chunks with
+// their form type, are the exception: their Value is their encoding in
+// their own format, and an Encoder writes it as is.
+type Constructed interface {
+	TLV
+	Children() *TLVList
+}
+
+// encodedConstructed is implemented by Constructed records whose Value
+// is already encoded in their own format, and must not be re-encoded
+// from their children.
+type encodedConstructed interface {
+	Constructed
+	encodedValue()
+}
+
+// maxNestDepth is the deepest a Decoder will decode nested records if
+// its MaxDepth is zero.
+const maxNestDepth = 32
+
+type nestedRecord struct {
+	tag      int
+	children *TLVList
+}
+
+// Method Tag returns the record's tag.
+func (rec *nestedRecord) Tag() int {
+	return rec.tag
+}
+
+// Method Length returns the length of the record's encoded children.
+// It is computed from the children's lengths, without encoding them.
+func (rec *nestedRecord) Length() int {
+	n := 0
+	for _, child := range rec.children.records {
+		n += headerLength + child.Length()
+	}
+	return n
+}
+
+// Method Value returns the record's children encoded in the Standard
+// format. It is encoded afresh on each call, so changes to the children
+// are always reflected. If a child cannot be encoded, such as one whose
+// tag is out of range, Value returns nil; writing the record, with an
+// Encoder or TLVList.Write, fails with the child's error.
+func (rec *nestedRecord) Value() []byte {
+	buf := bytes.NewBuffer(make([]byte, 0, rec.Length()))
+	if err := rec.writeChildren(buf); err != nil {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+// writeChildren writes the record's children to w in the Standard
+// format. Nested children are written in place by writeRecord, rather
+// than through their Value, so that each level of the tree is encoded
+// only once.
+func (rec *nestedRecord) writeChildren(w io.Writer) error {
+	for _, child := range rec.children.records {
+		if err := writeRecord(child, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Method Children returns the records the record holds. Changes to the
+// list change the record.
+func (rec *nestedRecord) Children() *TLVList {
+	return rec.children
+}
+
+// NewConstructed returns a record with the given tag holding children.
+// The list is not copied.
+func NewConstructed(tag int, children *TLVList) Constructed {
+	return &nestedRecord{tag: tag, children: children}
+}
+
+// AddList adds a constructed record holding children to the TLVList.
+// The list is not copied, so later changes to it are reflected in the
+// record.
+func (recs *TLVList) AddList(tag int, children *TLVList) {
+	recs.AddRecord(NewConstructed(tag, children))
+}
+
+// flatten encodes a constructed record's children in the Encoder's
+// format, returning a plain record holding them.
+func (enc *Encoder) flatten(rec Constructed) (TLV, error) {
+	buf := new(bytes.Buffer)
+	child := NewEncoder(buf)
+	child.Format = enc.Format
+	if err := child.EncodeList(rec.Children()); err != nil {
+		return nil, err
+	}
+	return &record{tag: rec.Tag(), length: buf.Len(), value: buf.Bytes()}, nil
+}
+
+// nest decodes the value of a record the Decoder's Nested function
+// selects into its children, recursively. Align is not inherited, as an
+// Encoder writes children unpadded. Records the allowlist sets aside
+// are added to the Decoder's collection.
+func (dec *Decoder) nest(rec TLV) (TLV, error) {
+	max := dec.MaxDepth
+	if max == 0 {
+		max = maxNestDepth
+	}
+	if dec.depth >= max {
+		return nil, ErrTooLarge
+	}
+
+	child := NewDecoder(bytes.NewReader(rec.Value()))
+	child.Format = dec.Format
+	child.MaxLength = dec.MaxLength
+	child.MaxDecompressed = dec.MaxDecompressed
+	child.MaxRecords = dec.MaxRecords
+	child.Order = dec.Order
+	child.Accept = dec.Accept
+	child.Compressed = dec.Compressed
+	child.Repair = dec.Repair
+	child.Allow = dec.Allow
+	child.Unknown = dec.Unknown
+	child.Legacy = dec.Legacy
+	child.Nested = dec.Nested
+	child.MaxDepth = dec.MaxDepth
+	child.depth = dec.depth + 1
+	children, err := child.DecodeList()
+	if err == ErrTLVRead || err == ErrLengthRange {
+		return nil, ErrTLVRead
+	} else if err != nil {
+		return nil, err
+	}
+	if child.unknown != nil {
+		if dec.unknown == nil {
+			dec.unknown = New()
+		}
+		dec.unknown.records = append(dec.unknown.records, child.unknown.records...)
+	}
+	return &nestedRecord{tag: rec.Tag(), children: children}, nil
+}
+
+// NestedBER reports whether a BER or DER record is constructed. It can
+// be used as a Decoder's Nested function to decode BER-TLV data, such as
+// EMV templates, into nested lists.
+func NestedBER(rec TLV) bool {
+	return berConstructed(rec.Tag())
+}
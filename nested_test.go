@@ -0,0 +1,191 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestNestedDecode(t *testing.T) {
+	dec := NewDecoder(bytes.NewReader(emvFCI))
+	dec.Format = BER
+	dec.Nested = NestedBER
+	fci, err := dec.DecodeList()
+	if err != nil {
+		FailWithError(t, "TestNestedDecode", err)
+	}
+
+	tmpl, err := fci.Get(0x6f)
+	if err != nil {
+		FailWithError(t, "TestNestedDecode", err)
+	}
+	c, ok := tmpl.(Constructed)
+	if !ok {
+		FailWithError(t, "TestNestedDecode",
+			fmt.Errorf("template was not decoded as constructed"))
+	}
+	prop, err := c.Children().Get(0xa5)
+	if err != nil {
+		FailWithError(t, "TestNestedDecode", err)
+	}
+	lang, err := prop.(Constructed).Children().Get(0x5f2d)
+	if err != nil {
+		FailWithError(t, "TestNestedDecode", err)
+	} else if string(lang.Value()) != "en" {
+		FailWithError(t, "TestNestedDecode", noMatch)
+	}
+
+	// Re-encoding the nested list reproduces the original bytes.
+	if out := encodeFormat(t, "TestNestedDecode", BER, fci); !bytes.Equal(out, emvFCI) {
+		FailWithError(t, "TestNestedDecode", noMatch)
+	}
+
+	dec = NewDecoder(bytes.NewReader(emvFCI))
+	dec.Format = BER
+	dec.Nested = NestedBER
+	dec.MaxDepth = 1
+	if _, err = dec.DecodeList(); err != ErrTooLarge {
+		FailWithError(t, "TestNestedDecode",
+			fmt.Errorf("expected ErrTooLarge, got %v", err))
+	}
+}
+
+func TestAddList(t *testing.T) {
+	inner := New()
+	inner.Add(TagTest2, []byte("foo"))
+
+	recs := New()
+	recs.AddList(TagTest1, inner)
+	recs.Add(TagTest3, []byte("bar"))
+
+	// Changes to the child list show up in the record.
+	inner.Add(TagTest2, []byte("baz"))
+	rec, _ := recs.Get(TagTest1)
+	if rec.Length() != 2*(headerLength+3) {
+		FailWithError(t, "TestAddList",
+			fmt.Errorf("length is %d", rec.Length()))
+	}
+
+	buf := new(bytes.Buffer)
+	if err := recs.Write(buf); err != nil {
+		FailWithError(t, "TestAddList", err)
+	}
+	dec := NewDecoder(buf)
+	dec.Nested = func(rec TLV) bool { return rec.Tag() == TagTest1 }
+	out, err := dec.DecodeList()
+	if err != nil {
+		FailWithError(t, "TestAddList", err)
+	}
+	rec, _ = out.Get(TagTest1)
+	if kids := rec.(Constructed).Children(); kids.Length() != 2 {
+		FailWithError(t, "TestAddList", noMatch)
+	} else if !CompareLists(kids, inner, CompareRules{}).Equal() {
+		FailWithError(t, "TestAddList", noMatch)
+	}
+}
+
+func TestNestedBadChild(t *testing.T) {
+	// A child whose length does not match its value, nested one
+	// level down.
+	inner := New()
+	inner.AddRecord(&record{tag: TagTest3, length: 5, value: []byte("foo")})
+	middle := New()
+	middle.AddList(TagTest2, inner)
+	rec := NewConstructed(TagTest1, middle)
+	if rec.Value() != nil {
+		FailWithError(t, "TestNestedBadChild", noMatch)
+	}
+
+	recs := New()
+	recs.AddRecord(rec)
+	if err := recs.Write(new(bytes.Buffer)); err != ErrTLVWrite {
+		FailWithError(t, "TestNestedBadChild",
+			fmt.Errorf("expected ErrTLVWrite, got %v", err))
+	}
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf).Encode(rec); err != ErrTLVWrite {
+		FailWithError(t, "TestNestedBadChild",
+			fmt.Errorf("expected ErrTLVWrite, got %v", err))
+	} else if buf.Len() != 0 {
+		FailWithError(t, "TestNestedBadChild",
+			fmt.Errorf("wrote %d bytes", buf.Len()))
+	}
+}
+
+func TestNestedDeep(t *testing.T) {
+	// Encoding must not revisit each subtree once per enclosing level,
+	// or this would never finish.
+	const depth = 64
+	recs := New()
+	recs.Add(TagTest2, []byte("leaf"))
+	for i := 0; i < depth; i++ {
+		outer := New()
+		outer.AddList(TagTest1, recs)
+		recs = outer
+	}
+
+	rec, _ := recs.Get(TagTest1)
+	if rec.Length() != depth*headerLength+4 {
+		FailWithError(t, "TestNestedDeep",
+			fmt.Errorf("length is %d", rec.Length()))
+	} else if len(rec.Value()) != rec.Length() {
+		FailWithError(t, "TestNestedDeep", noMatch)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := recs.Write(buf); err != nil {
+		FailWithError(t, "TestNestedDeep", err)
+	}
+	dec := NewDecoder(buf)
+	dec.Nested = func(rec TLV) bool { return rec.Tag() == TagTest1 }
+	dec.MaxDepth = depth
+	out, err := dec.DecodeList()
+	if err != nil {
+		FailWithError(t, "TestNestedDeep", err)
+	} else if !CompareLists(out, recs, CompareRules{}).Equal() {
+		FailWithError(t, "TestNestedDeep", noMatch)
+	}
+}
+
+func TestNestedConfig(t *testing.T) {
+	inner := New()
+	inner.Add(TagTest2, []byte("known"))
+	inner.Add(TagTest5, []byte("unknown"))
+	recs := New()
+	recs.AddList(TagTest1, inner)
+	buf := new(bytes.Buffer)
+	if err := recs.Write(buf); err != nil {
+		FailWithError(t, "TestNestedConfig", err)
+	}
+	raw := buf.Bytes()
+
+	// The allowlist applies to children as well.
+	dec := NewDecoder(bytes.NewReader(raw))
+	dec.Nested = func(rec TLV) bool { return rec.Tag() == TagTest1 }
+	dec.Allow = testRegistry()
+	if _, err := dec.DecodeList(); err != ErrUnknownTag {
+		FailWithError(t, "TestNestedConfig",
+			fmt.Errorf("expected ErrUnknownTag, got %v", err))
+	}
+
+	dec = NewDecoder(bytes.NewReader(raw))
+	dec.Nested = func(rec TLV) bool { return rec.Tag() == TagTest1 }
+	dec.Allow = testRegistry()
+	dec.Unknown = UnknownCollect
+	out, err := dec.DecodeList()
+	if err != nil {
+		FailWithError(t, "TestNestedConfig", err)
+	}
+	rec, _ := out.Get(TagTest1)
+	checkTags(t, "TestNestedConfig", rec.(Constructed).Children(), TagTest2)
+	checkTags(t, "TestNestedConfig", dec.Collected(), TagTest5)
+
+	// So does the tag order.
+	dec = NewDecoder(bytes.NewReader(raw))
+	dec.Nested = func(rec TLV) bool { return rec.Tag() == TagTest1 }
+	dec.Order = []int{TagTest5, TagTest2}
+	if _, err = dec.DecodeList(); err != ErrOrder {
+		FailWithError(t, "TestNestedConfig",
+			fmt.Errorf("expected ErrOrder, got %v", err))
+	}
+}
@@ -0,0 +1,128 @@
+package tlv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// ONIE is the TLV format of ONIE TlvInfo EEPROMs, which describe network
+// switch hardware: a one-byte type followed by a one-byte length. Tags
+// must be between 0 and 255, and values may be at most 255 bytes long.
+// A whole EEPROM, with its header and CRC, is read and written with
+// ReadONIE and WriteONIE.
+var ONIE Format = onieFormat{}
+
+// ErrONIE is returned when a TlvInfo EEPROM's header is malformed.
+var ErrONIE = fmt.Errorf("invalid ONIE TlvInfo EEPROM")
+
+// TlvInfo types defined by ONIE.
+const (
+	ONIEProductName     = 0x21
+	ONIEPartNumber      = 0x22
+	ONIESerialNumber    = 0x23
+	ONIEBaseMACAddress  = 0x24
+	ONIEManufactureDate = 0x25
+	ONIEDeviceVersion   = 0x26
+	ONIELabelRevision   = 0x27
+	ONIEPlatformName    = 0x28
+	ONIEVersion         = 0x29
+	ONIENumMACs         = 0x2a
+	ONIEManufacturer    = 0x2b
+	ONIECountryCode     = 0x2c
+	ONIEVendor          = 0x2d
+	ONIEDiagVersion     = 0x2e
+	ONIEServiceTag      = 0x2f
+	ONIEVendorExtension = 0xfd
+	ONIECRC32           = 0xfe
+)
+
+// onieMagic begins every TlvInfo EEPROM, followed by a version byte and
+// the 16-bit big-endian length of the TLVs.
+const (
+	onieMagic        = "TlvInfo\x00"
+	onieHeaderLength = len(onieMagic) + 3
+	onieVersion      = 0x01
+)
+
+type onieFormat struct{}
+
+func (onieFormat) ReadHeader(r io.Reader) (tag, length int, err error) {
+	var hdr [2]byte
+	if _, err = io.ReadFull(r, hdr[:]); err != nil {
+		return
+	}
+	return int(hdr[0]), int(hdr[1]), nil
+}
+
+func (onieFormat) WriteHeader(w io.Writer, tag, length int) error {
+	if tag < 0 || tag > 0xff {
+		return ErrTagRange
+	} else if length < 0 || length > 0xff {
+		return ErrLengthRange
+	}
+	_, err := w.Write([]byte{byte(tag), byte(length)})
+	return err
+}
+
+// ReadONIE decodes a TlvInfo EEPROM image, checking its header and its
+// CRC-32 record, which must be the last TLV and covers every byte before
+// its value. A missing or wrong CRC fails with ErrCorrupt. The CRC
+// record is included in the list returned. Bytes after the TLVs, such as
+// unused EEPROM space, are ignored.
+func ReadONIE(eeprom []byte) (*TLVList, error) {
+	if len(eeprom) < onieHeaderLength || string(eeprom[:len(onieMagic)]) != onieMagic {
+		return nil, ErrONIE
+	} else if eeprom[len(onieMagic)] != onieVersion {
+		return nil, ErrONIE
+	}
+
+	total := onieHeaderLength + int(binary.BigEndian.Uint16(eeprom[len(onieMagic)+1:]))
+	if total > len(eeprom) {
+		return nil, ErrTLVRead
+	}
+	dec := NewDecoder(bytes.NewReader(eeprom[onieHeaderLength:total]))
+	dec.Format = ONIE
+	recs, err := dec.DecodeList()
+	if err != nil {
+		return nil, err
+	}
+
+	n := recs.Length()
+	if n == 0 || recs.records[n-1].Tag() != ONIECRC32 || recs.records[n-1].Length() != 4 {
+		return nil, ErrCorrupt
+	}
+	crc := crc32.ChecksumIEEE(eeprom[:total-4])
+	if crc != binary.BigEndian.Uint32(recs.records[n-1].Value()) {
+		return nil, ErrCorrupt
+	}
+	return recs, nil
+}
+
+// WriteONIE encodes recs as a TlvInfo EEPROM image. Any CRC-32 records in
+// recs are dropped, and a new one, computed over the image, is written
+// as the last TLV.
+func WriteONIE(recs *TLVList) ([]byte, error) {
+	buf := bytes.NewBufferString(onieMagic)
+	buf.Write([]byte{onieVersion, 0, 0})
+
+	enc := NewEncoder(buf)
+	enc.Format = ONIE
+	for _, tlv := range recs.records {
+		if tlv.Tag() != ONIECRC32 {
+			if err := enc.Encode(tlv); err != nil {
+				return nil, err
+			}
+		}
+	}
+	buf.Write([]byte{ONIECRC32, 4})
+
+	image := buf.Bytes()
+	if len(image)+4-onieHeaderLength > 0xffff {
+		return nil, ErrLengthRange
+	}
+	binary.BigEndian.PutUint16(image[len(onieMagic)+1:], uint16(len(image)+4-onieHeaderLength))
+	return binary.BigEndian.AppendUint32(image, crc32.ChecksumIEEE(image)), nil
+}
@@ -0,0 +1,41 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestONIE(t *testing.T) {
+	recs := New()
+	recs.Add(ONIEProductName, []byte("S4048-ON"))
+	recs.Add(ONIEBaseMACAddress, []byte{0x00, 0x01, 0xe8, 0x8b, 0x52, 0x00})
+	recs.Add(ONIENumMACs, []byte{0x00, 0x80})
+	recs.Add(ONIECRC32, []byte{0, 0, 0, 0}) // stale, replaced on write
+
+	image, err := WriteONIE(recs)
+	if err != nil {
+		FailWithError(t, "TestONIE", err)
+	}
+	if !bytes.HasPrefix(image, []byte("TlvInfo\x00\x01\x00\x1c")) {
+		FailWithError(t, "TestONIE",
+			fmt.Errorf("header is % x", image[:11]))
+	}
+
+	// Unused EEPROM space reads as 0xff.
+	eeprom := append(image, bytes.Repeat([]byte{0xff}, 32)...)
+	out, err := ReadONIE(eeprom)
+	if err != nil {
+		FailWithError(t, "TestONIE", err)
+	} else if out.Length() != 4 {
+		FailWithError(t, "TestONIE", noMatch)
+	} else if name, err := out.Get(ONIEProductName); err != nil || string(name.Value()) != "S4048-ON" {
+		FailWithError(t, "TestONIE", noMatch)
+	}
+
+	eeprom[13] ^= 1
+	if _, err = ReadONIE(eeprom); err != ErrCorrupt {
+		FailWithError(t, "TestONIE",
+			fmt.Errorf("expected ErrCorrupt, got %v", err))
+	}
+}
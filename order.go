@@ -0,0 +1,45 @@
+package tlv
+
+import "fmt"
+
+// ErrOrder is returned by a Decoder when a record appears out of the
+// order it was configured to enforce.
+var ErrOrder = fmt.Errorf("TLV record out of order")
+
+// CompareOrder compares the sequence of tags in two lists. It returns
+// the index of the first position at which the tags differ, or -1 if the
+// lists hold the same tags in the same order. If one list is a prefix of
+// the other, the length of the shorter list is returned.
+func CompareOrder(a, b *TLVList) int {
+	var i int
+	for ; i < a.Length() && i < b.Length(); i++ {
+		if a.records[i].Tag() != b.records[i].Tag() {
+			return i
+		}
+	}
+
+	if a.Length() != b.Length() {
+		return i
+	}
+	return -1
+}
+
+// checkOrder verifies that a decoded tag does not precede the tags that
+// have already been decoded in the Decoder's Order.
+func (dec *Decoder) checkOrder(tag int) error {
+	if dec.orderIndex == nil {
+		dec.orderIndex = make(map[int]int, len(dec.Order))
+		for i, tag := range dec.Order {
+			dec.orderIndex[tag] = i
+		}
+	}
+
+	i, ok := dec.orderIndex[tag]
+	if !ok {
+		return nil
+	} else if i < dec.orderPos {
+		return ErrOrder
+	}
+	dec.orderPos = i
+	return nil
+}
@@ -0,0 +1,104 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func orderTestList() *TLVList {
+	tlvl := New()
+	tlvl.Add(TagTest3, []byte("gophers are everywhere!"))
+	tlvl.Add(TagTest1, []byte("foo bar"))
+	tlvl.Add(TagTest2, []byte("baz quux"))
+	tlvl.Add(TagTest1, []byte("goodbye, cruel world"))
+	return tlvl
+}
+
+func TestOrderPreserved(t *testing.T) {
+	tlvl := orderTestList()
+
+	buf := new(bytes.Buffer)
+	if err := tlvl.Write(buf); err != nil {
+		FailWithError(t, "TestOrderPreserved", err)
+	}
+
+	exts, err := Extents(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		FailWithError(t, "TestOrderPreserved", err)
+	}
+	i := 0
+	for _, tlv := range tlvl.records {
+		if exts[i].Tag != tlv.Tag() {
+			FailWithError(t, "TestOrderPreserved",
+				fmt.Errorf("record %d written out of order", i))
+		}
+		i++
+	}
+
+	rtlvl, err := Read(buf)
+	if err != nil {
+		FailWithError(t, "TestOrderPreserved", err)
+	} else if n := CompareOrder(tlvl, rtlvl); n != -1 {
+		FailWithError(t, "TestOrderPreserved",
+			fmt.Errorf("record %d read out of order", n))
+	}
+
+	tlvs := rtlvl.GetAll(TagTest1)
+	if string(tlvs[0].Value()) != "foo bar" {
+		FailWithError(t, "TestOrderPreserved",
+			fmt.Errorf("duplicate tags reordered"))
+	}
+}
+
+func TestCompareOrder(t *testing.T) {
+	a := orderTestList()
+	b := orderTestList()
+	if n := CompareOrder(a, b); n != -1 {
+		FailWithError(t, "TestCompareOrder",
+			fmt.Errorf("identical lists differ at %d", n))
+	}
+
+	b.Add(TagTest4, nil)
+	if n := CompareOrder(a, b); n != 4 {
+		FailWithError(t, "TestCompareOrder",
+			fmt.Errorf("prefix differs at %d, expected 4", n))
+	}
+
+	b.Remove(TagTest2)
+	if n := CompareOrder(a, b); n != 2 {
+		FailWithError(t, "TestCompareOrder",
+			fmt.Errorf("lists differ at %d, expected 2", n))
+	}
+}
+
+func TestDecoderOrder(t *testing.T) {
+	tlvl := orderTestList()
+	tlvl.Add(TagTest6, nil)
+
+	buf := new(bytes.Buffer)
+	if err := tlvl.Write(buf); err != nil {
+		FailWithError(t, "TestDecoderOrder", err)
+	}
+	raw := buf.Bytes()
+
+	dec := NewDecoder(bytes.NewReader(raw))
+	dec.Order = []int{TagTest3, TagTest1, TagTest2}
+	if _, err := dec.DecodeList(); err != ErrOrder {
+		FailWithError(t, "TestDecoderOrder",
+			fmt.Errorf("out of order record should be rejected"))
+	}
+
+	dec = NewDecoder(bytes.NewReader(raw))
+	dec.Order = []int{TagTest3, TagTest2, TagTest1}
+	if _, err := dec.DecodeList(); err != ErrOrder {
+		FailWithError(t, "TestDecoderOrder",
+			fmt.Errorf("out of order record should be rejected"))
+	}
+
+	dec = NewDecoder(bytes.NewReader(raw))
+	dec.Order = []int{TagTest4, TagTest3, TagTest1}
+	if _, err := dec.DecodeList(); err != nil {
+		FailWithError(t, "TestDecoderOrder", err)
+	}
+}
@@ -0,0 +1,100 @@
+package tlv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrPath is returned by ParsePath when a path is malformed.
+var ErrPath = fmt.Errorf("invalid TLV path")
+
+// ParsePath parses a slash-separated path of tags, such as
+// "0x6F/0xA5/0x50", for use with GetPath. Tags may be decimal or, with a
+// 0x prefix, hexadecimal.
+func ParsePath(path string) ([]int, error) {
+	parts := strings.Split(path, "/")
+	tags := make([]int, len(parts))
+	for i, part := range parts {
+		tag, err := strconv.ParseInt(strings.TrimSpace(part), 0, 64)
+		if err != nil || int64(int(tag)) != tag {
+			return nil, ErrPath
+		}
+		tags[i] = int(tag)
+	}
+	return tags, nil
+}
+
+// GetPath returns the first record, in depth-first order, reached by
+// following tags through nested records: the first tag is matched in
+// recs, the second among the children of the constructed records
+// matched by the first, and so on. If no record is found,
+// ErrTagNotFound is returned. Records that are not Constructed are not
+// descended into.
+func (recs *TLVList) GetPath(tags ...int) (TLV, error) {
+	return QueryLimits{}.GetPath(recs, tags...)
+}
+
+// GetAllPath returns every record reached by following tags through
+// nested records, in depth-first order. If no record is found, an empty
+// slice is returned.
+func (recs *TLVList) GetAllPath(tags ...int) []TLV {
+	found, _ := QueryLimits{}.GetAllPath(recs, tags...)
+	return found
+}
+
+// GetPath is TLVList.GetPath within the limits, for queries over
+// untrusted data; if they are exceeded, ErrQueryLimit is returned.
+func (limits QueryLimits) GetPath(recs *TLVList, tags ...int) (TLV, error) {
+	limits.MaxResult = 1
+	budget := &queryBudget{limits: limits}
+	var found []TLV
+	if err := getPath(recs, tags, budget, &found, true); err != nil {
+		return nil, err
+	} else if len(found) == 0 {
+		return nil, ErrTagNotFound
+	}
+	return found[0], nil
+}
+
+// GetAllPath is TLVList.GetAllPath within the limits, for queries over
+// untrusted data; if they are exceeded, ErrQueryLimit is returned along
+// with the records found so far.
+func (limits QueryLimits) GetAllPath(recs *TLVList, tags ...int) ([]TLV, error) {
+	budget := &queryBudget{limits: limits}
+	found := make([]TLV, 0)
+	err := getPath(recs, tags, budget, &found, false)
+	return found, err
+}
+
+// getPath appends the records reached by tags from recs to found. If
+// first is set, it stops at the first record found.
+func getPath(recs *TLVList, tags []int, budget *queryBudget, found *[]TLV, first bool) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	for _, tlv := range recs.records {
+		if err := budget.visit(1); err != nil {
+			return err
+		}
+		if tlv.Tag() != tags[0] {
+			continue
+		}
+
+		if len(tags) == 1 {
+			if err := budget.result(); err != nil {
+				return err
+			}
+			*found = append(*found, tlv)
+		} else if c, ok := tlv.(Constructed); ok {
+			if err := getPath(c.Children(), tags[1:], budget, found, first); err != nil {
+				return err
+			}
+		}
+		if first && len(*found) > 0 {
+			return nil
+		}
+	}
+	return nil
+}
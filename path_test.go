@@ -0,0 +1,73 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func nestedFCI(t *testing.T) *TLVList {
+	dec := NewDecoder(bytes.NewReader(emvFCI))
+	dec.Format = BER
+	dec.Nested = NestedBER
+	fci, err := dec.DecodeList()
+	if err != nil {
+		FailWithError(t, "nestedFCI", err)
+	}
+	return fci
+}
+
+func TestGetPath(t *testing.T) {
+	fci := nestedFCI(t)
+
+	path, err := ParsePath("0x6F/0xA5/0x5F2D")
+	if err != nil {
+		FailWithError(t, "TestGetPath", err)
+	}
+	lang, err := fci.GetPath(path...)
+	if err != nil {
+		FailWithError(t, "TestGetPath", err)
+	} else if string(lang.Value()) != "en" {
+		FailWithError(t, "TestGetPath", noMatch)
+	}
+
+	if _, err = fci.GetPath(0x6f, 0x84, 0x50); err != ErrTagNotFound {
+		FailWithError(t, "TestGetPath",
+			fmt.Errorf("expected ErrTagNotFound, got %v", err))
+	}
+	if _, err = ParsePath("0x6F//0x50"); err != ErrPath {
+		FailWithError(t, "TestGetPath",
+			fmt.Errorf("expected ErrPath, got %v", err))
+	}
+}
+
+func TestGetAllPath(t *testing.T) {
+	inner := New()
+	inner.Add(TagTest3, []byte("a"))
+	inner.Add(TagTest3, []byte("b"))
+	recs := New()
+	recs.AddList(TagTest1, inner)
+	recs.AddList(TagTest1, inner)
+
+	if found := recs.GetAllPath(TagTest1, TagTest3); len(found) != 4 {
+		FailWithError(t, "TestGetAllPath",
+			fmt.Errorf("found %d records, expected 4", len(found)))
+	}
+	if found := recs.GetAllPath(TagTest2); len(found) != 0 {
+		FailWithError(t, "TestGetAllPath", noMatch)
+	}
+
+	limits := QueryLimits{MaxResult: 3}
+	if found, err := limits.GetAllPath(recs, TagTest1, TagTest3); err != ErrQueryLimit {
+		FailWithError(t, "TestGetAllPath",
+			fmt.Errorf("expected ErrQueryLimit, got %v", err))
+	} else if len(found) != 3 {
+		FailWithError(t, "TestGetAllPath", noMatch)
+	}
+
+	limits = QueryLimits{MaxVisited: 3}
+	if _, err := limits.GetPath(recs, TagTest1, TagTest2); err != ErrQueryLimit {
+		FailWithError(t, "TestGetAllPath",
+			fmt.Errorf("expected ErrQueryLimit, got %v", err))
+	}
+}
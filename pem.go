@@ -0,0 +1,121 @@
+package tlv
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/pem"
+	"io"
+	"strconv"
+)
+
+// PEMType is the type of the PEM blocks holding TLV lists.
+const PEMType = "TLV LIST"
+
+// PEMChecksum is the header holding the checksum of a PEM block's
+// contents, in hex. PEMChecksumAlgorithm is the header holding the
+// HashID of the algorithm used for the checksum, in decimal; if it is
+// absent, CRC-32C is used.
+const (
+	PEMChecksum          = "Checksum"
+	PEMChecksumAlgorithm = "Checksum-Algorithm"
+)
+
+// Type PEMBlock is a TLV list read from a PEM block, along with the
+// block's headers, such as a version or profile name.
+type PEMBlock struct {
+	Headers map[string]string
+	List    *TLVList
+}
+
+// pemChecksum returns the checksum of data, in hex, computed with the
+// algorithm given by the PEMChecksumAlgorithm header, along with the
+// algorithm's HashID. If names is set, the header may also give the
+// algorithm's registered name.
+func pemChecksum(headers map[string]string, data []byte, names bool) (id HashID, sum string, err error) {
+	id = HashCRC32C
+	if value, ok := headers[PEMChecksumAlgorithm]; ok {
+		if n, perr := strconv.ParseUint(value, 10, 8); perr == nil {
+			id = HashID(n)
+		} else if id, ok = LookupHash(value); !ok || !names {
+			return 0, "", ErrUnknownHash
+		}
+	}
+
+	h, err := NewHash(id)
+	if err != nil {
+		return 0, "", err
+	}
+	h.Write(data)
+	return id, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// WritePEM writes the TLVList to w as a PEM block of type PEMType, with
+// the given headers. A PEMChecksum header is added, overriding any in
+// headers, so the contents can be verified when read back. It is
+// computed with the algorithm given in the PEMChecksumAlgorithm header,
+// if there is one, by HashID or by registered name; the header is
+// written with the HashID.
+func WritePEM(w io.Writer, recs *TLVList, headers map[string]string) error {
+	buf := new(bytes.Buffer)
+	if err := recs.Write(buf); err != nil {
+		return err
+	}
+
+	block := &pem.Block{Type: PEMType, Headers: make(map[string]string), Bytes: buf.Bytes()}
+	for k, v := range headers {
+		block.Headers[k] = v
+	}
+	id, sum, err := pemChecksum(block.Headers, block.Bytes, true)
+	if err != nil {
+		return err
+	}
+	if _, ok := block.Headers[PEMChecksumAlgorithm]; ok {
+		block.Headers[PEMChecksumAlgorithm] = strconv.Itoa(int(id))
+	}
+	block.Headers[PEMChecksum] = sum
+	return pem.Encode(w, block)
+}
+
+// DecodePEM finds the next PEM block of type PEMType in data, skipping
+// blocks of other types, and returns it and the rest of the data. If no
+// such block is found, block is nil and rest is the whole of data. If
+// the block has a PEMChecksum header that does not match its contents,
+// ErrCorrupt is returned.
+func DecodePEM(data []byte) (block *PEMBlock, rest []byte, err error) {
+	rest = data
+	for {
+		var p *pem.Block
+		if p, rest = pem.Decode(rest); p == nil {
+			return nil, data, nil
+		} else if p.Type != PEMType {
+			continue
+		}
+
+		if sum, ok := p.Headers[PEMChecksum]; ok {
+			_, want, err := pemChecksum(p.Headers, p.Bytes, false)
+			if err != nil {
+				return nil, rest, err
+			} else if sum != want {
+				return nil, rest, ErrCorrupt
+			}
+		}
+		recs, err := NewDecoder(bytes.NewReader(p.Bytes)).DecodeList()
+		if err != nil {
+			return nil, rest, err
+		}
+		return &PEMBlock{Headers: p.Headers, List: recs}, rest, nil
+	}
+}
+
+// DecodeAllPEM returns every PEM block of type PEMType in data, in order.
+func DecodeAllPEM(data []byte) (blocks []*PEMBlock, err error) {
+	for {
+		var block *PEMBlock
+		if block, data, err = DecodePEM(data); err != nil {
+			return nil, err
+		} else if block == nil {
+			return
+		}
+		blocks = append(blocks, block)
+	}
+}
@@ -0,0 +1,54 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPEM(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(TagTest1, []byte("copy me"))
+	tlvl.Add(TagTest2, make([]byte, 64))
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("Some notes from the ops runbook.\n")
+	if err := WritePEM(buf, tlvl, map[string]string{"Version": "1", "Profile": "test"}); err != nil {
+		FailWithError(t, "TestPEM", err)
+	}
+	buf.WriteString("-----BEGIN OTHER-----\nAAAA\n-----END OTHER-----\n")
+	if err := WritePEM(buf, New(), nil); err != nil {
+		FailWithError(t, "TestPEM", err)
+	}
+	text := buf.String()
+	if !strings.Contains(text, "-----BEGIN TLV LIST-----") {
+		FailWithError(t, "TestPEM",
+			fmt.Errorf("missing PEM boundary:\n%s", text))
+	}
+
+	blocks, err := DecodeAllPEM(buf.Bytes())
+	if err != nil {
+		FailWithError(t, "TestPEM", err)
+	} else if len(blocks) != 2 {
+		FailWithError(t, "TestPEM",
+			fmt.Errorf("expected 2 blocks, got %d", len(blocks)))
+	}
+	if blocks[0].Headers["Profile"] != "test" || blocks[0].Headers["Version"] != "1" {
+		FailWithError(t, "TestPEM",
+			fmt.Errorf("headers were not preserved"))
+	} else if CompareOrder(tlvl, blocks[0].List) != -1 || blocks[1].List.Length() != 0 {
+		FailWithError(t, "TestPEM", noMatch)
+	}
+
+	// Corrupt the body of the first block.
+	i := strings.Index(text, "\n\n") + 2
+	corrupt := text[:i] + "B" + text[i+1:]
+	if text[i] == 'B' {
+		corrupt = text[:i] + "C" + text[i+1:]
+	}
+	if _, _, err = DecodePEM([]byte(corrupt)); err != ErrCorrupt {
+		FailWithError(t, "TestPEM",
+			fmt.Errorf("expected ErrCorrupt, got %v", err))
+	}
+}
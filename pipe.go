@@ -0,0 +1,113 @@
+package tlv
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// NewPipe returns a connected Decoder and Encoder: records encoded on
+// the Encoder can be decoded from the Decoder. At most size bytes are
+// buffered between the two; once the buffer is full, Encode blocks until
+// the Decoder has caught up. Closing the Encoder causes the Decoder to
+// return io.EOF once it has read everything written, and closing the
+// Decoder causes further writes to fail with io.ErrClosedPipe.
+//
+// Both ends are safe to use from different goroutines, but each end
+// should only be used by one goroutine at a time.
+func NewPipe(size int) (*Decoder, *Encoder) {
+	if size < 1 {
+		size = 1
+	}
+	p := &pipe{size: size}
+	p.cond = sync.NewCond(&p.mu)
+
+	dec := NewDecoder(pipeReader{p})
+	dec.closer = pipeReader{p}
+	enc := NewEncoder(pipeWriter{p})
+	enc.closer = pipeWriter{p}
+	return dec, enc
+}
+
+type pipe struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	buf  bytes.Buffer
+	size int
+
+	wclosed bool
+	rclosed bool
+}
+
+func (p *pipe) read(b []byte) (n int, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for p.buf.Len() == 0 {
+		if p.rclosed {
+			return 0, io.ErrClosedPipe
+		} else if p.wclosed {
+			return 0, io.EOF
+		}
+		p.cond.Wait()
+	}
+	n, err = p.buf.Read(b)
+	p.cond.Broadcast()
+	return
+}
+
+func (p *pipe) write(b []byte) (n int, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for len(b) > 0 {
+		if p.rclosed || p.wclosed {
+			return n, io.ErrClosedPipe
+		}
+
+		space := p.size - p.buf.Len()
+		if space == 0 {
+			p.cond.Wait()
+			continue
+		} else if space > len(b) {
+			space = len(b)
+		}
+		p.buf.Write(b[:space])
+		b = b[space:]
+		n += space
+		p.cond.Broadcast()
+	}
+	return
+}
+
+type pipeReader struct {
+	p *pipe
+}
+
+func (pr pipeReader) Read(b []byte) (int, error) {
+	return pr.p.read(b)
+}
+
+func (pr pipeReader) Close() error {
+	pr.p.mu.Lock()
+	pr.p.rclosed = true
+	pr.p.cond.Broadcast()
+	pr.p.mu.Unlock()
+	return nil
+}
+
+type pipeWriter struct {
+	p *pipe
+}
+
+func (pw pipeWriter) Write(b []byte) (int, error) {
+	return pw.p.write(b)
+}
+
+func (pw pipeWriter) Close() error {
+	pw.p.mu.Lock()
+	pw.p.wclosed = true
+	pw.p.cond.Broadcast()
+	pw.p.mu.Unlock()
+	return nil
+}
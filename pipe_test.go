@@ -0,0 +1,64 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestPipe(t *testing.T) {
+	dec, enc := NewPipe(16)
+
+	errs := make(chan error, 1)
+	go func() {
+		for i := 0; i < 64; i++ {
+			err := enc.Encode(newTLV(i, bytes.Repeat([]byte{byte(i)}, i)))
+			if err != nil {
+				errs <- err
+				return
+			}
+		}
+		errs <- enc.Close()
+	}()
+
+	var n int
+	for {
+		rec, err := dec.Decode()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			FailWithError(t, "TestPipe", err)
+		} else if !Equals(rec, newTLV(n, bytes.Repeat([]byte{byte(n)}, n))) {
+			FailWithError(t, "TestPipe", noMatch)
+		}
+		n++
+	}
+
+	if err := <-errs; err != nil {
+		FailWithError(t, "TestPipe", err)
+	} else if n != 64 {
+		FailWithError(t, "TestPipe",
+			fmt.Errorf("expected 64 records, got %d", n))
+	}
+}
+
+func TestPipeReaderClosed(t *testing.T) {
+	dec, enc := NewPipe(16)
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- enc.Encode(newTLV(TagTest1, make([]byte, 64)))
+	}()
+
+	if _, err := dec.Decode(); err != nil {
+		FailWithError(t, "TestPipeReaderClosed", err)
+	} else if err = <-errs; err != nil {
+		FailWithError(t, "TestPipeReaderClosed", err)
+	}
+	dec.Close()
+	if err := enc.Encode(newTLV(TagTest1, nil)); err != io.ErrClosedPipe {
+		FailWithError(t, "TestPipeReaderClosed",
+			fmt.Errorf("write to closed pipe should fail"))
+	}
+}
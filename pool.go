@@ -0,0 +1,51 @@
+package tlv
+
+import (
+	"io"
+	"sync"
+)
+
+// Type DecoderPool hands out Decoders for short-lived streams, such as
+// the connections accepted by a server, and takes them back when the
+// stream is done. Reusing a Decoder keeps its reader state and the index
+// built for its Order, so a busy server does not rebuild them for every
+// connection.
+//
+// A DecoderPool is safe for use from multiple goroutines. It must not be
+// copied after first use.
+type DecoderPool struct {
+	// Configure, if set, is called once for each Decoder the pool
+	// creates, to set its Format, limits, Order and other fields.
+	// Every Decoder from the pool is configured the same way; a
+	// Decoder that is reconfigured after Get must not be returned to
+	// the pool.
+	Configure func(dec *Decoder)
+
+	pool sync.Pool
+}
+
+// Get returns a configured Decoder that reads from r, reusing one
+// returned to the pool if possible.
+func (p *DecoderPool) Get(r io.Reader) *Decoder {
+	if dec, ok := p.pool.Get().(*Decoder); ok {
+		dec.Reset(r)
+		return dec
+	}
+
+	dec := NewDecoder(r)
+	if p.Configure != nil {
+		p.Configure(dec)
+	}
+	return dec
+}
+
+// Put returns dec to the pool. The Decoder must not be used afterwards.
+// Put does not close the Decoder's reader; the reference to it is
+// dropped so the pool does not keep the stream alive.
+func (p *DecoderPool) Put(dec *Decoder) {
+	if dec == nil {
+		return
+	}
+	dec.Reset(nil)
+	p.pool.Put(dec)
+}
@@ -0,0 +1,56 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestDecoderPool(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(TagTest1, []byte("foo"))
+	tlvl.Add(TagTest2, []byte("bar"))
+
+	buf := new(bytes.Buffer)
+	if err := tlvl.Write(buf); err != nil {
+		FailWithError(t, "TestDecoderPool", err)
+	}
+	raw := buf.Bytes()
+
+	var configured int
+	pool := &DecoderPool{Configure: func(dec *Decoder) {
+		configured++
+		dec.Order = []int{TagTest1, TagTest2}
+	}}
+
+	// Each stream starts over: the offset and the position in Order
+	// must not carry over from the previous one.
+	for i := 0; i < 3; i++ {
+		dec := pool.Get(bytes.NewReader(raw))
+		if len(dec.Order) != 2 {
+			FailWithError(t, "TestDecoderPool",
+				fmt.Errorf("decoder lost its configuration"))
+		}
+		for j := 0; j < 2; j++ {
+			if _, err := dec.Decode(); err != nil {
+				FailWithError(t, "TestDecoderPool", err)
+			}
+		}
+		if dec.Offset() != int64(len(raw)) {
+			FailWithError(t, "TestDecoderPool",
+				fmt.Errorf("decoder offset is %d, expected %d",
+					dec.Offset(), len(raw)))
+		}
+		if _, err := dec.Decode(); err != io.EOF {
+			FailWithError(t, "TestDecoderPool",
+				fmt.Errorf("expected EOF, got %v", err))
+		}
+		pool.Put(dec)
+	}
+
+	if configured < 1 {
+		FailWithError(t, "TestDecoderPool",
+			fmt.Errorf("Configure was never called"))
+	}
+}
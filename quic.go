@@ -0,0 +1,138 @@
+package tlv
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// QUIC is the format of QUIC transport parameters (RFC 9000, section
+// 18): a parameter ID and a length, each a QUIC variable-length integer.
+// Transport parameters are carried in the TLS extension
+// TLSQUICTransportParameters, whose value is a sequence of parameters in
+// this format. Tags and lengths are written in their shortest encoding.
+// Tags may be as large as 2^62-1 where int is 64 bits; on 32-bit
+// platforms, parameters with IDs that do not fit in an int, such as
+// large greased IDs, are rejected with ErrTagRange. QUIC variable-length integers give
+// their size in a two-bit prefix, rather than a continuation bit in each
+// byte as the LEB128 varints of the Varint format do, so the two formats
+// are not interchangeable.
+var QUIC Format = quicFormat{}
+
+// TLSQUICTransportParameters is the TLS extension type carrying QUIC
+// transport parameters.
+const TLSQUICTransportParameters = 0x39
+
+// Some of the transport parameters defined by RFC 9000. Most hold a
+// single variable-length integer, which can be read with
+// ParseQUICVarint.
+const (
+	QUICOriginalDestinationConnectionID = 0x00
+	QUICMaxIdleTimeout                  = 0x01
+	QUICStatelessResetToken             = 0x02
+	QUICMaxUDPPayloadSize               = 0x03
+	QUICInitialMaxData                  = 0x04
+	QUICInitialMaxStreamDataBidiLocal   = 0x05
+	QUICInitialMaxStreamDataBidiRemote  = 0x06
+	QUICInitialMaxStreamDataUni         = 0x07
+	QUICInitialMaxStreamsBidi           = 0x08
+	QUICInitialMaxStreamsUni            = 0x09
+	QUICAckDelayExponent                = 0x0a
+	QUICMaxAckDelay                     = 0x0b
+	QUICDisableActiveMigration          = 0x0c
+	QUICPreferredAddress                = 0x0d
+	QUICActiveConnectionIDLimit         = 0x0e
+	QUICInitialSourceConnectionID       = 0x0f
+	QUICRetrySourceConnectionID         = 0x10
+)
+
+// maxQUICVarint is the largest value a QUIC variable-length integer can
+// hold.
+const maxQUICVarint = 1<<62 - 1
+
+type quicFormat struct{}
+
+// readQUICVarint reads a variable-length integer, whose length is given
+// by the two high bits of its first byte.
+func readQUICVarint(br io.ByteReader) (v uint64, err error) {
+	b, err := br.ReadByte()
+	if err != nil {
+		return
+	}
+	n := 1 << (b >> 6)
+	v = uint64(b & 0x3f)
+	for i := 1; i < n; i++ {
+		if b, err = br.ReadByte(); err != nil {
+			return 0, io.ErrUnexpectedEOF
+		}
+		v = v<<8 | uint64(b)
+	}
+	return
+}
+
+// AppendQUICVarint appends v to b as a QUIC variable-length integer in
+// its shortest encoding. ErrLengthRange is returned if v is larger than
+// 2^62-1.
+func AppendQUICVarint(b []byte, v uint64) ([]byte, error) {
+	switch {
+	case v < 1<<6:
+		return append(b, byte(v)), nil
+	case v < 1<<14:
+		return append(b, 0x40|byte(v>>8), byte(v)), nil
+	case v < 1<<30:
+		return binary.BigEndian.AppendUint32(b, 0x80<<24|uint32(v)), nil
+	case v <= maxQUICVarint:
+		return binary.BigEndian.AppendUint64(b, 0xc0<<56|v), nil
+	}
+	return b, ErrLengthRange
+}
+
+// ParseQUICVarint parses the QUIC variable-length integer at the start
+// of b, returning its value and its length in bytes. It is used to read
+// the values of integer transport parameters. If b is too short,
+// io.ErrUnexpectedEOF is returned.
+func ParseQUICVarint(b []byte) (v uint64, n int, err error) {
+	if len(b) == 0 {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	n = 1 << (b[0] >> 6)
+	if len(b) < n {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	v = uint64(b[0] & 0x3f)
+	for _, c := range b[1:n] {
+		v = v<<8 | uint64(c)
+	}
+	return
+}
+
+func (quicFormat) ReadHeader(r io.Reader) (tag, length int, err error) {
+	br := asByteReader(r)
+	id, err := readQUICVarint(br)
+	if err != nil {
+		return
+	} else if id > maxInt {
+		return 0, 0, ErrTagRange
+	}
+	n, err := readQUICVarint(br)
+	if err == io.EOF {
+		return 0, 0, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return
+	} else if n > maxChunk {
+		return 0, 0, ErrLengthRange
+	}
+	return int(id), int(n), nil
+}
+
+func (quicFormat) WriteHeader(w io.Writer, tag, length int) error {
+	if tag < 0 || uint64(tag) > maxQUICVarint {
+		return ErrTagRange
+	} else if length < 0 || length > maxChunk {
+		return ErrLengthRange
+	}
+
+	hdr, _ := AppendQUICVarint(make([]byte, 0, 16), uint64(tag))
+	hdr, _ = AppendQUICVarint(hdr, uint64(length))
+	_, err := w.Write(hdr)
+	return err
+}
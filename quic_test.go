@@ -0,0 +1,86 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+func TestQUICVarint(t *testing.T) {
+	// The examples from RFC 9000, appendix A.1.
+	var tests = []struct {
+		v   uint64
+		enc []byte
+	}{
+		{151288809941952652, []byte{0xc2, 0x19, 0x7c, 0x5e, 0xff, 0x14, 0xe8, 0x8c}},
+		{494878333, []byte{0x9d, 0x7f, 0x3e, 0x7d}},
+		{15293, []byte{0x7b, 0xbd}},
+		{37, []byte{0x25}},
+	}
+
+	for _, tt := range tests {
+		enc, err := AppendQUICVarint(nil, tt.v)
+		if err != nil {
+			FailWithError(t, "TestQUICVarint", err)
+		} else if !bytes.Equal(enc, tt.enc) {
+			FailWithError(t, "TestQUICVarint",
+				fmt.Errorf("%d encoded as % x", tt.v, enc))
+		}
+		if v, n, err := ParseQUICVarint(tt.enc); err != nil {
+			FailWithError(t, "TestQUICVarint", err)
+		} else if v != tt.v || n != len(tt.enc) {
+			FailWithError(t, "TestQUICVarint", noMatch)
+		}
+	}
+
+	// Non-minimal encodings are accepted on read.
+	if v, _, err := ParseQUICVarint([]byte{0x40, 0x25}); err != nil || v != 37 {
+		FailWithError(t, "TestQUICVarint", noMatch)
+	}
+	if _, err := AppendQUICVarint(nil, 1<<62); err != ErrLengthRange {
+		FailWithError(t, "TestQUICVarint", noMatch)
+	}
+}
+
+func TestQUIC(t *testing.T) {
+	params := New()
+	idle, _ := AppendQUICVarint(nil, 30000)
+	params.Add(QUICMaxIdleTimeout, idle)
+	params.Add(QUICDisableActiveMigration, nil)
+	params.Add(0x2ab2, []byte{0xde, 0xad}) // a greased parameter
+
+	raw := encodeFormat(t, "TestQUIC", QUIC, params)
+	want := []byte{0x01, 0x04, 0x80, 0x00, 0x75, 0x30, 0x0c, 0x00,
+		0x6a, 0xb2, 0x02, 0xde, 0xad}
+	if !bytes.Equal(raw, want) {
+		FailWithError(t, "TestQUIC", fmt.Errorf("encoded % x", raw))
+	}
+
+	out := decodeFormat(t, "TestQUIC", QUIC, raw)
+	rec, err := out.Get(QUICMaxIdleTimeout)
+	if err != nil {
+		FailWithError(t, "TestQUIC", err)
+	} else if v, _, err := ParseQUICVarint(rec.Value()); err != nil || v != 30000 {
+		FailWithError(t, "TestQUIC", noMatch)
+	}
+	if !CompareLists(out, params, CompareRules{}).Equal() {
+		FailWithError(t, "TestQUIC", noMatch)
+	}
+
+	// Parameter 2^32+1 is not parameter 1 on 32-bit platforms.
+	wide, _ := AppendQUICVarint(nil, 1<<32+1)
+	dec := NewDecoder(bytes.NewReader(append(wide, 0x00)))
+	dec.Format = QUIC
+	rec, err = dec.Decode()
+	if strconv.IntSize == 32 {
+		if err != ErrTagRange {
+			FailWithError(t, "TestQUIC",
+				fmt.Errorf("expected ErrTagRange, got %v", err))
+		}
+	} else if err != nil {
+		FailWithError(t, "TestQUIC", err)
+	} else if int64(rec.Tag()) != 1<<32+1 {
+		FailWithError(t, "TestQUIC", noMatch)
+	}
+}
@@ -0,0 +1,72 @@
+package tlv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// RADIUS is the RADIUS attribute format: a one-byte type followed by a
+// one-byte length that counts the two header bytes as well as the value.
+// Tags must be between 0 and 255, and values may be at most 253 bytes
+// long. The same format is used for the sub-attributes of most
+// Vendor-Specific attributes.
+var RADIUS Format = radiusFormat{}
+
+// RADIUSVendorSpecific is the type of the RADIUS Vendor-Specific
+// attribute.
+const RADIUSVendorSpecific = 26
+
+type radiusFormat struct{}
+
+func (radiusFormat) ReadHeader(r io.Reader) (tag, length int, err error) {
+	var hdr [2]byte
+	if _, err = io.ReadFull(r, hdr[:]); err != nil {
+		return
+	} else if hdr[1] < 2 {
+		return 0, 0, ErrLengthRange
+	}
+	return int(hdr[0]), int(hdr[1]) - 2, nil
+}
+
+func (radiusFormat) WriteHeader(w io.Writer, tag, length int) error {
+	if tag < 0 || tag > 0xff {
+		return ErrTagRange
+	} else if length < 0 || length > 0xff-2 {
+		return ErrLengthRange
+	}
+	_, err := w.Write([]byte{byte(tag), byte(length + 2)})
+	return err
+}
+
+// VendorAttributes decodes a RADIUS Vendor-Specific attribute, returning
+// the vendor's SMI Private Enterprise Code and the vendor's
+// sub-attributes.
+func VendorAttributes(rec TLV) (vendor uint32, attrs *TLVList, err error) {
+	value := rec.Value()
+	if rec.Tag() != RADIUSVendorSpecific || len(value) < 4 {
+		return 0, nil, ErrTLVRead
+	}
+	vendor = binary.BigEndian.Uint32(value)
+
+	dec := NewDecoder(bytes.NewReader(value[4:]))
+	dec.Format = RADIUS
+	attrs, err = dec.DecodeList()
+	return
+}
+
+// VendorSpecific builds a RADIUS Vendor-Specific attribute holding attrs
+// as the sub-attributes of the given vendor.
+func VendorSpecific(vendor uint32, attrs *TLVList) (TLV, error) {
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.BigEndian, vendor)
+
+	enc := NewEncoder(buf)
+	enc.Format = RADIUS
+	if err := enc.EncodeList(attrs); err != nil {
+		return nil, err
+	} else if buf.Len() > 0xff-2 {
+		return nil, ErrLengthRange
+	}
+	return newTLV(RADIUSVendorSpecific, buf.Bytes()), nil
+}
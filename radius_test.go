@@ -0,0 +1,70 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// The attributes of an Access-Request: User-Name "gopher", NAS-Port 3,
+// and a Vendor-Specific attribute from vendor 9 holding a single
+// sub-attribute.
+var radiusAttrs = []byte{
+	0x01, 0x08, 'g', 'o', 'p', 'h', 'e', 'r',
+	0x05, 0x06, 0x00, 0x00, 0x00, 0x03,
+	0x1a, 0x0c, 0x00, 0x00, 0x00, 0x09,
+	0x01, 0x06, 'a', '=', 'b', 'c',
+}
+
+func TestRADIUS(t *testing.T) {
+	attrs := decodeFormat(t, "TestRADIUS", RADIUS, radiusAttrs)
+	if attrs.Length() != 3 {
+		FailWithError(t, "TestRADIUS",
+			fmt.Errorf("expected 3 attributes, got %d", attrs.Length()))
+	} else if user, err := attrs.Get(1); err != nil {
+		FailWithError(t, "TestRADIUS", err)
+	} else if string(user.Value()) != "gopher" {
+		FailWithError(t, "TestRADIUS", noMatch)
+	}
+
+	vsa, err := attrs.Get(RADIUSVendorSpecific)
+	if err != nil {
+		FailWithError(t, "TestRADIUS", err)
+	}
+	vendor, sub, err := VendorAttributes(vsa)
+	if err != nil {
+		FailWithError(t, "TestRADIUS", err)
+	} else if vendor != 9 {
+		FailWithError(t, "TestRADIUS",
+			fmt.Errorf("bad vendor %d", vendor))
+	} else if rec, err := sub.Get(1); err != nil {
+		FailWithError(t, "TestRADIUS", err)
+	} else if string(rec.Value()) != "a=bc" {
+		FailWithError(t, "TestRADIUS", noMatch)
+	}
+
+	rebuilt, err := VendorSpecific(vendor, sub)
+	if err != nil {
+		FailWithError(t, "TestRADIUS", err)
+	} else if !Equals(rebuilt, vsa) {
+		FailWithError(t, "TestRADIUS", noMatch)
+	}
+
+	if !bytes.Equal(encodeFormat(t, "TestRADIUS", RADIUS, attrs), radiusAttrs) {
+		FailWithError(t, "TestRADIUS",
+			fmt.Errorf("attributes did not round-trip"))
+	}
+
+	buf := new(bytes.Buffer)
+	if err = RADIUS.WriteHeader(buf, 1, 254); err != ErrLengthRange {
+		FailWithError(t, "TestRADIUS",
+			fmt.Errorf("overlong attribute should be rejected"))
+	} else if err = RADIUS.WriteHeader(buf, 256, 0); err != ErrTagRange {
+		FailWithError(t, "TestRADIUS",
+			fmt.Errorf("large type should be rejected"))
+	}
+	if _, _, err = RADIUS.ReadHeader(bytes.NewReader([]byte{1, 1})); err != ErrLengthRange {
+		FailWithError(t, "TestRADIUS",
+			fmt.Errorf("length below header size should be rejected"))
+	}
+}
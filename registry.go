@@ -0,0 +1,169 @@
+package tlv
+
+import "fmt"
+
+// ErrDuplicateTag is returned when a tag is registered more than once;
+// similarly, ErrDuplicateName is returned when a name is registered more
+// than once. ErrUnknownTag is returned when a tag or name that has not
+// been registered is looked up.
+var (
+	ErrDuplicateTag  = fmt.Errorf("tag already registered")
+	ErrDuplicateName = fmt.Errorf("name already registered")
+	ErrUnknownTag    = fmt.Errorf("tag not registered")
+)
+
+// Type Registry maps tags to symbolic names. A Registry should be fully
+// populated before it is shared between goroutines.
+type Registry struct {
+	names       map[int]string
+	tags        map[string]int
+	renderers   map[int]Renderer
+	ranges      []Range
+	enums       map[int]map[byte]string
+	constraints map[int]Constraint
+	kinds       map[int]string
+	sizes       map[int]int
+	descs       map[int]string
+}
+
+// NewRegistry returns a new, empty Registry.
+func NewRegistry() *Registry {
+	reg := new(Registry)
+	reg.names = make(map[int]string)
+	reg.tags = make(map[string]int)
+	return reg
+}
+
+// clone returns a deep copy of the registry.
+func (reg *Registry) clone() *Registry {
+	c := NewRegistry()
+	for tag, name := range reg.names {
+		c.names[tag] = name
+	}
+	for name, tag := range reg.tags {
+		c.tags[name] = tag
+	}
+	c.ranges = append([]Range(nil), reg.ranges...)
+	if reg.renderers != nil {
+		c.renderers = make(map[int]Renderer, len(reg.renderers))
+		for tag, r := range reg.renderers {
+			c.renderers[tag] = r
+		}
+	}
+	if reg.enums != nil {
+		c.enums = make(map[int]map[byte]string, len(reg.enums))
+		for tag, names := range reg.enums {
+			c.enums[tag] = make(map[byte]string, len(names))
+			for value, name := range names {
+				c.enums[tag][value] = name
+			}
+		}
+	}
+	if reg.constraints != nil {
+		c.constraints = make(map[int]Constraint, len(reg.constraints))
+		for tag, con := range reg.constraints {
+			c.constraints[tag] = con
+		}
+	}
+	if reg.kinds != nil {
+		c.kinds = make(map[int]string, len(reg.kinds))
+		for tag, kind := range reg.kinds {
+			c.kinds[tag] = kind
+		}
+	}
+	if reg.sizes != nil {
+		c.sizes = make(map[int]int, len(reg.sizes))
+		for tag, size := range reg.sizes {
+			c.sizes[tag] = size
+		}
+	}
+	if reg.descs != nil {
+		c.descs = make(map[int]string, len(reg.descs))
+		for tag, desc := range reg.descs {
+			c.descs[tag] = desc
+		}
+	}
+	return c
+}
+
+// Register associates name with tag. Both the tag and the name must be
+// unique within the registry, and the tag must not be reserved.
+func (reg *Registry) Register(tag int, name string) error {
+	if IsReservedTag(tag) {
+		return ErrReservedTag
+	} else if _, ok := reg.names[tag]; ok {
+		return ErrDuplicateTag
+	} else if _, ok := reg.tags[name]; ok {
+		return ErrDuplicateName
+	}
+	reg.names[tag] = name
+	reg.tags[name] = tag
+	return nil
+}
+
+// Name returns the name registered for tag.
+func (reg *Registry) Name(tag int) (name string, ok bool) {
+	name, ok = reg.names[tag]
+	return
+}
+
+// Lookup returns the tag registered under name.
+func (reg *Registry) Lookup(name string) (tag int, ok bool) {
+	tag, ok = reg.tags[name]
+	return
+}
+
+// SetDescription sets a human-readable description of tag, such as
+// "Language Preference", for use in debugging output and error
+// messages. The tag does not need to have a name registered.
+func (reg *Registry) SetDescription(tag int, desc string) {
+	if reg.descs == nil {
+		reg.descs = make(map[int]string)
+	}
+	reg.descs[tag] = desc
+}
+
+// Description returns the description set for tag.
+func (reg *Registry) Description(tag int) (desc string, ok bool) {
+	desc, ok = reg.descs[tag]
+	return
+}
+
+// Describe returns tag in a form suitable for messages, such as
+// "tag 0x5F2D (Language Preference)", using the tag's description or,
+// failing that, its name. Tags with neither, and all tags if reg is
+// nil, are shown as a bare number.
+func (reg *Registry) Describe(tag int) string {
+	s := fmt.Sprintf("tag 0x%X", tag)
+	if reg == nil {
+		return s
+	} else if desc, ok := reg.descs[tag]; ok {
+		return s + " (" + desc + ")"
+	} else if name, ok := reg.names[tag]; ok {
+		return s + " (" + name + ")"
+	}
+	return s
+}
+
+// Type TagError is an error concerning a record with a particular tag.
+// Its message describes the tag using the registry it was made with.
+type TagError struct {
+	Tag  int
+	Desc string
+	Err  error
+}
+
+// Method Error returns the error's message, prefixed with the tag.
+func (err *TagError) Error() string {
+	return err.Desc + ": " + err.Err.Error()
+}
+
+// Method Unwrap returns the underlying error.
+func (err *TagError) Unwrap() error {
+	return err.Err
+}
+
+// TagError returns err as a *TagError for tag, described by reg.
+func (reg *Registry) TagError(tag int, err error) error {
+	return &TagError{Tag: tag, Desc: reg.Describe(tag), Err: err}
+}
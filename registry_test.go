@@ -0,0 +1,107 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func testRegistry() *Registry {
+	reg := NewRegistry()
+	reg.Register(TagTest1, "test-one")
+	reg.Register(TagTest2, "test-two")
+	reg.Register(TagTest3, "test-three")
+	return reg
+}
+
+func TestRegistry(t *testing.T) {
+	reg := testRegistry()
+
+	if name, ok := reg.Name(TagTest2); !ok || name != "test-two" {
+		FailWithError(t, "TestRegistry",
+			fmt.Errorf("bad name for tag %d: %s", TagTest2, name))
+	}
+
+	if tag, ok := reg.Lookup("test-three"); !ok || tag != TagTest3 {
+		FailWithError(t, "TestRegistry",
+			fmt.Errorf("bad tag for test-three: %d", tag))
+	}
+
+	if _, ok := reg.Name(TagTest6); ok {
+		FailWithError(t, "TestRegistry",
+			fmt.Errorf("unregistered tag should not have a name"))
+	}
+
+	if err := reg.Register(TagTest1, "test-four"); err != ErrDuplicateTag {
+		FailWithError(t, "TestRegistry",
+			fmt.Errorf("duplicate tag should not register"))
+	}
+
+	if err := reg.Register(TagTest4, "test-one"); err != ErrDuplicateName {
+		FailWithError(t, "TestRegistry",
+			fmt.Errorf("duplicate name should not register"))
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	reg := testRegistry()
+	reg.SetDescription(0x5f2d, "Language Preference")
+
+	tests := []struct {
+		reg  *Registry
+		tag  int
+		want string
+	}{
+		{reg, 0x5f2d, "tag 0x5F2D (Language Preference)"},
+		{reg, TagTest2, "tag 0x1 (test-two)"},
+		{reg, 0x99, "tag 0x99"},
+		{nil, 0x5f2d, "tag 0x5F2D"},
+	}
+	for _, test := range tests {
+		if got := test.reg.Describe(test.tag); got != test.want {
+			FailWithError(t, "TestDescribe", fmt.Errorf("got %q, want %q", got, test.want))
+		}
+	}
+
+	err := reg.TagError(0x5f2d, ErrLengthRange)
+	if err.Error() != "tag 0x5F2D (Language Preference): "+ErrLengthRange.Error() ||
+		err.(*TagError).Unwrap() != ErrLengthRange {
+		FailWithError(t, "TestDescribe", fmt.Errorf("bad tag error %q", err))
+	}
+
+	recs := New()
+	recs.Add(0x5f2d, []byte("en"))
+	recs.Add(0x99, nil)
+	buf := new(bytes.Buffer)
+	if err = reg.WriteText(buf, recs); err != nil {
+		FailWithError(t, "TestDescribe", err)
+	}
+	want := "# tag 0x5F2D (Language Preference)\ntag=24365 len=2 value=0x656e\ntag=153 len=0 value=0x\n"
+	if buf.String() != want {
+		FailWithError(t, "TestDescribe", fmt.Errorf("bad text:\n%s", buf))
+	}
+	back, err := ReadText(buf)
+	if err != nil || !CompareLists(recs, back, CompareRules{}).Equal() {
+		FailWithError(t, "TestDescribe", fmt.Errorf("text did not round trip: %v", err))
+	}
+
+	out, err := reg.EncodeJSON(recs, true)
+	if err != nil {
+		FailWithError(t, "TestDescribe", err)
+	}
+	want = `[{"tag":24365,"description":"Language Preference","hex":"656e"},{"tag":153,"hex":""}]`
+	if string(out) != want {
+		FailWithError(t, "TestDescribe", fmt.Errorf("bad JSON %s", out))
+	}
+	back = New()
+	if err = back.UnmarshalJSON(out); err != nil || !CompareLists(recs, back, CompareRules{}).Equal() {
+		FailWithError(t, "TestDescribe", fmt.Errorf("JSON did not round trip: %v", err))
+	}
+
+	buf.Reset()
+	reg.Dump(buf, recs)
+	if !strings.HasPrefix(buf.String(), "├── 0x5f2d (Language Preference) (len 2)") {
+		FailWithError(t, "TestDescribe", fmt.Errorf("bad dump:\n%s", buf))
+	}
+}
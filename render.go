@@ -0,0 +1,88 @@
+package tlv
+
+import (
+	"encoding/hex"
+	"net"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// Type Renderer converts a record's value into a human-readable string
+// for export and display.
+type Renderer func(value []byte) string
+
+// SetRenderer registers the Renderer used to display values with the
+// given tag. The tag does not need to have a name registered.
+func (reg *Registry) SetRenderer(tag int, r Renderer) {
+	if reg.renderers == nil {
+		reg.renderers = make(map[int]Renderer)
+	}
+	reg.renderers[tag] = r
+}
+
+// Render returns a human-readable form of a record's value, using the
+// Renderer registered for its tag. Values without a Renderer are shown
+// in hex, as they are if reg is nil.
+func (reg *Registry) Render(rec TLV) string {
+	if reg != nil {
+		if r, ok := reg.renderers[rec.Tag()]; ok {
+			return r(rec.Value())
+		}
+	}
+	return RenderHex(rec.Value())
+}
+
+// RenderHex renders a value as lowercase hex.
+func RenderHex(value []byte) string {
+	return hex.EncodeToString(value)
+}
+
+// RenderString renders a value as a quoted string, falling back to hex
+// if the value is not valid UTF-8.
+func RenderString(value []byte) string {
+	if !utf8.Valid(value) {
+		return RenderHex(value)
+	}
+	return strconv.Quote(string(value))
+}
+
+// RenderIP renders a 4- or 16-byte value as an IP address, falling back
+// to hex for values of any other length.
+func RenderIP(value []byte) string {
+	if len(value) != net.IPv4len && len(value) != net.IPv6len {
+		return RenderHex(value)
+	}
+	return net.IP(value).String()
+}
+
+// RenderUint renders a big-endian unsigned integer of up to eight bytes
+// in decimal, falling back to hex for longer values.
+func RenderUint(value []byte) string {
+	n, ok := beUint(value)
+	if !ok {
+		return RenderHex(value)
+	}
+	return strconv.FormatUint(n, 10)
+}
+
+// RenderTime renders a big-endian count of seconds since the Unix epoch,
+// of up to eight bytes, as an RFC 3339 timestamp in UTC. Longer values
+// are rendered in hex.
+func RenderTime(value []byte) string {
+	n, ok := beUint(value)
+	if !ok {
+		return RenderHex(value)
+	}
+	return time.Unix(int64(n), 0).UTC().Format(time.RFC3339)
+}
+
+func beUint(value []byte) (n uint64, ok bool) {
+	if len(value) > 8 {
+		return 0, false
+	}
+	for _, b := range value {
+		n = n<<8 | uint64(b)
+	}
+	return n, true
+}
@@ -0,0 +1,40 @@
+package tlv
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	reg := testRegistry()
+	reg.SetRenderer(TagTest1, RenderString)
+	reg.SetRenderer(TagTest2, RenderIP)
+	reg.SetRenderer(TagTest3, RenderTime)
+	reg.SetRenderer(TagTest4, RenderUint)
+
+	tests := []struct {
+		rec      TLV
+		expected string
+	}{
+		{newTLV(TagTest1, []byte("foo bar")), `"foo bar"`},
+		{newTLV(TagTest1, []byte{0xff}), "ff"},
+		{newTLV(TagTest2, []byte{192, 0, 2, 1}), "192.0.2.1"},
+		{newTLV(TagTest2, []byte{192, 0, 2}), "c00002"},
+		{newTLV(TagTest3, []byte{0x51, 0xc9, 0xe5, 0x00}), "2013-06-25T18:44:16Z"},
+		{newTLV(TagTest4, []byte{1, 0}), "256"},
+		{newTLV(TagTest5, []byte{0xde, 0xad}), "dead"},
+	}
+	for _, test := range tests {
+		if s := reg.Render(test.rec); s != test.expected {
+			FailWithError(t, "TestRender",
+				fmt.Errorf("tag %d rendered as %s, expected %s",
+					test.rec.Tag(), s, test.expected))
+		}
+	}
+
+	var nilReg *Registry
+	if s := nilReg.Render(newTLV(TagTest1, []byte("hi"))); s != "6869" {
+		FailWithError(t, "TestRender",
+			fmt.Errorf("nil registry rendered %s", s))
+	}
+}
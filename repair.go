@@ -0,0 +1,65 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// ErrConflict is returned by RepairError when a single-valued tag has
+// conflicting records.
+var ErrConflict = fmt.Errorf("conflicting records for single-valued tag")
+
+// Type RepairPolicy reconciles two records with the same single-valued
+// tag, the existing record and one that follows it in the stream,
+// returning the record to keep. Policies must be deterministic so that
+// every reader of a file reconciles it in the same way.
+type RepairPolicy func(existing, incoming TLV) (TLV, error)
+
+// RepairError keeps identical duplicates, and fails with ErrConflict if
+// the records differ.
+func RepairError(existing, incoming TLV) (TLV, error) {
+	if !Equals(existing, incoming) {
+		return nil, ErrConflict
+	}
+	return existing, nil
+}
+
+// RepairNewest returns a policy that keeps the record with the latest
+// timestamp, as extracted by timestamp. Records without a timestamp are
+// older than any with one. Ties are broken by keeping the record with
+// the greater value, so the result does not depend on the order in
+// which writers' records were interleaved.
+func RepairNewest(timestamp func(TLV) (time.Time, bool)) RepairPolicy {
+	return func(existing, incoming TLV) (TLV, error) {
+		te, okE := timestamp(existing)
+		ti, okI := timestamp(incoming)
+		switch {
+		case okE != okI:
+			if okI {
+				return incoming, nil
+			}
+			return existing, nil
+		case ti.After(te):
+			return incoming, nil
+		case te.After(ti):
+			return existing, nil
+		case bytes.Compare(incoming.Value(), existing.Value()) > 0:
+			return incoming, nil
+		}
+		return existing, nil
+	}
+}
+
+// RepairMerge returns a policy that combines the values of the records
+// with merge. For the result to be deterministic, merge should be
+// commutative and associative, such as a set union or a maximum.
+func RepairMerge(merge func(a, b []byte) ([]byte, error)) RepairPolicy {
+	return func(existing, incoming TLV) (TLV, error) {
+		value, err := merge(existing.Value(), incoming.Value())
+		if err != nil {
+			return nil, err
+		}
+		return newTLV(existing.Tag(), value), nil
+	}
+}
@@ -0,0 +1,79 @@
+package tlv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// repairValue builds a value holding a Unix timestamp followed by data.
+func repairValue(ts uint32, data string) []byte {
+	value := make([]byte, 4, 4+len(data))
+	binary.BigEndian.PutUint32(value, ts)
+	return append(value, data...)
+}
+
+func repairTimestamp(rec TLV) (time.Time, bool) {
+	if rec.Length() < 4 {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(binary.BigEndian.Uint32(rec.Value())), 0), true
+}
+
+func repairStream(t *testing.T) []byte {
+	tlvl := New()
+	tlvl.Add(TagTest1, repairValue(200, "newer"))
+	tlvl.Add(TagTest2, []byte("multi"))
+	tlvl.Add(TagTest1, repairValue(100, "older"))
+	tlvl.Add(TagTest2, []byte("valued"))
+	tlvl.Add(TagTest1, repairValue(200, "newest"))
+
+	buf := new(bytes.Buffer)
+	if err := tlvl.Write(buf); err != nil {
+		FailWithError(t, "repairStream", err)
+	}
+	return buf.Bytes()
+}
+
+func TestRepair(t *testing.T) {
+	raw := repairStream(t)
+
+	dec := NewDecoder(bytes.NewReader(raw))
+	dec.Repair = map[int]RepairPolicy{TagTest1: RepairNewest(repairTimestamp)}
+	tlvl, err := dec.DecodeList()
+	if err != nil {
+		FailWithError(t, "TestRepair", err)
+	}
+	checkTags(t, "TestRepair", tlvl, TagTest1, TagTest2, TagTest2)
+	if rec, _ := tlvl.Get(TagTest1); string(rec.Value()[4:]) != "newest" {
+		FailWithError(t, "TestRepair",
+			fmt.Errorf("kept %q", rec.Value()[4:]))
+	}
+
+	dec = NewDecoder(bytes.NewReader(raw))
+	dec.Repair = map[int]RepairPolicy{TagTest1: RepairError}
+	if tlvl, err = dec.DecodeList(); err != ErrConflict {
+		FailWithError(t, "TestRepair",
+			fmt.Errorf("expected ErrConflict, got %v", err))
+	}
+	// The records decoded before the conflict are intact.
+	if rec, err := tlvl.Get(TagTest1); err != nil || rec == nil {
+		FailWithError(t, "TestRepair",
+			fmt.Errorf("conflicting record was lost"))
+	}
+
+	dec = NewDecoder(bytes.NewReader(raw))
+	dec.Repair = map[int]RepairPolicy{TagTest2: RepairMerge(func(a, b []byte) ([]byte, error) {
+		if bytes.Compare(a, b) > 0 {
+			a, b = b, a
+		}
+		return append(append(append([]byte{}, a...), ','), b...), nil
+	})}
+	if tlvl, err = dec.DecodeList(); err != nil {
+		FailWithError(t, "TestRepair", err)
+	} else if rec, _ := tlvl.Get(TagTest2); string(rec.Value()) != "multi,valued" {
+		FailWithError(t, "TestRepair", noMatch)
+	}
+}
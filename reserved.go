@@ -0,0 +1,72 @@
+package tlv
+
+import "fmt"
+
+// The package reserves a range of tags for its own control records, so
+// that they can be told apart from application records in any stream.
+// The tags assigned so far are:
+//
+//	0x7fffff01  TagSignature       signature of a signed stream
+//	0x7fffff02  TagTombstone       deletion marker in an append-only log
+//	0x7fffff03  TagIndex           footer index for reverse reading
+//	0x7fffff04  TagCompressed      compressed record wrapper
+//	0x7fffff05  TagStringEncoding  string encoding of a tag's values
+//	0x7fffff06  TagTimestamp       batch timestamp in an append-only log
+//	0x7fffff07  TagSummary         stream summary trailer
+//
+// The rest of the range is kept for future control records.
+const (
+	ReservedTagMin = 0x7fffff00
+	ReservedTagMax = 0x7fffff7f
+)
+
+// ErrReservedTag is returned when an application tries to use a tag in
+// the reserved range.
+var ErrReservedTag = fmt.Errorf("tag is reserved for control records")
+
+// IsReservedTag reports whether tag lies within the reserved range.
+func IsReservedTag(tag int) bool {
+	return tag >= ReservedTagMin && tag <= ReservedTagMax
+}
+
+// isControlTag reports whether tag is one of the assigned control tags
+// listed above, as opposed to the unassigned remainder of the range.
+func isControlTag(tag int) bool {
+	return tag >= TagSignature && tag <= TagSummary
+}
+
+// CheckTag returns ErrReservedTag if tag lies within the reserved range,
+// and nil otherwise. Applications that take tags from their users can
+// use it to keep them out of the range.
+func CheckTag(tag int) error {
+	if IsReservedTag(tag) {
+		return ErrReservedTag
+	}
+	return nil
+}
+
+// RemapReserved is a migration aid for lists read from files written
+// before the range was reserved, which may use reserved tags as
+// application tags; such files should be read with a Decoder whose
+// Legacy field is set. Every record in recs with a reserved tag is given
+// the tag remap returns for it. ErrReservedTag is returned, and recs
+// left unchanged, if remap returns a reserved tag.
+func RemapReserved(recs *TLVList, remap func(tag int) int) error {
+	tags := make(map[int]int)
+	for _, tlv := range recs.records {
+		tag := tlv.Tag()
+		if _, ok := tags[tag]; ok || !IsReservedTag(tag) {
+			continue
+		}
+		if tags[tag] = remap(tag); IsReservedTag(tags[tag]) {
+			return ErrReservedTag
+		}
+	}
+
+	for i, tlv := range recs.records {
+		if to, ok := tags[tlv.Tag()]; ok {
+			recs.set(i, newTLV(to, tlv.Value()))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestReservedTags(t *testing.T) {
+	for _, tag := range []int{TagSignature, TagTombstone, TagIndex, TagCompressed,
+		TagStringEncoding, TagTimestamp, TagSummary} {
+		if !IsReservedTag(tag) {
+			FailWithError(t, "TestReservedTags",
+				fmt.Errorf("%#x is not reserved", tag))
+		}
+	}
+	if CheckTag(TagTest1) != nil || CheckTag(ReservedTagMax) != ErrReservedTag {
+		FailWithError(t, "TestReservedTags", noMatch)
+	}
+	if err := NewRegistry().Register(TagIndex, "index"); err != ErrReservedTag {
+		FailWithError(t, "TestReservedTags",
+			fmt.Errorf("expected ErrReservedTag, got %v", err))
+	}
+}
+
+func TestLegacyReserved(t *testing.T) {
+	// A file from before the range was reserved, using the tag now
+	// assigned to compressed records for its own data.
+	old := New()
+	old.Add(TagCompressed, []byte("not compressed"))
+	old.Add(TagTest1, []byte("foo"))
+	buf := new(bytes.Buffer)
+	if err := old.Write(buf); err != nil {
+		FailWithError(t, "TestLegacyReserved", err)
+	}
+
+	dec := NewDecoder(bytes.NewReader(buf.Bytes()))
+	if _, err := dec.DecodeList(); err == nil {
+		FailWithError(t, "TestLegacyReserved",
+			fmt.Errorf("old record was decompressed"))
+	}
+
+	dec = NewDecoder(bytes.NewReader(buf.Bytes()))
+	dec.Legacy = true
+	recs, err := dec.DecodeList()
+	if err != nil {
+		FailWithError(t, "TestLegacyReserved", err)
+	}
+	err = RemapReserved(recs, func(tag int) int { return tag - ReservedTagMin + 0x1000 })
+	if err != nil {
+		FailWithError(t, "TestLegacyReserved", err)
+	} else if recs.Spec() != `4100=str:not compressed, 0=str:foo` {
+		FailWithError(t, "TestLegacyReserved",
+			fmt.Errorf("remapped to %s", recs.Spec()))
+	}
+
+	if err = RemapReserved(old, func(tag int) int { return tag }); err != ErrReservedTag {
+		FailWithError(t, "TestLegacyReserved",
+			fmt.Errorf("expected ErrReservedTag, got %v", err))
+	}
+}
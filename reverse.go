@@ -0,0 +1,132 @@
+package tlv
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// TagIndex is the tag of the footer index record written by an Encoder
+// with Index set. Its value holds the offset of each record in the
+// stream, followed by the offset of the index record itself, all as
+// 64-bit big-endian integers. Because the index record is the last in
+// the stream, its offset can always be found in the final eight bytes.
+const TagIndex = 0x7fffff03
+
+// ErrNoIndex is returned by a ReverseDecoder when its input does not end
+// with a valid footer index.
+var ErrNoIndex = fmt.Errorf("TLV stream has no footer index")
+
+func indexRecord(offsets []int64, self int64) TLV {
+	value := make([]byte, 8*(len(offsets)+1))
+	for i, off := range offsets {
+		binary.BigEndian.PutUint64(value[8*i:], uint64(off))
+	}
+	binary.BigEndian.PutUint64(value[8*len(offsets):], uint64(self))
+	return &record{tag: TagIndex, length: len(value), value: value}
+}
+
+// Type ReverseDecoder reads the records of an indexed stream from the
+// last record to the first, without reading the rest of the stream. This
+// makes it cheap to fetch the most recent records from a large log.
+// Streams are indexed by setting Index on the Encoder that wrote them.
+type ReverseDecoder struct {
+	// Format is the wire format records are read in; it must match
+	// the format the stream was written in. If nil, Standard is used.
+	Format Format
+
+	r       io.ReaderAt
+	size    int64
+	offsets []int64
+	loaded  bool
+}
+
+// NewReverseDecoder returns a new ReverseDecoder reading an indexed
+// stream of size bytes from r.
+func NewReverseDecoder(r io.ReaderAt, size int64) *ReverseDecoder {
+	return &ReverseDecoder{r: r, size: size}
+}
+
+func (dec *ReverseDecoder) decoderAt(off int64) *Decoder {
+	d := NewDecoder(io.NewSectionReader(dec.r, off, dec.size-off))
+	d.Format = dec.Format
+	d.MaxLength = int(dec.size - off)
+	return d
+}
+
+func (dec *ReverseDecoder) loadIndex() error {
+	if dec.size < 8 {
+		return ErrNoIndex
+	}
+
+	var tail [8]byte
+	// A ReaderAt may return io.EOF along with the last bytes of its
+	// input, which is where the footer is.
+	if n, err := dec.r.ReadAt(tail[:], dec.size-8); n < len(tail) {
+		return err
+	}
+	self := int64(binary.BigEndian.Uint64(tail[:]))
+	if self < 0 || self >= dec.size-8 {
+		return ErrNoIndex
+	}
+
+	idx, err := dec.decoderAt(self).Decode()
+	if err != nil || idx.Tag() != TagIndex || idx.Length()%8 != 0 {
+		return ErrNoIndex
+	}
+
+	value := idx.Value()
+	if int64(binary.BigEndian.Uint64(value[len(value)-8:])) != self {
+		return ErrNoIndex
+	}
+	dec.offsets = make([]int64, len(value)/8-1)
+	for i := range dec.offsets {
+		off := int64(binary.BigEndian.Uint64(value[8*i:]))
+		if off < 0 || off >= self {
+			return ErrNoIndex
+		}
+		dec.offsets[i] = off
+	}
+	return nil
+}
+
+// Decode returns the record preceding the last one returned, starting
+// with the last record in the stream. Once the first record has been
+// returned, Decode returns io.EOF.
+func (dec *ReverseDecoder) Decode() (TLV, error) {
+	if !dec.loaded {
+		if err := dec.loadIndex(); err != nil {
+			return nil, err
+		}
+		dec.loaded = true
+	}
+
+	n := len(dec.offsets)
+	if n == 0 {
+		return nil, io.EOF
+	}
+	off := dec.offsets[n-1]
+	dec.offsets = dec.offsets[:n-1]
+	return dec.decoderAt(off).Decode()
+}
+
+// Last returns up to n of the last records in the stream, in stream
+// order.
+func (dec *ReverseDecoder) Last(n int) (recs *TLVList, err error) {
+	recs = New()
+	for i := 0; i < n; i++ {
+		var tlv TLV
+		if tlv, err = dec.Decode(); err == io.EOF {
+			err = nil
+			break
+		} else if err != nil {
+			return
+		}
+		recs.records = append(recs.records, tlv)
+	}
+
+	for i, j := 0, len(recs.records)-1; i < j; i, j = i+1, j-1 {
+		recs.records[i], recs.records[j] = recs.records[j], recs.records[i]
+	}
+	return
+}
@@ -0,0 +1,88 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// eofReaderAt returns io.EOF along with the bytes at the end of its
+// input, as io.ReaderAt allows.
+type eofReaderAt struct {
+	r *bytes.Reader
+}
+
+func (r eofReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := r.r.ReadAt(p, off)
+	if err == nil && off+int64(n) == r.r.Size() {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func TestReverseDecoder(t *testing.T) {
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+	enc.Format = BER
+	enc.Index = true
+	for i := 0; i < 20; i++ {
+		err := enc.Encode(newTLV(i+1, bytes.Repeat([]byte{byte(i)}, i*10)))
+		if err != nil {
+			FailWithError(t, "TestReverseDecoder", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		FailWithError(t, "TestReverseDecoder", err)
+	}
+	raw := buf.Bytes()
+
+	// The index is an ordinary record to a forward Decoder.
+	recs := decodeFormat(t, "TestReverseDecoder", BER, raw)
+	if recs.Length() != 21 {
+		FailWithError(t, "TestReverseDecoder",
+			fmt.Errorf("expected 21 records, got %d", recs.Length()))
+	}
+
+	dec := NewReverseDecoder(bytes.NewReader(raw), int64(len(raw)))
+	dec.Format = BER
+	last, err := dec.Last(3)
+	if err != nil {
+		FailWithError(t, "TestReverseDecoder", err)
+	} else if last.Length() != 3 {
+		FailWithError(t, "TestReverseDecoder", noMatch)
+	} else if last.records[0].Tag() != 18 {
+		FailWithError(t, "TestReverseDecoder",
+			fmt.Errorf("records should be in stream order"))
+	}
+
+	for i := 17; i > 0; i-- {
+		rec, err := dec.Decode()
+		if err != nil {
+			FailWithError(t, "TestReverseDecoder", err)
+		} else if !Equals(rec, newTLV(i, bytes.Repeat([]byte{byte(i - 1)}, (i-1)*10))) {
+			FailWithError(t, "TestReverseDecoder", noMatch)
+		}
+	}
+	if _, err = dec.Decode(); err != io.EOF {
+		FailWithError(t, "TestReverseDecoder",
+			fmt.Errorf("expected EOF, got %v", err))
+	}
+
+	// ReaderAt implementations may return io.EOF with the final bytes.
+	dec = NewReverseDecoder(eofReaderAt{bytes.NewReader(raw)}, int64(len(raw)))
+	dec.Format = BER
+	if rec, err := dec.Decode(); err != nil {
+		FailWithError(t, "TestReverseDecoder", err)
+	} else if rec.Tag() != 20 {
+		FailWithError(t, "TestReverseDecoder", noMatch)
+	}
+
+	unindexed := raw[:len(raw)-1]
+	dec = NewReverseDecoder(bytes.NewReader(unindexed), int64(len(unindexed)))
+	dec.Format = BER
+	if _, err = dec.Decode(); err != ErrNoIndex {
+		FailWithError(t, "TestReverseDecoder",
+			fmt.Errorf("stream without an index should fail"))
+	}
+}
@@ -0,0 +1,200 @@
+package tlv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// RIFF is the chunk format of RIFF files, such as WAV and AVI: a
+// four-character chunk ID, used as the tag as with FourCC, followed by a
+// 32-bit little-endian length. Chunk data is padded to an even length,
+// and the pad byte is not counted in the length; set Align to 2 on the
+// Encoder and Decoder, or use ReadRIFF and WriteRIFF, which do so.
+var RIFF Format = riffFormat{}
+
+// The IDs of the RIFF container chunks, whose data is a four-character
+// form type followed by further chunks.
+var (
+	RIFFChunk, _ = FourCC("RIFF")
+	RIFFList, _  = FourCC("LIST")
+)
+
+type riffFormat struct{}
+
+func (riffFormat) ReadHeader(r io.Reader) (tag, length int, err error) {
+	var hdr [8]byte
+	if _, err = io.ReadFull(r, hdr[:]); err != nil {
+		return
+	}
+	if tag, err = fourCCTag(binary.BigEndian.Uint32(hdr[:])); err != nil {
+		return
+	}
+	n := binary.LittleEndian.Uint32(hdr[4:])
+	if n > maxChunk {
+		return 0, 0, ErrLengthRange
+	}
+	return tag, int(n), nil
+}
+
+func (riffFormat) WriteHeader(w io.Writer, tag, length int) error {
+	if tag < 0 || int64(tag) > 0xffffffff {
+		return ErrTagRange
+	} else if length < 0 || length > maxChunk {
+		return ErrLengthRange
+	}
+
+	var hdr [8]byte
+	binary.BigEndian.PutUint32(hdr[:], uint32(tag))
+	binary.LittleEndian.PutUint32(hdr[4:], uint32(length))
+	_, err := w.Write(hdr[:])
+	return err
+}
+
+// ReadRIFF reads the chunks of a RIFF file from r. RIFF and LIST chunks
+// are returned as Constructed records whose children are the chunks they
+// hold; their form type is returned by RIFFChildren. Their Value is their
+// data as read, form type first, and they are written back unchanged.
+func ReadRIFF(r io.Reader) (*TLVList, error) {
+	return readRIFF(r, 0)
+}
+
+// readRIFF reads chunks from r, decoding the containers among them,
+// which are nested depth deep.
+func readRIFF(r io.Reader, depth int) (*TLVList, error) {
+	if depth >= maxNestDepth {
+		return nil, ErrTooLarge
+	}
+
+	dec := NewDecoder(r)
+	dec.Format = RIFF
+	dec.Align = 2
+	recs, err := dec.DecodeList()
+	if err != nil {
+		return recs, err
+	}
+
+	for i, rec := range recs.records {
+		if !IsRIFFContainer(rec) {
+			continue
+		}
+		value := rec.Value()
+		if len(value) < 4 {
+			return nil, ErrTLVRead
+		}
+		chunks, err := readRIFF(bytes.NewReader(value[4:]), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		recs.records[i] = &riffContainer{
+			tag:    rec.Tag(),
+			form:   string(value[:4]),
+			chunks: chunks,
+		}
+	}
+	return recs, nil
+}
+
+// WriteRIFF writes recs to w as RIFF chunks, padding odd-length chunks.
+func WriteRIFF(w io.Writer, recs *TLVList) error {
+	enc := NewEncoder(w)
+	enc.Format = RIFF
+	enc.Align = 2
+	return enc.EncodeList(recs)
+}
+
+// IsRIFFContainer reports whether rec is a RIFF or LIST chunk, which
+// holds further chunks.
+func IsRIFFContainer(rec TLV) bool {
+	return rec.Tag() == RIFFChunk || rec.Tag() == RIFFList
+}
+
+// RIFFChildren returns the form type of a RIFF or LIST chunk, such as
+// "WAVE" or "INFO", and the chunks it holds. For a chunk read by
+// ReadRIFF or built by RIFFContainer, the list is the record's own, and
+// changes to it change the record.
+func RIFFChildren(rec TLV) (form string, children *TLVList, err error) {
+	if c, ok := rec.(*riffContainer); ok {
+		return c.form, c.chunks, nil
+	}
+
+	value := rec.Value()
+	if !IsRIFFContainer(rec) || len(value) < 4 {
+		return "", nil, ErrTLVRead
+	}
+	children, err = ReadRIFF(bytes.NewReader(value[4:]))
+	return string(value[:4]), children, err
+}
+
+// RIFFContainer builds a RIFF or LIST chunk, as given by tag, holding
+// the form type and the chunks in children. The list is not copied.
+func RIFFContainer(tag int, form string, children *TLVList) (Constructed, error) {
+	if tag != RIFFChunk && tag != RIFFList {
+		return nil, ErrTagRange
+	} else if len(form) != 4 {
+		return nil, ErrTagRange
+	}
+
+	if err := WriteRIFF(io.Discard, children); err != nil {
+		return nil, err
+	}
+	return &riffContainer{tag: tag, form: form, chunks: children}, nil
+}
+
+// riffContainer is a RIFF or LIST chunk holding further chunks.
+type riffContainer struct {
+	tag    int
+	form   string
+	chunks *TLVList
+}
+
+// Method Tag returns the chunk ID.
+func (rec *riffContainer) Tag() int {
+	return rec.tag
+}
+
+// Method Length returns the length of the chunk's data: the form type
+// and the padded chunks it holds. It is computed from the chunks'
+// lengths where it can be, without encoding them.
+func (rec *riffContainer) Length() int {
+	n := len(rec.form)
+	for _, chunk := range rec.chunks.records {
+		n += riffChunkSize(chunk)
+	}
+	return n
+}
+
+// riffChunkSize returns the size of chunk written as a RIFF chunk,
+// including its header and pad byte.
+func riffChunkSize(chunk TLV) int {
+	length := chunk.Length()
+	if _, ok := chunk.(encodedConstructed); !ok {
+		if c, ok := chunk.(Constructed); ok {
+			enc := NewEncoder(io.Discard)
+			enc.Format = RIFF
+			flat, err := enc.flatten(c)
+			if err != nil {
+				return 0
+			}
+			length = flat.Length()
+		}
+	}
+	return 8 + length + length&1
+}
+
+// Method Value returns the chunk's data: the form type followed by the
+// chunks it holds, encoded afresh on each call.
+func (rec *riffContainer) Value() []byte {
+	buf := bytes.NewBuffer(make([]byte, 0, rec.Length()))
+	buf.WriteString(rec.form)
+	WriteRIFF(buf, rec.chunks)
+	return buf.Bytes()
+}
+
+// Method Children returns the chunks the container holds. Changes to
+// the list change the record.
+func (rec *riffContainer) Children() *TLVList {
+	return rec.chunks
+}
+
+func (rec *riffContainer) encodedValue() {}
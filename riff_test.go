@@ -0,0 +1,114 @@
+package tlv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"testing"
+)
+
+func TestRIFF(t *testing.T) {
+	fourCC := func(code string) int {
+		tag, err := FourCC(code)
+		if err != nil {
+			FailWithError(t, "TestRIFF", err)
+		}
+		return tag
+	}
+
+	info := New()
+	info.Add(fourCC("INAM"), []byte("Song\x00"))
+	list, err := RIFFContainer(RIFFList, "INFO", info)
+	if err != nil {
+		FailWithError(t, "TestRIFF", err)
+	}
+
+	wave := New()
+	wave.Add(fourCC("fmt "), make([]byte, 16))
+	wave.records = append(wave.records, list)
+	wave.Add(fourCC("data"), []byte{1, 2, 3})
+	riff, err := RIFFContainer(RIFFChunk, "WAVE", wave)
+	if err != nil {
+		FailWithError(t, "TestRIFF", err)
+	}
+
+	file := New()
+	file.records = append(file.records, riff)
+	buf := new(bytes.Buffer)
+	if err = WriteRIFF(buf, file); err != nil {
+		FailWithError(t, "TestRIFF", err)
+	}
+
+	// WAVE, fmt (8+16), LIST (8+4+8+5+1), data (8+3+1).
+	raw := buf.Bytes()
+	if size := binary.LittleEndian.Uint32(raw[4:]); size != 4+24+26+12 {
+		FailWithError(t, "TestRIFF",
+			fmt.Errorf("RIFF size is %d", size))
+	} else if len(raw) != 8+int(size) {
+		FailWithError(t, "TestRIFF", noMatch)
+	}
+
+	top, err := ReadRIFF(bytes.NewReader(raw))
+	if err != nil {
+		FailWithError(t, "TestRIFF", err)
+	}
+	rec, err := top.Get(RIFFChunk)
+	if err != nil {
+		FailWithError(t, "TestRIFF", err)
+	} else if c, ok := rec.(Constructed); !ok || c.Children().Length() != 3 {
+		FailWithError(t, "TestRIFF", noMatch)
+	}
+
+	again := new(bytes.Buffer)
+	if err = WriteRIFF(again, top); err != nil {
+		FailWithError(t, "TestRIFF", err)
+	} else if !bytes.Equal(again.Bytes(), raw) {
+		FailWithError(t, "TestRIFF", noMatch)
+	}
+	form, chunks, err := RIFFChildren(rec)
+	if err != nil {
+		FailWithError(t, "TestRIFF", err)
+	} else if form != "WAVE" || chunks.Length() != 3 {
+		FailWithError(t, "TestRIFF", noMatch)
+	}
+
+	rec, err = chunks.Get(RIFFList)
+	if err != nil {
+		FailWithError(t, "TestRIFF", err)
+	}
+	form, tags, err := RIFFChildren(rec)
+	if err != nil {
+		FailWithError(t, "TestRIFF", err)
+	} else if form != "INFO" {
+		FailWithError(t, "TestRIFF", noMatch)
+	} else if name, err := tags.Get(fourCC("INAM")); err != nil {
+		FailWithError(t, "TestRIFF", err)
+	} else if string(name.Value()) != "Song\x00" {
+		FailWithError(t, "TestRIFF", noMatch)
+	}
+
+	if data, err := chunks.Get(fourCC("data")); err != nil {
+		FailWithError(t, "TestRIFF", err)
+	} else if data.Length() != 3 {
+		FailWithError(t, "TestRIFF", noMatch)
+	}
+}
+
+func TestRIFFDepth(t *testing.T) {
+	chunks := New()
+	for i := 0; i < maxNestDepth; i++ {
+		list, err := RIFFContainer(RIFFList, "INFO", chunks)
+		if err != nil {
+			FailWithError(t, "TestRIFFDepth", err)
+		}
+		chunks = New()
+		chunks.AddRecord(list)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := WriteRIFF(buf, chunks); err != nil {
+		FailWithError(t, "TestRIFFDepth", err)
+	} else if _, err = ReadRIFF(buf); err != ErrTooLarge {
+		FailWithError(t, "TestRIFFDepth", fmt.Errorf("got %v", err))
+	}
+}
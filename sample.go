@@ -0,0 +1,96 @@
+package tlv
+
+import "math/rand"
+
+// Head returns a new TLVList holding the first n records of the list.
+func (recs *TLVList) Head(n int) *TLVList {
+	if n > len(recs.records) {
+		n = len(recs.records)
+	} else if n < 0 {
+		n = 0
+	}
+	head := New()
+	head.records = append([]TLV(nil), recs.records[:n]...)
+	return head
+}
+
+// Tail returns a new TLVList holding the last n records of the list.
+func (recs *TLVList) Tail(n int) *TLVList {
+	if n > len(recs.records) {
+		n = len(recs.records)
+	} else if n < 0 {
+		n = 0
+	}
+	tail := New()
+	tail.records = append([]TLV(nil), recs.records[len(recs.records)-n:]...)
+	return tail
+}
+
+// Sample returns a new TLVList holding a random subset of the list's
+// records, keeping each with probability rate. If rnd is nil, the
+// default source from math/rand is used.
+func (recs *TLVList) Sample(rate float64, rnd *rand.Rand) *TLVList {
+	sample := New()
+	for _, tlv := range recs.records {
+		if keep(rate, rnd) {
+			sample.records = append(sample.records, tlv)
+		}
+	}
+	return sample
+}
+
+func keep(rate float64, rnd *rand.Rand) bool {
+	if rnd == nil {
+		return rand.Float64() < rate
+	}
+	return rnd.Float64() < rate
+}
+
+// Head decodes the next n records from the stream, returning fewer if
+// the stream ends first.
+func (dec *Decoder) Head(n int) (recs *TLVList, err error) {
+	recs = New()
+	for recs.Length() < n {
+		var tlv TLV
+		if tlv, err = dec.Decode(); err != nil {
+			break
+		}
+		recs.records = append(recs.records, tlv)
+	}
+	return recs, eof(err)
+}
+
+// Tail decodes the rest of the stream, returning the last n records.
+// Only the records being kept are held in memory.
+func (dec *Decoder) Tail(n int) (recs *TLVList, err error) {
+	recs = New()
+	for {
+		var tlv TLV
+		if tlv, err = dec.Decode(); err != nil {
+			break
+		}
+		recs.records = append(recs.records, tlv)
+		if recs.Length() > n {
+			recs.records[0] = nil
+			recs.records = recs.records[1:]
+		}
+	}
+	return recs, eof(err)
+}
+
+// Sample decodes the rest of the stream, keeping each record with
+// probability rate. If rnd is nil, the default source from math/rand is
+// used.
+func (dec *Decoder) Sample(rate float64, rnd *rand.Rand) (recs *TLVList, err error) {
+	recs = New()
+	for {
+		var tlv TLV
+		if tlv, err = dec.Decode(); err != nil {
+			break
+		}
+		if keep(rate, rnd) {
+			recs.records = append(recs.records, tlv)
+		}
+	}
+	return recs, eof(err)
+}
@@ -0,0 +1,97 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func sampleTestList() *TLVList {
+	tlvl := New()
+	for i := 0; i < 100; i++ {
+		tlvl.Add(i, []byte{byte(i)})
+	}
+	return tlvl
+}
+
+func checkTags(t *testing.T, name string, recs *TLVList, tags ...int) {
+	if recs.Length() != len(tags) {
+		FailWithError(t, name,
+			fmt.Errorf("expected %d records, got %d",
+				len(tags), recs.Length()))
+	}
+
+	i := 0
+	for _, tlv := range recs.records {
+		if tlv.Tag() != tags[i] {
+			FailWithError(t, name,
+				fmt.Errorf("record %d has tag %d, expected %d",
+					i, tlv.Tag(), tags[i]))
+		}
+		i++
+	}
+}
+
+func TestHeadTail(t *testing.T) {
+	tlvl := sampleTestList()
+	checkTags(t, "TestHeadTail", tlvl.Head(3), 0, 1, 2)
+	checkTags(t, "TestHeadTail", tlvl.Tail(3), 97, 98, 99)
+	checkTags(t, "TestHeadTail", New().Head(3))
+
+	buf := new(bytes.Buffer)
+	if err := tlvl.Write(buf); err != nil {
+		FailWithError(t, "TestHeadTail", err)
+	}
+	raw := buf.Bytes()
+
+	dec := NewDecoder(bytes.NewReader(raw))
+	head, err := dec.Head(2)
+	if err != nil {
+		FailWithError(t, "TestHeadTail", err)
+	}
+	checkTags(t, "TestHeadTail", head, 0, 1)
+
+	tail, err := dec.Tail(2)
+	if err != nil {
+		FailWithError(t, "TestHeadTail", err)
+	}
+	checkTags(t, "TestHeadTail", tail, 98, 99)
+
+	if head, err = dec.Head(2); err != nil {
+		FailWithError(t, "TestHeadTail", err)
+	}
+	checkTags(t, "TestHeadTail", head)
+}
+
+func TestSample(t *testing.T) {
+	tlvl := sampleTestList()
+	if tlvl.Sample(1, nil).Length() != 100 {
+		FailWithError(t, "TestSample", noMatch)
+	} else if tlvl.Sample(0, nil).Length() != 0 {
+		FailWithError(t, "TestSample", noMatch)
+	}
+
+	a := tlvl.Sample(0.5, rand.New(rand.NewSource(1)))
+	b := tlvl.Sample(0.5, rand.New(rand.NewSource(1)))
+	if CompareOrder(a, b) != -1 {
+		FailWithError(t, "TestSample",
+			fmt.Errorf("samples with the same seed differ"))
+	} else if a.Length() < 25 || a.Length() > 75 {
+		FailWithError(t, "TestSample",
+			fmt.Errorf("implausible sample size %d", a.Length()))
+	}
+
+	buf := new(bytes.Buffer)
+	if err := tlvl.Write(buf); err != nil {
+		FailWithError(t, "TestSample", err)
+	}
+	dec := NewDecoder(buf)
+	c, err := dec.Sample(0.5, rand.New(rand.NewSource(1)))
+	if err != nil {
+		FailWithError(t, "TestSample", err)
+	} else if CompareOrder(a, c) != -1 {
+		FailWithError(t, "TestSample",
+			fmt.Errorf("stream and list samples differ"))
+	}
+}
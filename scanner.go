@@ -0,0 +1,137 @@
+package tlv
+
+import (
+	"bufio"
+	"io"
+)
+
+// Type Scanner reads the raw encoding of each record in a stream,
+// without constructing records. It is intended for hot paths that only
+// need to count, route, or forward records unchanged. Like
+// bufio.Scanner, it buffers its input and may read past the last record
+// it returns.
+type Scanner struct {
+	// Format is the wire format records are read in. If nil,
+	// Standard is used.
+	Format Format
+
+	// MaxLength, if nonzero, is the longest value the Scanner will
+	// accept.
+	MaxLength int
+
+	r   *recorder
+	tag int
+	err error
+}
+
+// NewScanner returns a new Scanner that reads from r.
+func NewScanner(r io.Reader) *Scanner {
+	return &Scanner{r: &recorder{r: bufio.NewReader(r)}}
+}
+
+// Scan advances the Scanner to the next record, which is then available
+// through the Bytes and Tag methods. It returns false at the end of the
+// input or on an error; Err reports which.
+func (s *Scanner) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+
+	format := s.Format
+	if format == nil {
+		format = Standard
+	}
+
+	var tag, length int
+	var err error
+	if f, ok := format.(Fixed); ok {
+		// Avoid the allocation made by going through the interface.
+		s.r.buf = append(s.r.buf[:0], make([]byte, f.size())...)
+		if _, err = io.ReadFull(s.r.r, s.r.buf); err == nil {
+			tag, length, err = f.parse(s.r.buf)
+		}
+	} else {
+		s.r.buf = s.r.buf[:0]
+		tag, length, err = format.ReadHeader(s.r)
+	}
+	if err == io.ErrUnexpectedEOF {
+		err = ErrTLVRead
+	} else if err == nil && length < 0 {
+		err = ErrLengthRange
+	} else if err == nil && s.MaxLength > 0 && length > s.MaxLength {
+		err = ErrTooLarge
+	}
+	if err != nil {
+		s.err = err
+		return false
+	}
+
+	buf, err := appendValue(s.r.buf, s.r.r, length)
+	if err != nil {
+		s.err = ErrTLVRead
+		return false
+	}
+	s.r.buf = buf
+	s.tag = tag
+	return true
+}
+
+// Bytes returns the encoding of the current record, header included.
+// The underlying array may be overwritten by the next call to Scan.
+func (s *Scanner) Bytes() []byte {
+	return s.r.buf
+}
+
+// Tag returns the tag of the current record.
+func (s *Scanner) Tag() int {
+	return s.tag
+}
+
+// Err returns the first error encountered by the Scanner, or nil if it
+// stopped at the end of the input.
+func (s *Scanner) Err() error {
+	if s.err == io.EOF {
+		return nil
+	}
+	return s.err
+}
+
+// ExtractRaw scans the rest of the input, returning the concatenated
+// encodings of the records with any of the given tags exactly as they
+// appeared in the input. Because the records are not re-encoded, a
+// signed sub-section of a stream can be forwarded without invalidating
+// its signature.
+func (s *Scanner) ExtractRaw(tags ...int) (raw []byte, err error) {
+	want := make(map[int]bool, len(tags))
+	for _, tag := range tags {
+		want[tag] = true
+	}
+
+	for s.Scan() {
+		if want[s.Tag()] {
+			raw = append(raw, s.Bytes()...)
+		}
+	}
+	return raw, s.Err()
+}
+
+// recorder keeps a copy of the bytes read through it, so the Scanner can
+// return record headers as they appeared on the wire.
+type recorder struct {
+	r   *bufio.Reader
+	buf []byte
+}
+
+func (rec *recorder) Read(p []byte) (n int, err error) {
+	n, err = rec.r.Read(p)
+	rec.buf = append(rec.buf, p[:n]...)
+	return
+}
+
+func (rec *recorder) ReadByte() (c byte, err error) {
+	c, err = rec.r.ReadByte()
+	if err == nil {
+		rec.buf = append(rec.buf, c)
+	}
+	return
+}
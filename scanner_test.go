@@ -0,0 +1,150 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestScanner(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(TagTest1, []byte("foo bar"))
+	tlvl.Add(TagTest2, nil)
+	tlvl.Add(TagTest3, []byte("gophers are everywhere!"))
+
+	buf := new(bytes.Buffer)
+	if err := tlvl.Write(buf); err != nil {
+		FailWithError(t, "TestScanner", err)
+	}
+	raw := buf.Bytes()
+
+	exts, err := Extents(bytes.NewReader(raw))
+	if err != nil {
+		FailWithError(t, "TestScanner", err)
+	}
+
+	s := NewScanner(bytes.NewReader(raw))
+	var n int
+	for ; s.Scan(); n++ {
+		ext := exts[n]
+		if s.Tag() != ext.Tag {
+			FailWithError(t, "TestScanner", noMatch)
+		} else if !bytes.Equal(s.Bytes(), raw[ext.Offset:ext.Offset+ext.Length]) {
+			FailWithError(t, "TestScanner", noMatch)
+		}
+	}
+	if err = s.Err(); err != nil {
+		FailWithError(t, "TestScanner", err)
+	} else if n != 3 {
+		FailWithError(t, "TestScanner",
+			fmt.Errorf("expected 3 records, got %d", n))
+	}
+
+	s = NewScanner(bytes.NewReader(raw[:len(raw)-1]))
+	for s.Scan() {
+	}
+	if s.Err() != ErrTLVRead {
+		FailWithError(t, "TestScanner",
+			fmt.Errorf("truncated input should fail"))
+	}
+
+	// A long record after a short one, read into a growing buffer.
+	long := New()
+	long.Add(TagTest1, []byte("foo"))
+	long.Add(TagTest2, bytes.Repeat([]byte("gopher"), maxEagerValue))
+	buf.Reset()
+	if err = long.Write(buf); err != nil {
+		FailWithError(t, "TestScanner", err)
+	}
+	s = NewScanner(bytes.NewReader(buf.Bytes()))
+	for n = 0; s.Scan(); n++ {
+		if rec, err := tlvFromBytes(s.Bytes()); err != nil {
+			FailWithError(t, "TestScanner", err)
+		} else if !Equals(rec, long.records[n]) {
+			FailWithError(t, "TestScanner", noMatch)
+		}
+	}
+	if err = s.Err(); err != nil || n != 2 {
+		FailWithError(t, "TestScanner", noMatch)
+	}
+
+	hostile := []byte{0, 0, 0, 1, 0x7f, 0xff, 0xff, 0xff, 'a', 'b'}
+	alloc := allocatedBy(func() {
+		s = NewScanner(bytes.NewReader(hostile))
+		for s.Scan() {
+		}
+	})
+	if s.Err() != ErrTLVRead {
+		FailWithError(t, "TestScanner",
+			fmt.Errorf("expected ErrTLVRead, got %v", s.Err()))
+	} else if alloc > 1<<20 {
+		FailWithError(t, "TestScanner",
+			fmt.Errorf("%d bytes allocated for a short input", alloc))
+	}
+}
+
+func TestExtractRaw(t *testing.T) {
+	// The second record uses a long-form length where a short one would
+	// do, so re-encoding it would change its bytes.
+	raw := []byte{
+		0x5a, 0x02, 0x12, 0x34,
+		0x5f, 0x24, 0x81, 0x03, 0x30, 0x31, 0x32,
+		0x9f, 0x02, 0x01, 0x00,
+		0x5f, 0x24, 0x01, 0x39,
+	}
+
+	s := NewScanner(bytes.NewReader(raw))
+	s.Format = BER
+	sub, err := s.ExtractRaw(0x5f24, 0x5a)
+	if err != nil {
+		FailWithError(t, "TestExtractRaw", err)
+	}
+
+	want := append(append([]byte{}, raw[:11]...), raw[15:]...)
+	if !bytes.Equal(sub, want) {
+		FailWithError(t, "TestExtractRaw",
+			fmt.Errorf("raw bytes were not preserved: % x", sub))
+	}
+}
+
+func benchmarkStream(b *testing.B) []byte {
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+	for i := 0; i < 1000; i++ {
+		if err := enc.Encode(newTLV(i%16, make([]byte, i%64))); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func BenchmarkScanner(b *testing.B) {
+	raw := benchmarkStream(b)
+	b.SetBytes(int64(len(raw)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s := NewScanner(bytes.NewReader(raw))
+		for s.Scan() {
+		}
+		if s.Err() != nil {
+			b.Fatal(s.Err())
+		}
+	}
+}
+
+func BenchmarkDecoder(b *testing.B) {
+	raw := benchmarkStream(b)
+	b.SetBytes(int64(len(raw)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dec := NewDecoder(bytes.NewReader(raw))
+		for {
+			if _, err := dec.Decode(); err == io.EOF {
+				break
+			} else if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
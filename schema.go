@@ -0,0 +1,187 @@
+package tlv
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// ErrRangeOverlap is returned when a tag range overlaps one that has
+// already been declared.
+var ErrRangeOverlap = fmt.Errorf("tag range overlaps an existing range")
+
+// Type Range is a named, inclusive range of tags, such as those set
+// aside for core, vendor, or experimental use.
+type Range struct {
+	Name string `json:"name"`
+	Min  int    `json:"min"`
+	Max  int    `json:"max"`
+}
+
+// Contains reports whether tag lies within the range.
+func (r Range) Contains(tag int) bool {
+	return tag >= r.Min && tag <= r.Max
+}
+
+// DeclareRange adds a tag range to the registry. Ranges must have
+// unique names and may not overlap.
+func (reg *Registry) DeclareRange(r Range) error {
+	if r.Min > r.Max {
+		return ErrTagRange
+	}
+	for _, other := range reg.ranges {
+		if other.Name == r.Name || (r.Min <= other.Max && other.Min <= r.Max) {
+			return ErrRangeOverlap
+		}
+	}
+	reg.ranges = append(reg.ranges, r)
+	return nil
+}
+
+// Ranges returns the tag ranges declared in the registry.
+func (reg *Registry) Ranges() []Range {
+	return append([]Range(nil), reg.ranges...)
+}
+
+// Type Constraint restricts the records carrying a tag. A required tag
+// must be present in every list; a MaxLength of zero leaves the length
+// of values unrestricted. A repeated tag may occur more than once in a
+// list.
+type Constraint struct {
+	Required  bool
+	MaxLength int
+	Repeated  bool
+}
+
+// Constrain sets the constraint on tag.
+func (reg *Registry) Constrain(tag int, c Constraint) {
+	if reg.constraints == nil {
+		reg.constraints = make(map[int]Constraint)
+	}
+	reg.constraints[tag] = c
+}
+
+// Constraint returns the constraint on tag; tags without one are
+// optional and unrestricted.
+func (reg *Registry) Constraint(tag int) Constraint {
+	return reg.constraints[tag]
+}
+
+// A schema file is the JSON serialization of a Registry.
+type schemaFile struct {
+	Ranges []Range     `json:"ranges,omitempty"`
+	Tags   []schemaTag `json:"tags"`
+}
+
+type schemaTag struct {
+	Tag  int             `json:"tag"`
+	Name string          `json:"name,omitempty"`
+	Desc string          `json:"description,omitempty"`
+	Enum map[byte]string `json:"enum,omitempty"`
+	Kind string          `json:"kind,omitempty"`
+	Size *int            `json:"size,omitempty"`
+
+	Required  bool `json:"required,omitempty"`
+	MaxLength int  `json:"max_length,omitempty"`
+	Repeated  bool `json:"repeated,omitempty"`
+}
+
+// WriteSchema writes the registry to w as a JSON schema file, which can
+// be read back with ReadSchema. Tags are written in ascending order.
+// Renderers are not part of the schema and are not written, except for
+// those set up by SetEnum.
+func (reg *Registry) WriteSchema(w io.Writer) error {
+	tags := make(map[int]*schemaTag)
+	entry := func(tag int) *schemaTag {
+		if tags[tag] == nil {
+			tags[tag] = &schemaTag{Tag: tag}
+		}
+		return tags[tag]
+	}
+	for tag, name := range reg.names {
+		entry(tag).Name = name
+	}
+	for tag, desc := range reg.descs {
+		entry(tag).Desc = desc
+	}
+	for tag, enum := range reg.enums {
+		entry(tag).Enum = enum
+	}
+	for tag, kind := range reg.kinds {
+		entry(tag).Kind = kind
+	}
+	for tag, size := range reg.sizes {
+		size := size
+		entry(tag).Size = &size
+	}
+	for tag, c := range reg.constraints {
+		if c != (Constraint{}) {
+			entry(tag).Required = c.Required
+			entry(tag).MaxLength = c.MaxLength
+			entry(tag).Repeated = c.Repeated
+		}
+	}
+
+	var sf schemaFile
+	sf.Ranges = reg.ranges
+	sf.Tags = make([]schemaTag, 0, len(tags))
+	for _, st := range tags {
+		sf.Tags = append(sf.Tags, *st)
+	}
+	sort.Slice(sf.Tags, func(i, j int) bool {
+		return sf.Tags[i].Tag < sf.Tags[j].Tag
+	})
+
+	out, err := json.MarshalIndent(sf, "", "\t")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(out, '\n'))
+	return err
+}
+
+// ReadSchema builds a Registry from a JSON schema file written by
+// WriteSchema.
+func ReadSchema(r io.Reader) (*Registry, error) {
+	var sf schemaFile
+	if err := json.NewDecoder(r).Decode(&sf); err != nil {
+		return nil, err
+	}
+
+	reg := NewRegistry()
+	for _, rng := range sf.Ranges {
+		if err := reg.DeclareRange(rng); err != nil {
+			return nil, err
+		}
+	}
+	for _, st := range sf.Tags {
+		if st.Name != "" {
+			if err := reg.Register(st.Tag, st.Name); err != nil {
+				return nil, err
+			}
+		}
+		if st.Desc != "" {
+			reg.SetDescription(st.Tag, st.Desc)
+		}
+		if st.Enum != nil {
+			if err := reg.SetEnum(st.Tag, st.Enum); err != nil {
+				return nil, err
+			}
+		}
+		if st.Kind != "" {
+			if err := reg.SetKind(st.Tag, st.Kind); err != nil {
+				return nil, err
+			}
+		}
+		if st.Size != nil {
+			if err := reg.SetSize(st.Tag, *st.Size); err != nil {
+				return nil, err
+			}
+		}
+		if st.Required || st.MaxLength != 0 || st.Repeated {
+			reg.Constrain(st.Tag, Constraint{st.Required, st.MaxLength, st.Repeated})
+		}
+	}
+	return reg, nil
+}
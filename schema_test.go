@@ -0,0 +1,47 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	reg := testRegistry()
+	if err := reg.DeclareRange(Range{"core", 0, 99}); err != nil {
+		FailWithError(t, "TestSchema", err)
+	} else if err = reg.DeclareRange(Range{"vendor", 50, 199}); err != ErrRangeOverlap {
+		FailWithError(t, "TestSchema",
+			fmt.Errorf("overlapping range should be rejected"))
+	} else if err = reg.DeclareRange(Range{"vendor", 100, 199}); err != nil {
+		FailWithError(t, "TestSchema", err)
+	}
+	reg.SetDescription(TagTest1, "The first test tag")
+	reg.Constrain(TagTest2, Constraint{Repeated: true})
+
+	buf := new(bytes.Buffer)
+	if err := reg.WriteSchema(buf); err != nil {
+		FailWithError(t, "TestSchema", err)
+	}
+
+	rreg, err := ReadSchema(buf)
+	if err != nil {
+		FailWithError(t, "TestSchema", err)
+	} else if len(rreg.Ranges()) != 2 {
+		FailWithError(t, "TestSchema",
+			fmt.Errorf("ranges were not preserved"))
+	}
+	for tag, name := range reg.names {
+		if rname, ok := rreg.Name(tag); !ok || rname != name {
+			FailWithError(t, "TestSchema",
+				fmt.Errorf("tag %d was not preserved", tag))
+		}
+	}
+	if desc, _ := rreg.Description(TagTest1); desc != "The first test tag" {
+		FailWithError(t, "TestSchema",
+			fmt.Errorf("description was not preserved"))
+	} else if !rreg.Constraint(TagTest2).Repeated {
+		FailWithError(t, "TestSchema",
+			fmt.Errorf("constraint was not preserved"))
+	}
+}
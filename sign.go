@@ -0,0 +1,94 @@
+package tlv
+
+import (
+	"fmt"
+	"hash"
+)
+
+// TagSignature is the tag of the trailing record holding the signature
+// of a signed stream.
+const TagSignature = 0x7fffff01
+
+// ErrUnsigned is returned when a Decoder that is verifying signatures
+// reaches the end of a stream without finding a signature record.
+var ErrUnsigned = fmt.Errorf("TLV stream is not signed")
+
+// ErrHashMismatch is returned when a stream was signed with a different
+// hash algorithm from the one it is being verified with.
+var ErrHashMismatch = fmt.Errorf("TLV stream signed with a different hash algorithm")
+
+// Sign arranges for the records written by the Encoder to be signed. As
+// each record is written, its encoding is added to h; Close then passes
+// the digest to sign, and writes the signature it returns as a final
+// TagSignature record. Sign must be called before any records have been
+// written.
+func (enc *Encoder) Sign(h hash.Hash, sign func(digest []byte) ([]byte, error)) {
+	enc.digest = h
+	enc.sign = sign
+}
+
+// Verify arranges for the Decoder to verify a signed stream in a single
+// pass. As each record is decoded, its encoding is added to h. When the
+// TagSignature record is reached, verify is called with the digest and
+// the signature, and if it succeeds, Decode returns io.EOF. If the stream
+// ends without a signature, ErrUnsigned is returned instead.
+//
+// Records are returned as they are decoded, before the signature has
+// been checked; they should not be trusted until Decode has returned
+// io.EOF. Verify must be called before any records have been decoded.
+func (dec *Decoder) Verify(h hash.Hash, verify func(digest, sig []byte) error) {
+	dec.digest = h
+	dec.verify = verify
+}
+
+// SignWith is Sign using the registered hash algorithm id. The
+// algorithm's HashID is recorded as the first byte of the signature
+// record, ahead of the signature itself, so that the stream states how
+// it was signed. ErrUnknownHash is returned if id is not registered.
+func (enc *Encoder) SignWith(id HashID, sign func(digest []byte) ([]byte, error)) error {
+	h, err := NewHash(id)
+	if err != nil {
+		return err
+	}
+	enc.Sign(h, func(digest []byte) ([]byte, error) {
+		sig, err := sign(digest)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{byte(id)}, sig...), nil
+	})
+	return nil
+}
+
+// VerifyWith is Verify for a stream written with SignWith, using the
+// registered hash algorithm id. If the signature record names a
+// different algorithm, ErrHashMismatch is returned without calling
+// verify; otherwise verify is passed the signature without the HashID.
+// ErrUnknownHash is returned if id is not registered.
+func (dec *Decoder) VerifyWith(id HashID, verify func(digest, sig []byte) error) error {
+	h, err := NewHash(id)
+	if err != nil {
+		return err
+	}
+	dec.Verify(h, func(digest, sig []byte) error {
+		if len(sig) == 0 || HashID(sig[0]) != id {
+			return ErrHashMismatch
+		}
+		return verify(digest, sig[1:])
+	})
+	return nil
+}
+
+// checkSignature handles a record decoded from a stream being verified,
+// whose header was read as header. It returns true once the signature
+// record has been verified.
+func (dec *Decoder) checkSignature(tlv TLV, header []byte) (done bool, err error) {
+	if tlv.Tag() == TagSignature {
+		dec.verified = true
+		return true, dec.verify(dec.digest.Sum(nil), tlv.Value())
+	}
+
+	dec.digest.Write(header)
+	dec.digest.Write(tlv.Value())
+	return
+}
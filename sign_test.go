@@ -0,0 +1,188 @@
+package tlv
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"testing"
+)
+
+var errBadSignature = fmt.Errorf("bad signature")
+
+func signTestStream(t *testing.T) (raw []byte, pub ed25519.PublicKey) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		FailWithError(t, "signTestStream", err)
+	}
+
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+	enc.Sign(sha256.New(), func(digest []byte) ([]byte, error) {
+		return ed25519.Sign(priv, digest), nil
+	})
+	for i := 0; i < 16; i++ {
+		err = enc.Encode(newTLV(i, bytes.Repeat([]byte{byte(i)}, i*i)))
+		if err != nil {
+			FailWithError(t, "signTestStream", err)
+		}
+	}
+	if err = enc.Close(); err != nil {
+		FailWithError(t, "signTestStream", err)
+	}
+	return buf.Bytes(), pub
+}
+
+func verifyTestStream(raw []byte, pub ed25519.PublicKey) (int, error) {
+	dec := NewDecoder(bytes.NewReader(raw))
+	dec.Verify(sha256.New(), func(digest, sig []byte) error {
+		if !ed25519.Verify(pub, digest, sig) {
+			return errBadSignature
+		}
+		return nil
+	})
+
+	var n int
+	for {
+		if _, err := dec.Decode(); err == io.EOF {
+			return n, nil
+		} else if err != nil {
+			return n, err
+		}
+		n++
+	}
+}
+
+func TestSignedStream(t *testing.T) {
+	raw, pub := signTestStream(t)
+
+	if n, err := verifyTestStream(raw, pub); err != nil {
+		FailWithError(t, "TestSignedStream", err)
+	} else if n != 16 {
+		FailWithError(t, "TestSignedStream",
+			fmt.Errorf("expected 16 records, got %d", n))
+	}
+
+	tampered := append([]byte{}, raw...)
+	tampered[len(tampered)-100] ^= 1
+	if _, err := verifyTestStream(tampered, pub); err != errBadSignature {
+		FailWithError(t, "TestSignedStream",
+			fmt.Errorf("tampered stream should not verify"))
+	}
+
+	exts, err := Extents(bytes.NewReader(raw))
+	if err != nil {
+		FailWithError(t, "TestSignedStream", err)
+	}
+	trailer := exts[len(exts)-1]
+	if trailer.Tag != TagSignature {
+		FailWithError(t, "TestSignedStream",
+			fmt.Errorf("stream should end with a signature"))
+	}
+	unsigned := raw[:trailer.Offset]
+	if _, err = verifyTestStream(unsigned, pub); err != ErrUnsigned {
+		FailWithError(t, "TestSignedStream",
+			fmt.Errorf("unsigned stream should not verify"))
+	}
+}
+
+func TestSignWith(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		FailWithError(t, "TestSignWith", err)
+	}
+
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+	err = enc.SignWith(HashSHA256, func(digest []byte) ([]byte, error) {
+		return ed25519.Sign(priv, digest), nil
+	})
+	if err != nil {
+		FailWithError(t, "TestSignWith", err)
+	} else if err = enc.Encode(newTLV(TagTest1, []byte("signed"))); err != nil {
+		FailWithError(t, "TestSignWith", err)
+	} else if err = enc.Close(); err != nil {
+		FailWithError(t, "TestSignWith", err)
+	}
+	raw := buf.Bytes()
+
+	verify := func(id HashID) error {
+		dec := NewDecoder(bytes.NewReader(raw))
+		err := dec.VerifyWith(id, func(digest, sig []byte) error {
+			if !ed25519.Verify(pub, digest, sig) {
+				return errBadSignature
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		_, err = dec.DecodeList()
+		return err
+	}
+	if err = verify(HashSHA256); err != nil {
+		FailWithError(t, "TestSignWith", err)
+	} else if err = verify(HashCRC32C); err != ErrHashMismatch {
+		FailWithError(t, "TestSignWith",
+			fmt.Errorf("expected ErrHashMismatch, got %v", err))
+	} else if err = verify(HashBLAKE2b); err != ErrUnknownHash {
+		FailWithError(t, "TestSignWith",
+			fmt.Errorf("expected ErrUnknownHash, got %v", err))
+	}
+
+	// The signature record starts with the algorithm's HashID.
+	recs, err := Read(bytes.NewReader(raw))
+	if err != nil && err != io.EOF {
+		FailWithError(t, "TestSignWith", err)
+	} else if sig, err := recs.Get(TagSignature); err != nil {
+		FailWithError(t, "TestSignWith", err)
+	} else if HashID(sig.Value()[0]) != HashSHA256 {
+		FailWithError(t, "TestSignWith", noMatch)
+	}
+}
+
+func TestSignedHeaderBytes(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		FailWithError(t, "TestSignedHeaderBytes", err)
+	}
+
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+	enc.Format = Varint
+	enc.Sign(sha256.New(), func(digest []byte) ([]byte, error) {
+		return ed25519.Sign(priv, digest), nil
+	})
+	if err = enc.Encode(newTLV(1, []byte("abc"))); err != nil {
+		FailWithError(t, "TestSignedHeaderBytes", err)
+	} else if err = enc.Close(); err != nil {
+		FailWithError(t, "TestSignedHeaderBytes", err)
+	}
+
+	verify := func(raw []byte) error {
+		dec := NewDecoder(bytes.NewReader(raw))
+		dec.Format = Varint
+		dec.Verify(sha256.New(), func(digest, sig []byte) error {
+			if !ed25519.Verify(pub, digest, sig) {
+				return errBadSignature
+			}
+			return nil
+		})
+		_, err := dec.DecodeList()
+		return err
+	}
+	raw := buf.Bytes()
+	if err = verify(raw); err != nil {
+		FailWithError(t, "TestSignedHeaderBytes", err)
+	}
+
+	// The same record with its length written in two bytes decodes
+	// the same, but is not what was signed.
+	tampered := append([]byte{0x01, 0x83, 0x00}, raw[2:]...)
+	if err = verify(tampered); err != errBadSignature {
+		FailWithError(t, "TestSignedHeaderBytes",
+			fmt.Errorf("expected errBadSignature, got %v", err))
+	}
+}
@@ -0,0 +1,51 @@
+package tlv
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// SimpleTLV is the ISO/IEC 7816-4 SIMPLE-TLV format used by smartcard
+// data objects: a one-byte tag followed by a length of either one byte,
+// for lengths up to 254, or the byte 0xff and a 16-bit big-endian
+// length. Tags must be between 1 and 254, and values may be at most
+// 65535 bytes long.
+var SimpleTLV Format = simpleFormat{}
+
+type simpleFormat struct{}
+
+func (simpleFormat) ReadHeader(r io.Reader) (tag, length int, err error) {
+	var hdr [4]byte
+	if _, err = io.ReadFull(r, hdr[:2]); err != nil {
+		return
+	} else if hdr[0] == 0x00 || hdr[0] == 0xff {
+		return 0, 0, ErrTagRange
+	}
+
+	tag, length = int(hdr[0]), int(hdr[1])
+	if length != 0xff {
+		return
+	}
+
+	if _, err = io.ReadFull(r, hdr[2:]); err != nil {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	return tag, int(binary.BigEndian.Uint16(hdr[2:])), nil
+}
+
+func (simpleFormat) WriteHeader(w io.Writer, tag, length int) error {
+	if tag < 0x01 || tag > 0xfe {
+		return ErrTagRange
+	} else if length < 0 || length > 0xffff {
+		return ErrLengthRange
+	}
+
+	if length < 0xff {
+		_, err := w.Write([]byte{byte(tag), byte(length)})
+		return err
+	}
+	hdr := []byte{byte(tag), 0xff, 0, 0}
+	binary.BigEndian.PutUint16(hdr[2:], uint16(length))
+	_, err := w.Write(hdr)
+	return err
+}
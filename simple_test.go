@@ -0,0 +1,38 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestSimpleTLV(t *testing.T) {
+	for _, length := range []int{0, 1, 0xfe, 0xff, 0x100, 0xffff} {
+		tlvl := New()
+		tlvl.Add(0x42, make([]byte, length))
+		raw := encodeFormat(t, "TestSimpleTLV", SimpleTLV, tlvl)
+		if (length < 0xff && len(raw) != 2+length) || (length >= 0xff && len(raw) != 4+length) {
+			FailWithError(t, "TestSimpleTLV",
+				fmt.Errorf("bad header size for length %d", length))
+		}
+
+		rtlvl := decodeFormat(t, "TestSimpleTLV", SimpleTLV, raw)
+		if rec, err := rtlvl.Get(0x42); err != nil {
+			FailWithError(t, "TestSimpleTLV", err)
+		} else if rec.Length() != length {
+			FailWithError(t, "TestSimpleTLV", noMatch)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := SimpleTLV.WriteHeader(buf, 0xff, 0); err != ErrTagRange {
+		FailWithError(t, "TestSimpleTLV",
+			fmt.Errorf("tag 0xff should be rejected"))
+	} else if err = SimpleTLV.WriteHeader(buf, 0x01, 0x10000); err != ErrLengthRange {
+		FailWithError(t, "TestSimpleTLV",
+			fmt.Errorf("length 0x10000 should be rejected"))
+	} else if _, _, err = SimpleTLV.ReadHeader(bytes.NewReader([]byte{0x00, 0x00})); err != ErrTagRange {
+		FailWithError(t, "TestSimpleTLV",
+			fmt.Errorf("tag 0x00 should be rejected"))
+	}
+}
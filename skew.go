@@ -0,0 +1,50 @@
+package tlv
+
+import "fmt"
+
+// ErrSkew is returned by a Decoder when a timestamp record goes back in
+// time by more than the Decoder's MaxSkew.
+var ErrSkew = fmt.Errorf("TLV timestamp out of order")
+
+// Type SkewPolicy determines whether a Decoder checks that the
+// timestamp records in a stream are in order, and what it does with
+// those that are not.
+type SkewPolicy int
+
+const (
+	// SkewIgnore disables the check.
+	SkewIgnore SkewPolicy = iota
+
+	// SkewReject causes Decode to fail with ErrSkew on a timestamp
+	// record that goes back by more than MaxSkew.
+	SkewReject
+
+	// SkewFlag marks the batch of records following such a timestamp
+	// record as skewed, which can be checked with the Decoder's Skewed
+	// method, and lets decoding continue.
+	SkewFlag
+)
+
+// checkSkew compares a timestamp record with the latest timestamp seen.
+func (dec *Decoder) checkSkew(rec TLV) error {
+	t, ok := IsTimestamp(rec)
+	if !ok {
+		return ErrSkew
+	}
+
+	dec.skewed = !dec.latest.IsZero() && dec.latest.Sub(t) > dec.MaxSkew
+	if dec.skewed && dec.Skew == SkewReject {
+		return ErrSkew
+	} else if t.After(dec.latest) {
+		dec.latest = t
+	}
+	return nil
+}
+
+// Skewed reports whether the records being decoded follow a timestamp
+// record that was out of order by more than MaxSkew, when the Decoder's
+// Skew policy is SkewFlag. A batch ends at the next timestamp record
+// that is in order.
+func (dec *Decoder) Skewed() bool {
+	return dec.skewed
+}
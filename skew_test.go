@@ -0,0 +1,62 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func skewLog(t *testing.T) []byte {
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	tlvl := New()
+	tlvl.records = append(tlvl.records, Timestamp(base))
+	tlvl.Add(TagTest1, []byte("a"))
+	tlvl.records = append(tlvl.records, Timestamp(base.Add(time.Minute)))
+	tlvl.Add(TagTest1, []byte("b"))
+	tlvl.records = append(tlvl.records, Timestamp(base.Add(50*time.Second)))
+	tlvl.Add(TagTest1, []byte("c"))
+	tlvl.records = append(tlvl.records, Timestamp(base.Add(2*time.Minute)))
+	tlvl.Add(TagTest1, []byte("d"))
+
+	buf := new(bytes.Buffer)
+	if err := tlvl.Write(buf); err != nil {
+		FailWithError(t, "skewLog", err)
+	}
+	return buf.Bytes()
+}
+
+func TestSkew(t *testing.T) {
+	raw := skewLog(t)
+
+	// Ten seconds back is within a 15 second skew.
+	dec := NewDecoder(bytes.NewReader(raw))
+	dec.Skew, dec.MaxSkew = SkewReject, 15*time.Second
+	if _, err := dec.DecodeList(); err != nil {
+		FailWithError(t, "TestSkew", err)
+	}
+
+	dec = NewDecoder(bytes.NewReader(raw))
+	dec.Skew, dec.MaxSkew = SkewReject, 5*time.Second
+	if _, err := dec.DecodeList(); err != ErrSkew {
+		FailWithError(t, "TestSkew",
+			fmt.Errorf("expected ErrSkew, got %v", err))
+	}
+
+	dec = NewDecoder(bytes.NewReader(raw))
+	dec.Skew = SkewFlag
+	var skewed string
+	for {
+		rec, err := dec.Decode()
+		if err != nil {
+			break
+		}
+		if rec.Tag() == TagTest1 && dec.Skewed() {
+			skewed += string(rec.Value())
+		}
+	}
+	if skewed != "c" {
+		FailWithError(t, "TestSkew",
+			fmt.Errorf("flagged %q, expected \"c\"", skewed))
+	}
+}
@@ -0,0 +1,226 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrSNMP is returned when an SNMP message or value is malformed.
+var ErrSNMP = fmt.Errorf("invalid SNMP message")
+
+// The BER tags used by SNMP (RFC 3416 and RFC 2578): the universal
+// types, the SMI application types, the exception values, and the PDUs.
+// All are single identifier octets.
+const (
+	SNMPInteger     = 0x02
+	SNMPOctetString = 0x04
+	SNMPNull        = 0x05
+	SNMPObjectID    = 0x06
+	SNMPSequence    = 0x30
+
+	SNMPIPAddress = 0x40
+	SNMPCounter32 = 0x41
+	SNMPGauge32   = 0x42
+	SNMPTimeTicks = 0x43
+	SNMPOpaque    = 0x44
+	SNMPCounter64 = 0x46
+
+	SNMPNoSuchObject   = 0x80
+	SNMPNoSuchInstance = 0x81
+	SNMPEndOfMibView   = 0x82
+
+	SNMPGetRequest     = 0xa0
+	SNMPGetNextRequest = 0xa1
+	SNMPResponse       = 0xa2
+	SNMPSetRequest     = 0xa3
+	SNMPGetBulkRequest = 0xa5
+	SNMPInformRequest  = 0xa6
+	SNMPTrap           = 0xa7
+	SNMPReport         = 0xa8
+)
+
+// DecodeSNMP decodes an SNMP message, returning its top-level records;
+// a well-formed message is a single SEQUENCE. The reader is constrained
+// to what SNMP uses: every tag must be a single identifier octet, and
+// every constructed record must hold well-formed records, down to the
+// leaves, or ErrSNMP is returned. Constructed records are returned as
+// Constructed records holding their children, which are also returned by
+// SNMPChildren; EncodeSNMP writes them back in BER.
+func DecodeSNMP(msg []byte) (*TLVList, error) {
+	recs, err := decodeSNMP(msg, 0)
+	if err != nil {
+		return nil, err
+	}
+	return recs, nil
+}
+
+// maxSNMPDepth bounds the nesting of an SNMP message; real messages
+// nest no more than a handful of levels.
+const maxSNMPDepth = 16
+
+func decodeSNMP(data []byte, depth int) (*TLVList, error) {
+	if depth > maxSNMPDepth {
+		return nil, ErrSNMP
+	}
+
+	dec := NewDecoder(bytes.NewReader(data))
+	dec.Format = BER
+	recs, err := dec.DecodeList()
+	if err != nil {
+		return nil, ErrSNMP
+	}
+	for i, tlv := range recs.records {
+		if tlv.Tag() > 0xff {
+			return nil, ErrSNMP
+		} else if berConstructed(tlv.Tag()) {
+			children, err := decodeSNMP(tlv.Value(), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			recs.records[i] = NewConstructed(tlv.Tag(), children)
+		}
+	}
+	return recs, nil
+}
+
+// SNMPChildren returns the records held by a constructed record of an
+// SNMP message, such as a SEQUENCE or a PDU. For a Constructed record,
+// such as those DecodeSNMP returns, the list is the record's own, and
+// changes to it change the record; otherwise the value is decoded.
+func SNMPChildren(rec TLV) (*TLVList, error) {
+	if rec.Tag() > 0xff || !berConstructed(rec.Tag()) {
+		return nil, ErrSNMP
+	}
+	if c, ok := rec.(Constructed); ok {
+		return c.Children(), nil
+	}
+	return decodeSNMP(rec.Value(), 1)
+}
+
+// SNMPConstructed builds a constructed record, such as a SEQUENCE or a
+// PDU, holding children. The list is not copied.
+func SNMPConstructed(tag int, children *TLVList) (Constructed, error) {
+	if tag < 0 || tag > 0xff || !berConstructed(tag) {
+		return nil, ErrSNMP
+	}
+	if _, err := EncodeSNMP(children); err != nil {
+		return nil, err
+	}
+	return NewConstructed(tag, children), nil
+}
+
+// EncodeSNMP encodes recs in BER, as an SNMP message or part of one.
+// Lengths are written in their shortest form, so messages that used
+// longer length encodings are not reproduced byte for byte.
+func EncodeSNMP(recs *TLVList) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+	enc.Format = BER
+	if err := enc.EncodeList(recs); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SNMPIntegerValue returns the value of an INTEGER, or of an
+// application type holding an unsigned integer, such as a Counter32 or
+// TimeTicks. Values must fit in an int64.
+func SNMPIntegerValue(rec TLV) (int64, error) {
+	value := rec.Value()
+	if len(value) == 0 || len(value) > 8 {
+		return 0, ErrSNMP
+	}
+
+	var n int64
+	if value[0]&0x80 != 0 {
+		n = -1
+	}
+	for _, b := range value {
+		n = n<<8 | int64(b)
+	}
+	return n, nil
+}
+
+// SNMPIntegerRecord builds an INTEGER, or an application integer type
+// given by tag, holding n in the fewest octets.
+func SNMPIntegerRecord(tag int, n int64) TLV {
+	value := []byte{byte(n)}
+	for m := n >> 8; ; m >>= 8 {
+		if (m == 0 && value[0]&0x80 == 0) || (m == -1 && value[0]&0x80 != 0) {
+			break
+		}
+		value = append([]byte{byte(m)}, value...)
+	}
+	return newTLV(tag, value)
+}
+
+// SNMPObjectIDValue returns the OBJECT IDENTIFIER held in rec in dotted
+// form, such as "1.3.6.1.2.1.1.1.0".
+func SNMPObjectIDValue(rec TLV) (string, error) {
+	value := rec.Value()
+	if rec.Tag() != SNMPObjectID || len(value) == 0 || value[len(value)-1]&0x80 != 0 {
+		return "", ErrSNMP
+	}
+
+	var arcs []string
+	var arc uint64
+	for _, b := range value {
+		if arc > 1<<56 {
+			return "", ErrSNMP
+		}
+		arc = arc<<7 | uint64(b&0x7f)
+		if b&0x80 != 0 {
+			continue
+		}
+		if len(arcs) == 0 {
+			first := arc / 40
+			if first > 2 {
+				first = 2
+			}
+			arcs = append(arcs, strconv.FormatUint(first, 10))
+			arc -= first * 40
+		}
+		arcs = append(arcs, strconv.FormatUint(arc, 10))
+		arc = 0
+	}
+	return strings.Join(arcs, "."), nil
+}
+
+// SNMPObjectIDRecord builds an OBJECT IDENTIFIER record from its dotted
+// form.
+func SNMPObjectIDRecord(oid string) (TLV, error) {
+	parts := strings.Split(oid, ".")
+	if len(parts) < 2 {
+		return nil, ErrSNMP
+	}
+	arcs := make([]uint64, len(parts))
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 56)
+		if err != nil {
+			return nil, ErrSNMP
+		}
+		arcs[i] = n
+	}
+	if arcs[0] > 2 || (arcs[0] < 2 && arcs[1] >= 40) {
+		return nil, ErrSNMP
+	}
+
+	arcs = append([]uint64{arcs[0]*40 + arcs[1]}, arcs[2:]...)
+	var value []byte
+	for _, arc := range arcs {
+		n := 1
+		for a := arc >> 7; a != 0; a >>= 7 {
+			n++
+		}
+		for i := n - 1; i >= 0; i-- {
+			b := byte(arc>>uint(7*i)) & 0x7f
+			if i > 0 {
+				b |= 0x80
+			}
+			value = append(value, b)
+		}
+	}
+	return newTLV(SNMPObjectID, value), nil
+}
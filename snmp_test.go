@@ -0,0 +1,109 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// An SNMPv2c GetRequest for sysDescr.0 with community "public".
+var snmpGet = []byte{
+	0x30, 0x29,
+	0x02, 0x01, 0x01,
+	0x04, 0x06, 'p', 'u', 'b', 'l', 'i', 'c',
+	0xa0, 0x1c,
+	0x02, 0x04, 0x12, 0x34, 0x56, 0x78,
+	0x02, 0x01, 0x00,
+	0x02, 0x01, 0x00,
+	0x30, 0x0e,
+	0x30, 0x0c,
+	0x06, 0x08, 0x2b, 0x06, 0x01, 0x02, 0x01, 0x01, 0x01, 0x00,
+	0x05, 0x00,
+}
+
+func TestSNMP(t *testing.T) {
+	top, err := DecodeSNMP(snmpGet)
+	if err != nil {
+		FailWithError(t, "TestSNMP", err)
+	}
+	msg, err := top.Get(SNMPSequence)
+	if err != nil {
+		FailWithError(t, "TestSNMP", err)
+	} else if _, ok := msg.(Constructed); !ok {
+		FailWithError(t, "TestSNMP", noMatch)
+	}
+	fields, err := SNMPChildren(msg)
+	if err != nil {
+		FailWithError(t, "TestSNMP", err)
+	}
+	pdu, err := fields.Get(SNMPGetRequest)
+	if err != nil {
+		FailWithError(t, "TestSNMP", err)
+	}
+	pduFields, err := SNMPChildren(pdu)
+	if err != nil {
+		FailWithError(t, "TestSNMP", err)
+	}
+	if id, err := SNMPIntegerValue(pduFields.GetAll(SNMPInteger)[0]); err != nil || id != 0x12345678 {
+		FailWithError(t, "TestSNMP", noMatch)
+	}
+
+	varbinds, _ := pduFields.Get(SNMPSequence)
+	list, _ := SNMPChildren(varbinds)
+	bind, _ := list.Get(SNMPSequence)
+	pair, err := SNMPChildren(bind)
+	if err != nil {
+		FailWithError(t, "TestSNMP", err)
+	}
+	oid, _ := pair.Get(SNMPObjectID)
+	if s, err := SNMPObjectIDValue(oid); err != nil || s != "1.3.6.1.2.1.1.1.0" {
+		FailWithError(t, "TestSNMP",
+			fmt.Errorf("OID is %q", s))
+	}
+
+	// The decoded message encodes back as it was.
+	if raw, err := EncodeSNMP(top); err != nil {
+		FailWithError(t, "TestSNMP", err)
+	} else if !bytes.Equal(raw, snmpGet) {
+		FailWithError(t, "TestSNMP", noMatch)
+	}
+
+	// Rebuild the message from its parts.
+	rebuilt, err := SNMPConstructed(SNMPSequence, fields)
+	if err != nil {
+		FailWithError(t, "TestSNMP", err)
+	}
+	out := New()
+	out.AddRecord(rebuilt)
+	raw, err := EncodeSNMP(out)
+	if err != nil {
+		FailWithError(t, "TestSNMP", err)
+	} else if !bytes.Equal(raw, snmpGet) {
+		FailWithError(t, "TestSNMP", noMatch)
+	}
+
+	if _, err = DecodeSNMP(snmpGet[:20]); err != ErrSNMP {
+		FailWithError(t, "TestSNMP",
+			fmt.Errorf("expected ErrSNMP, got %v", err))
+	}
+}
+
+func TestSNMPValues(t *testing.T) {
+	for _, n := range []int64{0, 127, 128, -1, -128, -129, 1 << 40} {
+		rec := SNMPIntegerRecord(SNMPInteger, n)
+		if v, err := SNMPIntegerValue(rec); err != nil || v != n {
+			FailWithError(t, "TestSNMPValues",
+				fmt.Errorf("%d came back as %d (% x)", n, v, rec.Value()))
+		}
+	}
+	if rec := SNMPIntegerRecord(SNMPInteger, 128); !bytes.Equal(rec.Value(), []byte{0x00, 0x80}) {
+		FailWithError(t, "TestSNMPValues", noMatch)
+	}
+
+	rec, err := SNMPObjectIDRecord("1.3.6.1.4.1.2021.10.1.3.1")
+	if err != nil {
+		FailWithError(t, "TestSNMPValues", err)
+	} else if s, err := SNMPObjectIDValue(rec); err != nil || s != "1.3.6.1.4.1.2021.10.1.3.1" {
+		FailWithError(t, "TestSNMPValues", noMatch)
+	}
+}
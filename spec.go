@@ -0,0 +1,127 @@
+package tlv
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrSpec is returned by ParseSpec when a spec is malformed.
+var ErrSpec = fmt.Errorf("invalid record spec")
+
+// ParseSpec builds a TLVList from a terse textual description of its
+// records, for use in test fixtures and command-line tools. A spec is a
+// comma-separated list of records, each written as tag=kind:value, such
+// as
+//
+//	5=hex:a1b2, 7=str:hello, 9=u32:1234
+//
+// Tags may be decimal or, with a 0x prefix, hexadecimal. The kinds are:
+//
+//	hex            the value in hexadecimal
+//	str            the value as text, which may be a Go quoted string
+//	u8, u16, u32, u64
+//	               an unsigned integer, written big-endian in the
+//	               given number of bits
+func ParseSpec(spec string) (*TLVList, error) {
+	recs := New()
+	rest := strings.TrimSpace(spec)
+	for rest != "" {
+		eq := strings.IndexByte(rest, '=')
+		colon := strings.IndexByte(rest, ':')
+		if eq < 0 || colon < eq {
+			return nil, ErrSpec
+		}
+		tag, err := strconv.ParseInt(strings.TrimSpace(rest[:eq]), 0, 32)
+		if err != nil {
+			return nil, ErrSpec
+		}
+		kind := strings.TrimSpace(rest[eq+1 : colon])
+		rest = strings.TrimLeft(rest[colon+1:], " \t")
+
+		var text string
+		if strings.HasPrefix(rest, `"`) && kind == "str" {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return nil, ErrSpec
+			}
+			text, _ = strconv.Unquote(quoted)
+			rest = strings.TrimSpace(rest[len(quoted):])
+			if rest != "" && rest[0] != ',' {
+				return nil, ErrSpec
+			}
+		} else {
+			end := strings.IndexByte(rest, ',')
+			if end < 0 {
+				end = len(rest)
+			}
+			text, rest = strings.TrimSpace(rest[:end]), rest[end:]
+		}
+		if rest != "" {
+			rest = strings.TrimSpace(rest[1:])
+			if rest == "" {
+				return nil, ErrSpec
+			}
+		}
+
+		value, err := specValue(kind, text)
+		if err != nil {
+			return nil, err
+		}
+		recs.Add(int(tag), value)
+	}
+	return recs, nil
+}
+
+func specValue(kind, text string) ([]byte, error) {
+	bits := map[string]int{"u8": 8, "u16": 16, "u32": 32, "u64": 64}
+	switch kind {
+	case "hex":
+		value, err := hex.DecodeString(text)
+		if err != nil {
+			return nil, ErrSpec
+		}
+		return value, nil
+	case "str":
+		return []byte(text), nil
+	case "u8", "u16", "u32", "u64":
+		n, err := strconv.ParseUint(text, 0, bits[kind])
+		if err != nil {
+			return nil, ErrSpec
+		}
+		var value [8]byte
+		binary.BigEndian.PutUint64(value[:], n)
+		return value[8-bits[kind]/8:], nil
+	}
+	return nil, ErrSpec
+}
+
+// Spec returns a spec describing the TLVList that ParseSpec would turn
+// back into an identical list. Values that are printable text are
+// written as str, quoted if necessary, and all others as hex.
+func (recs *TLVList) Spec() string {
+	var parts []string
+	for _, tlv := range recs.records {
+		parts = append(parts, fmt.Sprintf("%d=%s", tlv.Tag(), specText(tlv.Value())))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func specText(value []byte) string {
+	s := string(value)
+	if len(s) == 0 || !utf8.ValidString(s) {
+		return "hex:" + hex.EncodeToString(value)
+	}
+	for _, r := range s {
+		if !strconv.IsPrint(r) {
+			return "hex:" + hex.EncodeToString(value)
+		}
+	}
+	if strings.ContainsAny(s, `,"`) || strings.TrimSpace(s) != s {
+		return "str:" + strconv.Quote(s)
+	}
+	return "str:" + s
+}
@@ -0,0 +1,50 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestParseSpec(t *testing.T) {
+	recs, err := ParseSpec(`5=hex:a1b2, 7=str:hello, 9=u32:1234, 0x10=str:"a, b", 3=u8:0xff, 4=hex:`)
+	if err != nil {
+		FailWithError(t, "TestParseSpec", err)
+	}
+	checkTags(t, "TestParseSpec", recs, 5, 7, 9, 16, 3, 4)
+
+	for tag, want := range map[int][]byte{
+		5:  {0xa1, 0xb2},
+		7:  []byte("hello"),
+		9:  {0x00, 0x00, 0x04, 0xd2},
+		16: []byte("a, b"),
+		3:  {0xff},
+		4:  {},
+	} {
+		if rec, err := recs.Get(tag); err != nil {
+			FailWithError(t, "TestParseSpec", err)
+		} else if !bytes.Equal(rec.Value(), want) {
+			FailWithError(t, "TestParseSpec",
+				fmt.Errorf("tag %d: expected % x, got % x", tag, want, rec.Value()))
+		}
+	}
+
+	spec := recs.Spec()
+	if spec != `5=hex:a1b2, 7=str:hello, 9=hex:000004d2, 16=str:"a, b", 3=hex:ff, 4=hex:` {
+		FailWithError(t, "TestParseSpec",
+			fmt.Errorf("bad spec %s", spec))
+	}
+	rrecs, err := ParseSpec(spec)
+	if err != nil {
+		FailWithError(t, "TestParseSpec", err)
+	} else if CompareOrder(recs, rrecs) != -1 {
+		FailWithError(t, "TestParseSpec", noMatch)
+	}
+
+	for _, bad := range []string{"5", "5=hex", "x=str:a", "5=hex:zz", "5=u8:256", "5=f32:1", "5=str:a,", `5=str:"a" b`} {
+		if _, err = ParseSpec(bad); err != ErrSpec {
+			FailWithError(t, "TestParseSpec",
+				fmt.Errorf("%q should be rejected", bad))
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package tlv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// NewSSHFormat returns a Format for sequences of SSH strings (RFC 4251,
+// section 5): each value is preceded only by its length as a 32-bit
+// big-endian integer, with no tag. SSH keys, signatures and certificates
+// are laid out this way, their fields identified by position. Reading
+// numbers the values from zero and uses that position as the tag, so the
+// returned Format keeps count and must not be shared between streams.
+// Writing ignores tags, writing the values in list order.
+func NewSSHFormat() Format {
+	return &sshFormat{}
+}
+
+type sshFormat struct {
+	next int
+}
+
+func (f *sshFormat) ReadHeader(r io.Reader) (tag, length int, err error) {
+	var hdr [4]byte
+	if _, err = io.ReadFull(r, hdr[:]); err != nil {
+		return
+	}
+
+	n := binary.BigEndian.Uint32(hdr[:])
+	if n > maxChunk {
+		return 0, 0, ErrLengthRange
+	}
+	tag = f.next
+	f.next++
+	return tag, int(n), nil
+}
+
+func (f *sshFormat) WriteHeader(w io.Writer, tag, length int) error {
+	if length < 0 || length > maxChunk {
+		return ErrLengthRange
+	}
+
+	var hdr [4]byte
+	binary.BigEndian.PutUint32(hdr[:], uint32(length))
+	_, err := w.Write(hdr[:])
+	return err
+}
+
+// ParseSSH decodes data as a sequence of SSH strings, such as the blob
+// of an SSH public key, returning them as records tagged by position.
+func ParseSSH(data []byte) (*TLVList, error) {
+	dec := NewDecoder(bytes.NewReader(data))
+	dec.Format = NewSSHFormat()
+	return dec.DecodeList()
+}
+
+// MarshalSSH encodes the values of recs as a sequence of SSH strings, in
+// list order.
+func MarshalSSH(recs *TLVList) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+	enc.Format = NewSSHFormat()
+	if err := enc.EncodeList(recs); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,47 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// The start of an ssh-ed25519 public key blob: the key type followed by
+// the 32-byte public key.
+var sshKey = append([]byte{
+	0, 0, 0, 11, 's', 's', 'h', '-', 'e', 'd', '2', '5', '5', '1', '9',
+	0, 0, 0, 32,
+}, bytes.Repeat([]byte{0xab}, 32)...)
+
+func TestSSH(t *testing.T) {
+	recs, err := ParseSSH(sshKey)
+	if err != nil {
+		FailWithError(t, "TestSSH", err)
+	} else if recs.Length() != 2 {
+		FailWithError(t, "TestSSH",
+			fmt.Errorf("parsed %d strings, expected 2", recs.Length()))
+	}
+
+	if kind, err := recs.Get(0); err != nil {
+		FailWithError(t, "TestSSH", err)
+	} else if string(kind.Value()) != "ssh-ed25519" {
+		FailWithError(t, "TestSSH", noMatch)
+	}
+	if key, err := recs.Get(1); err != nil {
+		FailWithError(t, "TestSSH", err)
+	} else if key.Length() != 32 {
+		FailWithError(t, "TestSSH", noMatch)
+	}
+
+	out, err := MarshalSSH(recs)
+	if err != nil {
+		FailWithError(t, "TestSSH", err)
+	} else if !bytes.Equal(out, sshKey) {
+		FailWithError(t, "TestSSH", noMatch)
+	}
+
+	if _, err = ParseSSH(sshKey[:20]); err != ErrTLVRead {
+		FailWithError(t, "TestSSH",
+			fmt.Errorf("expected ErrTLVRead, got %v", err))
+	}
+}
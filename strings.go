@@ -0,0 +1,159 @@
+package tlv
+
+import (
+	"encoding/binary"
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// TagStringEncoding is the tag of a string encoding declaration. Its
+// value is the tag the declaration applies to, as a 32-bit big-endian
+// integer, followed by a one-byte StringEncoding. A declaration applies
+// to every record in the list with that tag.
+const TagStringEncoding = 0x7fffff05
+
+// Type StringEncoding identifies the character encoding of string values.
+type StringEncoding byte
+
+// The supported string encodings. Values with no declared encoding are
+// taken to be UTF-8.
+const (
+	UTF8    StringEncoding = 0
+	UTF16LE StringEncoding = 1
+	Latin1  StringEncoding = 2
+)
+
+// ErrUnknownEncoding is returned for a StringEncoding the package does
+// not support. ErrUnencodable is returned when a string cannot be
+// represented in the requested encoding, and ErrBadString when a value
+// is not valid in its declared encoding. ErrEncodingMismatch is returned
+// when a string is added to a list already declaring a different
+// encoding for its tag.
+var (
+	ErrUnknownEncoding  = fmt.Errorf("unknown string encoding")
+	ErrUnencodable      = fmt.Errorf("string cannot be encoded")
+	ErrBadString        = fmt.Errorf("invalid string value")
+	ErrEncodingMismatch = fmt.Errorf("string encoding does not match declaration")
+)
+
+func encodeString(s string, enc StringEncoding) ([]byte, error) {
+	switch enc {
+	case UTF8:
+		return []byte(s), nil
+	case UTF16LE:
+		units := utf16.Encode([]rune(s))
+		value := make([]byte, 2*len(units))
+		for i, u := range units {
+			binary.LittleEndian.PutUint16(value[2*i:], u)
+		}
+		return value, nil
+	case Latin1:
+		value := make([]byte, 0, len(s))
+		for _, r := range s {
+			if r > 0xff {
+				return nil, ErrUnencodable
+			}
+			value = append(value, byte(r))
+		}
+		return value, nil
+	}
+	return nil, ErrUnknownEncoding
+}
+
+func decodeString(value []byte, enc StringEncoding) (string, error) {
+	switch enc {
+	case UTF8:
+		if !utf8.Valid(value) {
+			return "", ErrBadString
+		}
+		return string(value), nil
+	case UTF16LE:
+		if len(value)%2 != 0 {
+			return "", ErrBadString
+		}
+		units := make([]uint16, len(value)/2)
+		for i := range units {
+			units[i] = binary.LittleEndian.Uint16(value[2*i:])
+		}
+		return string(utf16.Decode(units)), nil
+	case Latin1:
+		runes := make([]rune, len(value))
+		for i, b := range value {
+			runes[i] = rune(b)
+		}
+		return string(runes), nil
+	}
+	return "", ErrUnknownEncoding
+}
+
+func (recs *TLVList) encodingDeclaration(tag int) TLV {
+	for _, decl := range recs.GetAll(TagStringEncoding) {
+		value := decl.Value()
+		if len(value) == 5 && int(int32(binary.BigEndian.Uint32(value))) == tag {
+			return decl
+		}
+	}
+	return nil
+}
+
+// StringEncoding returns the encoding declared for string values with
+// tag, or UTF8 if none has been declared.
+func (recs *TLVList) StringEncoding(tag int) StringEncoding {
+	if decl := recs.encodingDeclaration(tag); decl != nil {
+		return StringEncoding(decl.Value()[4])
+	}
+	return UTF8
+}
+
+// SetStringEncoding declares the encoding of string values with tag,
+// replacing any earlier declaration. This is used to describe values
+// added as raw bytes, such as those read from a source that emits
+// UTF-16.
+func (recs *TLVList) SetStringEncoding(tag int, enc StringEncoding) error {
+	if _, err := encodeString("", enc); err != nil {
+		return err
+	}
+	if decl := recs.encodingDeclaration(tag); decl != nil {
+		recs.RemoveRecord(decl)
+	}
+
+	var value [5]byte
+	binary.BigEndian.PutUint32(value[:], uint32(tag))
+	value[4] = byte(enc)
+	recs.Add(TagStringEncoding, value[:])
+	return nil
+}
+
+// AddString adds a record holding s in the given encoding, declaring the
+// encoding for the tag if it has not been declared already.
+func (recs *TLVList) AddString(tag int, s string, enc StringEncoding) error {
+	value, err := encodeString(s, enc)
+	if err != nil {
+		return err
+	}
+
+	if decl := recs.encodingDeclaration(tag); decl == nil {
+		if len(recs.GetAll(tag)) > 0 && enc != UTF8 {
+			return ErrEncodingMismatch
+		}
+		if err = recs.SetStringEncoding(tag, enc); err != nil {
+			return err
+		}
+	} else if StringEncoding(decl.Value()[4]) != enc {
+		return ErrEncodingMismatch
+	}
+
+	recs.Add(tag, value)
+	return nil
+}
+
+// GetString returns the value of the first record with tag as a string,
+// transcoding it from its declared encoding.
+func (recs *TLVList) GetString(tag int) (string, error) {
+	rec, err := recs.Get(tag)
+	if err != nil {
+		return "", err
+	}
+	return decodeString(rec.Value(), recs.StringEncoding(tag))
+}
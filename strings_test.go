@@ -0,0 +1,76 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestStrings(t *testing.T) {
+	tlvl := New()
+	if err := tlvl.AddString(TagTest1, "Grüße", UTF16LE); err != nil {
+		FailWithError(t, "TestStrings", err)
+	} else if err = tlvl.AddString(TagTest2, "café", Latin1); err != nil {
+		FailWithError(t, "TestStrings", err)
+	} else if err = tlvl.AddString(TagTest3, "gopher", UTF8); err != nil {
+		FailWithError(t, "TestStrings", err)
+	}
+
+	if rec, _ := tlvl.Get(TagTest1); rec.Length() != 10 {
+		FailWithError(t, "TestStrings",
+			fmt.Errorf("value was not stored as UTF-16"))
+	} else if rec, _ = tlvl.Get(TagTest2); rec.Length() != 4 {
+		FailWithError(t, "TestStrings",
+			fmt.Errorf("value was not stored as Latin-1"))
+	}
+
+	buf := new(bytes.Buffer)
+	if err := tlvl.Write(buf); err != nil {
+		FailWithError(t, "TestStrings", err)
+	}
+	rtlvl, err := Read(buf)
+	if err != nil {
+		FailWithError(t, "TestStrings", err)
+	}
+	for tag, want := range map[int]string{TagTest1: "Grüße", TagTest2: "café", TagTest3: "gopher"} {
+		if s, err := rtlvl.GetString(tag); err != nil {
+			FailWithError(t, "TestStrings", err)
+		} else if s != want {
+			FailWithError(t, "TestStrings",
+				fmt.Errorf("expected %q, got %q", want, s))
+		}
+	}
+
+	if err = tlvl.AddString(TagTest2, "€", Latin1); err != ErrUnencodable {
+		FailWithError(t, "TestStrings",
+			fmt.Errorf("expected ErrUnencodable, got %v", err))
+	} else if err = tlvl.AddString(TagTest1, "x", UTF8); err != ErrEncodingMismatch {
+		FailWithError(t, "TestStrings",
+			fmt.Errorf("expected ErrEncodingMismatch, got %v", err))
+	}
+}
+
+func TestSetStringEncoding(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(TagTest1, []byte{'h', 0, 'i', 0})
+	if s, _ := tlvl.GetString(TagTest1); s == "hi" {
+		FailWithError(t, "TestSetStringEncoding",
+			fmt.Errorf("undeclared value should be read as UTF-8"))
+	}
+
+	if err := tlvl.SetStringEncoding(TagTest1, UTF16LE); err != nil {
+		FailWithError(t, "TestSetStringEncoding", err)
+	} else if s, err := tlvl.GetString(TagTest1); err != nil {
+		FailWithError(t, "TestSetStringEncoding", err)
+	} else if s != "hi" {
+		FailWithError(t, "TestSetStringEncoding", noMatch)
+	} else if len(tlvl.GetAll(TagStringEncoding)) != 1 {
+		FailWithError(t, "TestSetStringEncoding",
+			fmt.Errorf("expected a single declaration"))
+	}
+
+	if err := tlvl.SetStringEncoding(TagTest1, 9); err != ErrUnknownEncoding {
+		FailWithError(t, "TestSetStringEncoding",
+			fmt.Errorf("expected ErrUnknownEncoding, got %v", err))
+	}
+}
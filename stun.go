@@ -0,0 +1,140 @@
+package tlv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+)
+
+// STUN is the format of STUN and TURN message attributes (RFC 8489,
+// section 14): a 16-bit big-endian attribute type followed by a 16-bit
+// big-endian length. Values are padded to a multiple of four bytes, and
+// the padding is not counted in the length; set Align to 4 on the
+// Encoder and Decoder, or use DecodeSTUNAttributes and
+// EncodeSTUNAttributes, which do so.
+var STUN Format = tlsFormat{}
+
+// STUN attribute types with helpers in this package, and the magic
+// cookie that fixes the layout of every STUN message.
+const (
+	STUNMappedAddress    = 0x0001
+	STUNXORMappedAddress = 0x0020
+	STUNMagicCookie      = 0x2112a442
+)
+
+// ErrSTUN is returned when a STUN address attribute is malformed.
+var ErrSTUN = fmt.Errorf("invalid STUN address attribute")
+
+// DecodeSTUNAttributes decodes the attributes of a STUN message, the
+// part of the message after its 20-byte header.
+func DecodeSTUNAttributes(attrs []byte) (*TLVList, error) {
+	dec := NewDecoder(bytes.NewReader(attrs))
+	dec.Format = STUN
+	dec.Align = 4
+	return dec.DecodeList()
+}
+
+// EncodeSTUNAttributes encodes recs as the attributes of a STUN message,
+// with each value padded to a multiple of four bytes.
+func EncodeSTUNAttributes(recs *TLVList) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+	enc.Format = STUN
+	enc.Align = 4
+	if err := enc.EncodeList(recs); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// STUNAddress builds a MAPPED-ADDRESS attribute holding ip and port.
+func STUNAddress(ip net.IP, port int) (TLV, error) {
+	value, err := stunAddress(ip, port, nil)
+	if err != nil {
+		return nil, err
+	}
+	return newTLV(STUNMappedAddress, value), nil
+}
+
+// XORMappedAddress builds an XOR-MAPPED-ADDRESS attribute holding ip and
+// port, obfuscated with the magic cookie and, for IPv6 addresses, the
+// message's transaction ID.
+func XORMappedAddress(ip net.IP, port int, txID [12]byte) (TLV, error) {
+	value, err := stunAddress(ip, port, stunMask(txID))
+	if err != nil {
+		return nil, err
+	}
+	return newTLV(STUNXORMappedAddress, value), nil
+}
+
+// ParseSTUNAddress returns the address held in a MAPPED-ADDRESS or
+// XOR-MAPPED-ADDRESS attribute; txID is only used for the latter.
+func ParseSTUNAddress(rec TLV, txID [12]byte) (ip net.IP, port int, err error) {
+	var mask []byte
+	switch rec.Tag() {
+	case STUNMappedAddress:
+	case STUNXORMappedAddress:
+		mask = stunMask(txID)
+	default:
+		return nil, 0, ErrSTUN
+	}
+
+	value := rec.Value()
+	if len(value) < 4 {
+		return nil, 0, ErrSTUN
+	}
+	switch {
+	case value[1] == 1 && len(value) == 4+net.IPv4len:
+	case value[1] == 2 && len(value) == 4+net.IPv6len:
+	default:
+		return nil, 0, ErrSTUN
+	}
+
+	port = int(binary.BigEndian.Uint16(value[2:]))
+	ip = make(net.IP, len(value)-4)
+	copy(ip, value[4:])
+	if mask != nil {
+		port ^= int(binary.BigEndian.Uint16(mask))
+		for i := range ip {
+			ip[i] ^= mask[i]
+		}
+	}
+	return
+}
+
+// stunMask returns the bytes an XOR-MAPPED-ADDRESS is XORed with: the
+// magic cookie followed by the transaction ID.
+func stunMask(txID [12]byte) []byte {
+	mask := make([]byte, 16)
+	binary.BigEndian.PutUint32(mask, STUNMagicCookie)
+	copy(mask[4:], txID[:])
+	return mask
+}
+
+func stunAddress(ip net.IP, port int, mask []byte) ([]byte, error) {
+	family := byte(1)
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	} else if len(ip) == net.IPv6len {
+		family = 2
+	} else {
+		return nil, ErrSTUN
+	}
+	if port < 0 || port > 0xffff {
+		return nil, ErrSTUN
+	}
+
+	value := make([]byte, 4+len(ip))
+	value[1] = family
+	binary.BigEndian.PutUint16(value[2:], uint16(port))
+	copy(value[4:], ip)
+	if mask != nil {
+		value[2] ^= mask[0]
+		value[3] ^= mask[1]
+		for i := range ip {
+			value[4+i] ^= mask[i]
+		}
+	}
+	return value, nil
+}
@@ -0,0 +1,80 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"testing"
+)
+
+// The transaction ID and XOR-MAPPED-ADDRESS of the IPv4 sample response
+// in RFC 5769, section 2.2, preceded by a USERNAME that needs padding.
+var (
+	stunTxID = [12]byte{
+		0xb7, 0xe7, 0xa7, 0x01, 0xbc, 0x34, 0xd6, 0x86,
+		0xfa, 0x87, 0xdf, 0xae,
+	}
+	stunAttrs = []byte{
+		0x00, 0x06, 0x00, 0x09, 'e', 'v', 't', 'j', ':', 'h', '6', 'v', 'Y',
+		0x00, 0x00, 0x00,
+		0x00, 0x20, 0x00, 0x08, 0x00, 0x01, 0xa1, 0x47,
+		0xe1, 0x12, 0xa6, 0x43,
+	}
+)
+
+func TestSTUN(t *testing.T) {
+	attrs, err := DecodeSTUNAttributes(stunAttrs)
+	if err != nil {
+		FailWithError(t, "TestSTUN", err)
+	} else if attrs.Length() != 2 {
+		FailWithError(t, "TestSTUN", noMatch)
+	}
+
+	if user, err := attrs.Get(0x0006); err != nil {
+		FailWithError(t, "TestSTUN", err)
+	} else if string(user.Value()) != "evtj:h6vY" {
+		FailWithError(t, "TestSTUN", noMatch)
+	}
+
+	rec, err := attrs.Get(STUNXORMappedAddress)
+	if err != nil {
+		FailWithError(t, "TestSTUN", err)
+	}
+	ip, port, err := ParseSTUNAddress(rec, stunTxID)
+	if err != nil {
+		FailWithError(t, "TestSTUN", err)
+	} else if !ip.Equal(net.ParseIP("192.0.2.1")) || port != 32853 {
+		FailWithError(t, "TestSTUN",
+			fmt.Errorf("address is %v:%d", ip, port))
+	}
+
+	out, err := EncodeSTUNAttributes(attrs)
+	if err != nil {
+		FailWithError(t, "TestSTUN", err)
+	} else if !bytes.Equal(out, stunAttrs) {
+		FailWithError(t, "TestSTUN", noMatch)
+	}
+}
+
+func TestXORMappedAddress(t *testing.T) {
+	for _, addr := range []string{"192.0.2.1", "2001:db8:1234:5678:11:2233:4455:6677"} {
+		rec, err := XORMappedAddress(net.ParseIP(addr), 32853, stunTxID)
+		if err != nil {
+			FailWithError(t, "TestXORMappedAddress", err)
+		}
+		ip, port, err := ParseSTUNAddress(rec, stunTxID)
+		if err != nil {
+			FailWithError(t, "TestXORMappedAddress", err)
+		} else if !ip.Equal(net.ParseIP(addr)) || port != 32853 {
+			FailWithError(t, "TestXORMappedAddress",
+				fmt.Errorf("address is %v:%d, expected %s", ip, port, addr))
+		}
+	}
+
+	rec, err := STUNAddress(net.ParseIP("192.0.2.1"), 80)
+	if err != nil {
+		FailWithError(t, "TestXORMappedAddress", err)
+	} else if !bytes.Equal(rec.Value(), []byte{0, 1, 0, 80, 192, 0, 2, 1}) {
+		FailWithError(t, "TestXORMappedAddress", noMatch)
+	}
+}
@@ -0,0 +1,138 @@
+package tlv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"time"
+)
+
+// TagSummary is the tag of the summary record an Encoder writes at the
+// end of a stream when its Summary field is set.
+const TagSummary = 0x7fffff07
+
+// ErrNoSummary is returned when a Decoder that is checking a summary
+// reaches the end of a stream without finding one.
+var ErrNoSummary = fmt.Errorf("TLV stream has no summary record")
+
+// Type Summary is the content of a summary record: a lightweight check
+// that a stream arrived whole and unaltered, short of signing it.
+type Summary struct {
+	// Records and Bytes are the number of records written before the
+	// summary, and the number of bytes they took up.
+	Records int64
+	Bytes   int64
+
+	// Duration is the wall-clock time from the first record being
+	// written to the summary being written.
+	Duration time.Duration
+
+	// Hash identifies the algorithm used for Digest, the hash of the
+	// encoding of every record written before the summary.
+	Hash   HashID
+	Digest []byte
+}
+
+// summaryState holds an Encoder's or Decoder's running summary.
+type summaryState struct {
+	id      HashID
+	digest  hash.Hash
+	records int64
+	started time.Time
+}
+
+func newSummaryState(id HashID) (*summaryState, error) {
+	h, err := NewHash(id)
+	if err != nil {
+		return nil, err
+	}
+	return &summaryState{id: id, digest: h, started: time.Now()}, nil
+}
+
+// record returns the summary record for the state, given the number of
+// bytes written so far.
+func (s *summaryState) record(n int64) TLV {
+	value := make([]byte, 25, 25+s.digest.Size())
+	binary.BigEndian.PutUint64(value, uint64(s.records))
+	binary.BigEndian.PutUint64(value[8:], uint64(n))
+	binary.BigEndian.PutUint64(value[16:], uint64(time.Since(s.started)))
+	value[24] = byte(s.id)
+	value = s.digest.Sum(value)
+	return &record{tag: TagSummary, length: len(value), value: value}
+}
+
+// ParseSummary returns the Summary held in a summary record.
+func ParseSummary(rec TLV) (sum Summary, err error) {
+	value := rec.Value()
+	if rec.Tag() != TagSummary || len(value) < 25 {
+		return sum, ErrCorrupt
+	}
+	sum.Records = int64(binary.BigEndian.Uint64(value))
+	sum.Bytes = int64(binary.BigEndian.Uint64(value[8:]))
+	sum.Duration = time.Duration(binary.BigEndian.Uint64(value[16:]))
+	sum.Hash = HashID(value[24])
+	sum.Digest = value[25:]
+	return
+}
+
+// CheckSummary arranges for the Decoder to check the summary record at
+// the end of a stream, which must have been written with the hash
+// algorithm id. As each record is decoded, it is counted and added to
+// the digest; when the summary record is reached, its counts and digest
+// are compared, and ErrCorrupt is returned if they differ. The summary
+// record itself is returned by Decode, and can be read with
+// ParseSummary. If the stream ends without a summary, ErrNoSummary is
+// returned instead of io.EOF.
+//
+// Byte counts are taken from the Decoder's Offset, so the Decoder must
+// be reading from the start of the stream. CheckSummary must be called
+// before any records have been decoded.
+func (dec *Decoder) CheckSummary(id HashID) (err error) {
+	dec.summary, err = newSummaryState(id)
+	return
+}
+
+// checkSummary handles a record decoded from a stream whose summary is
+// being checked; header is its header as read, and start is the offset
+// at which it began.
+func (dec *Decoder) checkSummary(tlv TLV, header []byte, start int64) error {
+	s := dec.summary
+	if tlv.Tag() != TagSummary {
+		s.records++
+		s.digest.Write(header)
+		s.digest.Write(tlv.Value())
+		return nil
+	}
+
+	sum, err := ParseSummary(tlv)
+	if err != nil {
+		return err
+	} else if sum.Records != s.records || sum.Bytes != start || sum.Hash != s.id {
+		return ErrCorrupt
+	} else if !bytes.Equal(sum.Digest, s.digest.Sum(nil)) {
+		return ErrCorrupt
+	}
+	dec.summary = nil
+	return nil
+}
+
+// summarize begins the Encoder's summary, if one was requested, when the
+// first record is written.
+func (enc *Encoder) summarize() (err error) {
+	if enc.summary == nil && enc.Summary != 0 && !enc.summarized {
+		enc.summary, err = newSummaryState(enc.Summary)
+	}
+	return
+}
+
+// writeSummary writes the summary record from Close.
+func (enc *Encoder) writeSummary() error {
+	if err := enc.summarize(); err != nil || enc.summary == nil {
+		return err
+	}
+	rec := enc.summary.record(enc.w.n)
+	enc.summary = nil
+	enc.summarized = true
+	return enc.encode(rec)
+}
@@ -0,0 +1,81 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func summaryStream(t *testing.T) []byte {
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+	enc.Summary = HashSHA256
+	enc.Align = 4
+	for _, v := range []string{"foo", "bar baz", ""} {
+		if err := enc.Encode(newTLV(TagTest1, []byte(v))); err != nil {
+			FailWithError(t, "summaryStream", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		FailWithError(t, "summaryStream", err)
+	}
+	return buf.Bytes()
+}
+
+func TestSummary(t *testing.T) {
+	raw := summaryStream(t)
+
+	dec := NewDecoder(bytes.NewReader(raw))
+	dec.Align = 4
+	if err := dec.CheckSummary(HashSHA256); err != nil {
+		FailWithError(t, "TestSummary", err)
+	}
+	var last TLV
+	for {
+		rec, err := dec.Decode()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			FailWithError(t, "TestSummary", err)
+		}
+		last = rec
+	}
+
+	sum, err := ParseSummary(last)
+	if err != nil {
+		FailWithError(t, "TestSummary", err)
+	} else if sum.Records != 3 || sum.Bytes != 12+16+8 || sum.Hash != HashSHA256 {
+		FailWithError(t, "TestSummary",
+			fmt.Errorf("summary is %+v", sum))
+	}
+
+	// Tampering with a value breaks the digest.
+	bad := append([]byte(nil), raw...)
+	bad[8] = 'g'
+	dec = NewDecoder(bytes.NewReader(bad))
+	dec.Align = 4
+	dec.CheckSummary(HashSHA256)
+	if _, err = dec.DecodeList(); err != ErrCorrupt {
+		FailWithError(t, "TestSummary",
+			fmt.Errorf("expected ErrCorrupt, got %v", err))
+	}
+
+	// So does dropping a record.
+	dec = NewDecoder(bytes.NewReader(raw[12:]))
+	dec.Align = 4
+	dec.CheckSummary(HashSHA256)
+	if _, err = dec.DecodeList(); err != ErrCorrupt {
+		FailWithError(t, "TestSummary",
+			fmt.Errorf("expected ErrCorrupt, got %v", err))
+	}
+
+	// And truncating the stream before the summary.
+	dec = NewDecoder(bytes.NewReader(raw[:36]))
+	dec.Align = 4
+	dec.CheckSummary(HashSHA256)
+	if _, err = dec.DecodeList(); err != ErrNoSummary {
+		FailWithError(t, "TestSummary",
+			fmt.Errorf("expected ErrNoSummary, got %v", err))
+	}
+}
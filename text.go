@@ -0,0 +1,110 @@
+package tlv
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrText is returned by ReadText when a line is malformed or its length
+// does not match its value.
+var ErrText = fmt.Errorf("invalid TLV text")
+
+// WriteText writes the TLVList to w in its canonical text form: one line
+// per record, in list order, such as
+//
+//	tag=5 len=4 value=0xdeadbeef
+//
+// Empty values are written as value=0x.
+func (recs *TLVList) WriteText(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, tlv := range recs.records {
+		fmt.Fprintf(bw, "tag=%d len=%d value=0x%x\n", tlv.Tag(), tlv.Length(), tlv.Value())
+	}
+	return bw.Flush()
+}
+
+// WriteText is TLVList.WriteText with each record whose tag is named or
+// described in reg preceded by a comment line, such as
+//
+//	# tag 0x5F2D (Language Preference)
+//
+// which ReadText skips.
+func (reg *Registry) WriteText(w io.Writer, recs *TLVList) error {
+	bw := bufio.NewWriter(w)
+	for _, tlv := range recs.records {
+		_, named := reg.Name(tlv.Tag())
+		_, described := reg.Description(tlv.Tag())
+		if named || described {
+			fmt.Fprintf(bw, "# %s\n", reg.Describe(tlv.Tag()))
+		}
+		fmt.Fprintf(bw, "tag=%d len=%d value=0x%x\n", tlv.Tag(), tlv.Length(), tlv.Value())
+	}
+	return bw.Flush()
+}
+
+// ReadText reads a TLVList in the text form written by WriteText. To make
+// hand-written files easier to maintain, blank lines and lines starting
+// with # are skipped, tags may be written in hex with a 0x prefix, and
+// values may be given as Go quoted strings instead of hex.
+func ReadText(r io.Reader) (*TLVList, error) {
+	recs := New()
+	br := bufio.NewReader(r)
+	for {
+		// Lines are read whole, however long, as a single record's
+		// value may be far larger than bufio.Scanner allows.
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		if line = strings.TrimSpace(line); line != "" && line[0] != '#' {
+			rec, perr := parseTextLine(line)
+			if perr != nil {
+				return nil, perr
+			}
+			recs.AddRecord(rec)
+		}
+		if err == io.EOF {
+			return recs, nil
+		}
+	}
+}
+
+func parseTextLine(line string) (TLV, error) {
+	const tagField, lenField, valueField = "tag=", "len=", "value="
+	fields := strings.SplitN(line, " ", 3)
+	if len(fields) != 3 || !strings.HasPrefix(fields[0], tagField) ||
+		!strings.HasPrefix(fields[1], lenField) || !strings.HasPrefix(fields[2], valueField) {
+		return nil, ErrText
+	}
+
+	tag, err := strconv.ParseInt(fields[0][len(tagField):], 0, 32)
+	if err != nil {
+		return nil, ErrText
+	}
+	length, err := strconv.Atoi(fields[1][len(lenField):])
+	if err != nil {
+		return nil, ErrText
+	}
+
+	var value []byte
+	text := strings.TrimSpace(fields[2][len(valueField):])
+	switch {
+	case strings.HasPrefix(text, "0x"):
+		value, err = hex.DecodeString(text[2:])
+	case strings.HasPrefix(text, `"`):
+		var s string
+		s, err = strconv.Unquote(text)
+		value = []byte(s)
+	default:
+		err = ErrText
+	}
+	if err != nil || len(value) != length {
+		return nil, ErrText
+	}
+	return newTLV(int(tag), value), nil
+}
@@ -0,0 +1,77 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestText(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(5, []byte{0xde, 0xad, 0xbe, 0xef})
+	tlvl.Add(TagTest3, nil)
+	tlvl.Add(-1, []byte("hi"))
+
+	buf := new(bytes.Buffer)
+	if err := tlvl.WriteText(buf); err != nil {
+		FailWithError(t, "TestText", err)
+	}
+	want := "tag=5 len=4 value=0xdeadbeef\ntag=2 len=0 value=0x\ntag=-1 len=2 value=0x6869\n"
+	if buf.String() != want {
+		FailWithError(t, "TestText",
+			fmt.Errorf("bad text:\n%s", buf.String()))
+	}
+
+	rtlvl, err := ReadText(buf)
+	if err != nil {
+		FailWithError(t, "TestText", err)
+	} else if CompareOrder(tlvl, rtlvl) != -1 {
+		FailWithError(t, "TestText", noMatch)
+	}
+
+	fixture := `# A hand-written fixture.
+
+tag=0x10 len=11 value="hello world"
+tag=1 len=1 value=0x2a
+`
+	if rtlvl, err = ReadText(strings.NewReader(fixture)); err != nil {
+		FailWithError(t, "TestText", err)
+	}
+	checkTags(t, "TestText", rtlvl, 16, 1)
+	if rec, _ := rtlvl.Get(16); string(rec.Value()) != "hello world" {
+		FailWithError(t, "TestText", noMatch)
+	}
+
+	for _, bad := range []string{
+		"tag=1 len=2 value=0x2a",
+		"tag=1 value=0x2a",
+		"tag=x len=1 value=0x2a",
+		"tag=1 len=1 value=2a",
+		"tag=1 len=1 value=0x2",
+	} {
+		if _, err = ReadText(strings.NewReader(bad)); err != ErrText {
+			FailWithError(t, "TestText",
+				fmt.Errorf("%q should be rejected", bad))
+		}
+	}
+}
+
+func TestTextLargeValue(t *testing.T) {
+	// Each byte takes two hex digits, so this line is far longer than
+	// bufio.Scanner's default limit.
+	tlvl := New()
+	tlvl.Add(TagTest1, bytes.Repeat([]byte{0xa5}, 1<<17))
+	tlvl.Add(TagTest2, []byte("after"))
+
+	buf := new(bytes.Buffer)
+	if err := tlvl.WriteText(buf); err != nil {
+		FailWithError(t, "TestTextLargeValue", err)
+	}
+	rtlvl, err := ReadText(buf)
+	if err != nil {
+		FailWithError(t, "TestTextLargeValue", err)
+	} else if CompareOrder(tlvl, rtlvl) != -1 {
+		FailWithError(t, "TestTextLargeValue", noMatch)
+	}
+}
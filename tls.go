@@ -0,0 +1,79 @@
+package tlv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// TLS is the format of TLS handshake extensions (RFC 8446, section 4.2):
+// a 16-bit big-endian extension type followed by a 16-bit big-endian
+// length. Tags must be between 0 and 65535, and values may be at most
+// 65535 bytes long.
+var TLS Format = tlsFormat{}
+
+type tlsFormat struct{}
+
+func (tlsFormat) ReadHeader(r io.Reader) (tag, length int, err error) {
+	var hdr [4]byte
+	if _, err = io.ReadFull(r, hdr[:]); err != nil {
+		return
+	}
+	return int(binary.BigEndian.Uint16(hdr[:])), int(binary.BigEndian.Uint16(hdr[2:])), nil
+}
+
+func (tlsFormat) WriteHeader(w io.Writer, tag, length int) error {
+	if tag < 0 || tag > 0xffff {
+		return ErrTagRange
+	} else if length < 0 || length > 0xffff {
+		return ErrLengthRange
+	}
+
+	var hdr [4]byte
+	binary.BigEndian.PutUint16(hdr[:], uint16(tag))
+	binary.BigEndian.PutUint16(hdr[2:], uint16(length))
+	_, err := w.Write(hdr[:])
+	return err
+}
+
+// ReadTLSExtensions reads a TLS extensions block, as found at the end of
+// a ClientHello or ServerHello: a 16-bit big-endian length followed by
+// that many bytes of extensions.
+func ReadTLSExtensions(r io.Reader) (*TLVList, error) {
+	var hdr [2]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, ErrTLVRead
+	}
+
+	block := make([]byte, binary.BigEndian.Uint16(hdr[:]))
+	if _, err := io.ReadFull(r, block); err != nil {
+		return nil, ErrTLVRead
+	}
+
+	dec := NewDecoder(bytes.NewReader(block))
+	dec.Format = TLS
+	return dec.DecodeList()
+}
+
+// WriteTLSExtensions writes recs as a TLS extensions block, prefixed by
+// its 16-bit big-endian length.
+func WriteTLSExtensions(w io.Writer, recs *TLVList) error {
+	buf := new(bytes.Buffer)
+	buf.Write([]byte{0, 0})
+
+	enc := NewEncoder(buf)
+	enc.Format = TLS
+	if err := enc.EncodeList(recs); err != nil {
+		return err
+	}
+
+	block := buf.Bytes()
+	if len(block)-2 > 0xffff {
+		return ErrLengthRange
+	}
+	binary.BigEndian.PutUint16(block, uint16(len(block)-2))
+	if _, err := w.Write(block); err != nil {
+		return ErrTLVWrite
+	}
+	return nil
+}
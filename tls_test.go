@@ -0,0 +1,50 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// A ClientHello extensions block holding server_name "go.dev" and
+// supported_versions TLS 1.3.
+var tlsExtensions = []byte{
+	0x00, 0x16,
+	0x00, 0x00, 0x00, 0x0b,
+	0x00, 0x09, 0x00, 0x00, 0x06, 'g', 'o', '.', 'd', 'e', 'v',
+	0x00, 0x2b, 0x00, 0x03,
+	0x02, 0x03, 0x04,
+	0x12, 0x34,
+}
+
+func TestTLS(t *testing.T) {
+	r := bytes.NewReader(tlsExtensions)
+	exts, err := ReadTLSExtensions(r)
+	if err != nil {
+		FailWithError(t, "TestTLS", err)
+	}
+	checkTags(t, "TestTLS", exts, 0x0000, 0x002b)
+	if r.Len() != 2 {
+		FailWithError(t, "TestTLS",
+			fmt.Errorf("read past the extensions block"))
+	}
+
+	if sv, err := exts.Get(0x002b); err != nil {
+		FailWithError(t, "TestTLS", err)
+	} else if !bytes.Equal(sv.Value(), []byte{0x02, 0x03, 0x04}) {
+		FailWithError(t, "TestTLS", noMatch)
+	}
+
+	buf := new(bytes.Buffer)
+	if err = WriteTLSExtensions(buf, exts); err != nil {
+		FailWithError(t, "TestTLS", err)
+	} else if !bytes.Equal(buf.Bytes(), tlsExtensions[:len(tlsExtensions)-2]) {
+		FailWithError(t, "TestTLS",
+			fmt.Errorf("extensions did not round-trip"))
+	}
+
+	if _, err = ReadTLSExtensions(bytes.NewReader(tlsExtensions[:10])); err != ErrTLVRead {
+		FailWithError(t, "TestTLS",
+			fmt.Errorf("truncated block should fail"))
+	}
+}
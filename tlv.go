@@ -2,7 +2,6 @@ package tlv
 
 import (
 	"bytes"
-	"container/list"
 	"encoding/binary"
 	"fmt"
 	"io"
@@ -79,6 +78,48 @@ func tlvFromBytes(rec []byte) (tlv TLV, err error) {
 	return readRecord(recBuf)
 }
 
+// maxEagerValue is the longest value readValue allocates in full before
+// reading it.
+const maxEagerValue = 64 << 10
+
+// readValue reads a value of length bytes from r. Long values are read
+// into a buffer that grows as the input arrives, so that a corrupt or
+// hostile header claiming a huge length cannot force a huge allocation
+// from a short input. A short read fails with io.ErrUnexpectedEOF, or
+// io.EOF if nothing was read.
+func readValue(r io.Reader, length int) ([]byte, error) {
+	return appendValue(nil, r, length)
+}
+
+// appendValue is readValue, appending the value to dst. Space already
+// in dst is used as it is.
+func appendValue(dst []byte, r io.Reader, length int) ([]byte, error) {
+	n := len(dst)
+	if length <= maxEagerValue || length <= cap(dst)-n {
+		if cap(dst)-n < length {
+			grown := make([]byte, n, n+length)
+			copy(grown, dst)
+			dst = grown
+		}
+		dst = dst[:n+length]
+		_, err := io.ReadFull(r, dst[n:])
+		return dst, err
+	}
+
+	if cap(dst)-n < maxEagerValue {
+		grown := make([]byte, n, n+maxEagerValue)
+		copy(grown, dst)
+		dst = grown
+	}
+	buf := bytes.NewBuffer(dst)
+	if m, err := io.CopyN(buf, r, int64(length)); err == io.EOF && m > 0 {
+		return nil, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 func readRecord(r io.Reader) (rec TLV, err error) {
 	tlv := new(record)
 
@@ -106,16 +147,12 @@ func readRecord(r io.Reader) (rec TLV, err error) {
 }
 
 func writeRecord(tlv TLV, w io.Writer) (err error) {
-	tmp := int32(tlv.Tag())
-	err = binary.Write(w, binary.BigEndian, tmp)
+	err = Standard.WriteHeader(w, tlv.Tag(), tlv.Length())
 	if err != nil {
 		return
 	}
-
-	tmp = int32(tlv.Length())
-	err = binary.Write(w, binary.BigEndian, tmp)
-	if err != nil {
-		return
+	if nested, ok := tlv.(*nestedRecord); ok {
+		return nested.writeChildren(w)
 	}
 
 	n, err := w.Write(tlv.Value())
@@ -127,29 +164,29 @@ func writeRecord(tlv TLV, w io.Writer) (err error) {
 	return
 }
 
-// Type TLVList is a doubly-linked list containing TLV records.
+// Type TLVList is a list of TLV records, kept in the order they were
+// added. The zero value is an empty list ready to use.
 type TLVList struct {
-	records *list.List
+	records []TLV
+	sums    []uint32
 }
 
 // New returns a new, empty TLVList.
 func New() *TLVList {
-	tl := new(TLVList)
-	tl.records = list.New()
-	return tl
+	return new(TLVList)
 }
 
 // Length returns the number of records in the TLVList.
 func (tl *TLVList) Length() int {
-	return tl.records.Len()
+	return len(tl.records)
 }
 
 // Get checks the TLVList for any record matching the tag. It returns the
 // first one found. If the tag could not be found, Get returns ErrTagNotFound.
 func (recs *TLVList) Get(tag int) (t TLV, err error) {
-	for e := recs.records.Front(); e != nil; e = e.Next() {
-		if e.Value.(*record).Tag() == tag {
-			return e.Value.(*record), nil
+	for _, tlv := range recs.records {
+		if tlv.Tag() == tag {
+			return tlv, nil
 		}
 	}
 	return nil, ErrTagNotFound
@@ -160,73 +197,179 @@ func (recs *TLVList) Get(tag int) (t TLV, err error) {
 // tag, an empty slice is returned.
 func (recs *TLVList) GetAll(tag int) (ts []TLV) {
 	ts = make([]TLV, 0)
-	for e := recs.records.Front(); e != nil; e = e.Next() {
-		if e.Value.(*record).Tag() == tag {
-			ts = append(ts, e.Value.(TLV))
+	for _, tlv := range recs.records {
+		if tlv.Tag() == tag {
+			ts = append(ts, tlv)
 		}
 	}
 	return ts
 }
 
-// Remove removes all records with the requested tag. It returns a count
-// of the number of removed records.
-func (recs *TLVList) Remove(tag int) int {
-	var totalRemoved int
-	for {
-		var removed int
-		for e := recs.records.Front(); e != nil; e = e.Next() {
-			if e.Value.(*record).Tag() == tag {
-				recs.records.Remove(e)
-				removed++
-				break
-			}
+// ForEach calls fn for each record in the TLVList, in order, stopping
+// early if fn returns false. Records added by fn are not visited.
+func (recs *TLVList) ForEach(fn func(TLV) bool) {
+	for _, tlv := range recs.records {
+		if !fn(tlv) {
+			return
 		}
-		if removed == 0 {
-			break
+	}
+}
+
+// ForEachIndex is like ForEach, but also passes fn each record's index
+// in the list.
+func (recs *TLVList) ForEachIndex(fn func(int, TLV) bool) {
+	for i, tlv := range recs.records {
+		if !fn(i, tlv) {
+			return
 		}
-		totalRemoved += removed
 	}
-	return totalRemoved
+}
+
+// ErrIndexRange is returned when a record is addressed by an index
+// outside the list.
+var ErrIndexRange = fmt.Errorf("record index out of range")
+
+// GetIndex returns the record at index i in the TLVList, counting from
+// zero in list order. This allows one of several records with the same
+// tag to be picked out. If i is out of range, ErrIndexRange is returned.
+func (recs *TLVList) GetIndex(i int) (TLV, error) {
+	if i < 0 || i >= len(recs.records) {
+		return nil, ErrIndexRange
+	}
+	return recs.records[i], nil
+}
+
+// SetIndex replaces the record at index i with rec.
+func (recs *TLVList) SetIndex(i int, rec TLV) error {
+	if i < 0 || i >= len(recs.records) {
+		return ErrIndexRange
+	}
+	recs.set(i, rec)
+	return nil
+}
+
+// RemoveIndex removes the record at index i, moving the records after
+// it up by one.
+func (recs *TLVList) RemoveIndex(i int) error {
+	if i < 0 || i >= len(recs.records) {
+		return ErrIndexRange
+	}
+	copy(recs.records[i:], recs.records[i+1:])
+	recs.records[len(recs.records)-1] = nil
+	recs.records = recs.records[:len(recs.records)-1]
+	if recs.sums != nil {
+		copy(recs.sums[i:], recs.sums[i+1:])
+		recs.sums = recs.sums[:len(recs.sums)-1]
+	}
+	return nil
+}
+
+// Remove removes all records with the requested tag. It returns a count
+// of the number of removed records.
+func (recs *TLVList) Remove(tag int) int {
+	return recs.removeFunc(func(tlv TLV) bool { return tlv.Tag() == tag })
 }
 
 // RemoveRecord takes a record as an argument, and removes all matching
 // records. It matches on not just tag, but also the value contained in
 // the record.
 func (recs *TLVList) RemoveRecord(rec TLV) int {
-	var totalRemoved int
-	for {
-		var removed int
-		for e := recs.records.Front(); e != nil; e = e.Next() {
-			if Equals(e.Value.(*record), rec) {
-				recs.records.Remove(e)
-				removed++
-				break
-			}
+	return recs.removeFunc(func(tlv TLV) bool { return Equals(tlv, rec) })
+}
+
+// removeFunc removes the records for which match returns true, along
+// with their checksums, keeping the rest in order. It returns the
+// number of records removed.
+func (recs *TLVList) removeFunc(match func(TLV) bool) int {
+	kept := 0
+	for i, tlv := range recs.records {
+		if match(tlv) {
+			continue
 		}
-		if removed == 0 {
-			break
+		recs.records[kept] = tlv
+		if recs.sums != nil {
+			recs.sums[kept] = recs.sums[i]
 		}
-		totalRemoved += removed
+		kept++
+	}
+
+	removed := len(recs.records) - kept
+	for i := kept; i < len(recs.records); i++ {
+		recs.records[i] = nil
 	}
-	return totalRemoved
+	recs.records = recs.records[:kept]
+	if recs.sums != nil {
+		recs.sums = recs.sums[:kept]
+	}
+	return removed
 }
 
 // Add pushes a new TLV record onto the TLVList. It builds the record from
 // its arguments.
 func (recs *TLVList) Add(tag int, value []byte) {
-	rec := newTLV(tag, value)
-	recs.records.PushBack(rec)
+	recs.AddRecord(newTLV(tag, value))
 }
 
 // AddRecord adds a TLV record onto the TLVList.
 func (recs *TLVList) AddRecord(rec TLV) {
-	recs.records.PushBack(rec)
+	recs.records = append(recs.records, rec)
+	recs.protect(rec)
+}
+
+// Set replaces the records with the given tag by a single record
+// holding value. The new record takes the place of the first record
+// with the tag, so the order of the list is otherwise unchanged; if
+// there is no record with the tag, it is added at the end.
+func (recs *TLVList) Set(tag int, value []byte) {
+	recs.Upsert(tag, value)
+}
+
+// Upsert is like Set, but reports whether the record was inserted
+// because no record had the tag, rather than replacing existing ones.
+func (recs *TLVList) Upsert(tag int, value []byte) (inserted bool) {
+	first := -1
+	for i, tlv := range recs.records {
+		if tlv.Tag() == tag {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		recs.Add(tag, value)
+		return true
+	}
+
+	recs.set(first, newTLV(tag, value))
+	kept := false
+	recs.removeFunc(func(tlv TLV) bool {
+		if tlv.Tag() != tag {
+			return false
+		} else if !kept {
+			kept = true
+			return false
+		}
+		return true
+	})
+	return false
+}
+
+// GetOrAdd returns the first record with the given tag. If there is
+// none, a record holding def is added at the end of the list and
+// returned.
+func (recs *TLVList) GetOrAdd(tag int, def []byte) TLV {
+	if tlv, err := recs.Get(tag); err == nil {
+		return tlv
+	}
+	rec := newTLV(tag, def)
+	recs.AddRecord(rec)
+	return rec
 }
 
-// Write writes out the TLVList to an io.Writer.
+// Write writes out the TLVList to an io.Writer. Records are written in
+// the order they appear in the list.
 func (recs *TLVList) Write(w io.Writer) (err error) {
-	for e := recs.records.Front(); e != nil; e = e.Next() {
-		err = writeRecord(e.Value.(TLV), w)
+	for _, tlv := range recs.records {
+		err = writeRecord(tlv, w)
 		if err != nil {
 			return
 		}
@@ -234,7 +377,8 @@ func (recs *TLVList) Write(w io.Writer) (err error) {
 	return
 }
 
-// Read takes an io.Reader and builds a TLVList from that.
+// Read takes an io.Reader and builds a TLVList from that. The records in
+// the list are in the order they were read.
 func Read(r io.Reader) (recs *TLVList, err error) {
 	recs = New()
 	for {
@@ -242,7 +386,7 @@ func Read(r io.Reader) (recs *TLVList, err error) {
 		if tlv, err = readRecord(r); err != nil {
 			break
 		}
-		recs.records.PushBack(tlv)
+		recs.records = append(recs.records, tlv)
 	}
 
 	if err == io.EOF {
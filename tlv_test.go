@@ -1,8 +1,11 @@
 package tlv
 
+import "bytes"
 import "fmt"
+import "io"
 import "io/ioutil"
 import "os"
+import "runtime"
 import "testing"
 
 const (
@@ -240,3 +243,178 @@ func TestTLVListReadWrite(t *testing.T) {
 		}
 	}
 }
+
+func TestTLVListZero(t *testing.T) {
+	var tlvl TLVList
+	if tlvl.Length() != 0 {
+		FailWithError(t, "TestTLVListZero", noMatch)
+	}
+	tlvl.Add(TagTest1, []byte("foo"))
+	tlvl.Add(TagTest2, []byte("bar"))
+	tlvl.Add(TagTest1, []byte("baz"))
+	if n := tlvl.Remove(TagTest1); n != 2 || tlvl.Length() != 1 {
+		FailWithError(t, "TestTLVListZero",
+			fmt.Errorf("removed %d records, %d left", n, tlvl.Length()))
+	} else if rec, err := tlvl.Get(TagTest2); err != nil || string(rec.Value()) != "bar" {
+		FailWithError(t, "TestTLVListZero", noMatch)
+	}
+}
+
+func TestTLVListForEach(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(TagTest1, []byte("foo"))
+	tlvl.Add(TagTest2, []byte("bar"))
+	tlvl.Add(TagTest3, []byte("baz"))
+
+	var tags []int
+	tlvl.ForEach(func(rec TLV) bool {
+		tags = append(tags, rec.Tag())
+		return true
+	})
+	if fmt.Sprint(tags) != fmt.Sprint([]int{TagTest1, TagTest2, TagTest3}) {
+		FailWithError(t, "TestTLVListForEach", fmt.Errorf("visited %v", tags))
+	}
+
+	var last int
+	tlvl.ForEachIndex(func(i int, rec TLV) bool {
+		last = i
+		return rec.Tag() != TagTest2
+	})
+	if last != 1 {
+		FailWithError(t, "TestTLVListForEach",
+			fmt.Errorf("stopped at %d, expected 1", last))
+	}
+}
+
+func TestTLVListSet(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(TagTest1, []byte("foo"))
+	tlvl.Add(TagTest2, []byte("bar"))
+	tlvl.Add(TagTest1, []byte("baz"))
+	tlvl.Add(TagTest3, []byte("quux"))
+	tlvl.Protect()
+
+	tlvl.Set(TagTest1, []byte("gopher"))
+	tlvl.Set(TagTest4, []byte("new"))
+	want := "0=str:gopher, 1=str:bar, 2=str:quux, 3=str:new"
+	if got := tlvl.Spec(); got != want {
+		FailWithError(t, "TestTLVListSet", fmt.Errorf("got %s", got))
+	} else if err := tlvl.Verify(); err != nil {
+		FailWithError(t, "TestTLVListSet", err)
+	}
+}
+
+func TestTLVListUpsert(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(TagTest1, []byte("foo"))
+
+	if tlvl.Upsert(TagTest1, []byte("bar")) {
+		FailWithError(t, "TestTLVListUpsert", fmt.Errorf("replacement reported as insert"))
+	} else if !tlvl.Upsert(TagTest2, []byte("baz")) {
+		FailWithError(t, "TestTLVListUpsert", fmt.Errorf("insert reported as replacement"))
+	}
+
+	if rec := tlvl.GetOrAdd(TagTest1, []byte("default")); string(rec.Value()) != "bar" {
+		FailWithError(t, "TestTLVListUpsert", fmt.Errorf("existing record not returned"))
+	}
+	rec := tlvl.GetOrAdd(TagTest3, []byte("default"))
+	if got, err := tlvl.Get(TagTest3); err != nil || got != rec || string(rec.Value()) != "default" {
+		FailWithError(t, "TestTLVListUpsert", fmt.Errorf("default record not added"))
+	} else if tlvl.Length() != 3 {
+		FailWithError(t, "TestTLVListUpsert",
+			fmt.Errorf("expected 3 records, got %d", tlvl.Length()))
+	}
+}
+
+func TestTLVListIndex(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(TagTest1, []byte("a"))
+	tlvl.Add(TagTest1, []byte("b"))
+	tlvl.Add(TagTest1, []byte("c"))
+	tlvl.Protect()
+
+	if rec, err := tlvl.GetIndex(1); err != nil || string(rec.Value()) != "b" {
+		FailWithError(t, "TestTLVListIndex", noMatch)
+	}
+	if err := tlvl.SetIndex(1, newTLV(TagTest1, []byte("B"))); err != nil {
+		FailWithError(t, "TestTLVListIndex", err)
+	}
+	if err := tlvl.RemoveIndex(0); err != nil {
+		FailWithError(t, "TestTLVListIndex", err)
+	}
+	if got := tlvl.Spec(); got != "0=str:B, 0=str:c" {
+		FailWithError(t, "TestTLVListIndex", fmt.Errorf("got %s", got))
+	} else if err := tlvl.Verify(); err != nil {
+		FailWithError(t, "TestTLVListIndex", err)
+	}
+
+	for _, i := range []int{-1, 2} {
+		if _, err := tlvl.GetIndex(i); err != ErrIndexRange {
+			FailWithError(t, "TestTLVListIndex", fmt.Errorf("GetIndex(%d): %v", i, err))
+		} else if err = tlvl.SetIndex(i, nil); err != ErrIndexRange {
+			FailWithError(t, "TestTLVListIndex", fmt.Errorf("SetIndex(%d): %v", i, err))
+		} else if err = tlvl.RemoveIndex(i); err != ErrIndexRange {
+			FailWithError(t, "TestTLVListIndex", fmt.Errorf("RemoveIndex(%d): %v", i, err))
+		}
+	}
+}
+
+func benchmarkList(n int) *TLVList {
+	tlvl := New()
+	value := []byte("gophers are everywhere")
+	for i := 0; i < n; i++ {
+		tlvl.Add(i%16, value)
+	}
+	return tlvl
+}
+
+func BenchmarkTLVListAdd(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		benchmarkList(100000)
+	}
+}
+
+func BenchmarkTLVListGetAll(b *testing.B) {
+	tlvl := benchmarkList(100000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tlvl.GetAll(15)
+	}
+}
+
+// allocatedBy returns the number of bytes allocated while fn runs.
+func allocatedBy(fn func()) uint64 {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	fn()
+	runtime.ReadMemStats(&after)
+	return after.TotalAlloc - before.TotalAlloc
+}
+
+func TestReadValue(t *testing.T) {
+	long := make([]byte, 3*maxEagerValue)
+	for i := range long {
+		long[i] = byte(i)
+	}
+	for _, length := range []int{0, 10, maxEagerValue + 1, len(long)} {
+		value, err := readValue(bytes.NewReader(long), length)
+		if err != nil {
+			FailWithError(t, "TestReadValue", err)
+		} else if !bytes.Equal(value, long[:length]) {
+			FailWithError(t, "TestReadValue", noMatch)
+		}
+	}
+
+	// A huge claimed length costs no more than the input holds.
+	var err error
+	n := allocatedBy(func() {
+		_, err = readValue(bytes.NewReader(long), 1<<30)
+	})
+	if err != io.ErrUnexpectedEOF {
+		FailWithError(t, "TestReadValue",
+			fmt.Errorf("expected io.ErrUnexpectedEOF, got %v", err))
+	} else if n > 1<<20 {
+		FailWithError(t, "TestReadValue",
+			fmt.Errorf("%d bytes allocated for a short input", n))
+	}
+}
@@ -0,0 +1,42 @@
+package tlv
+
+import "encoding/binary"
+
+// TagTombstone is the tag of a tombstone record. In an append-only log,
+// a tombstone marks the deletion of every earlier record with the tag
+// it names, so deletions can be replicated by appending records rather
+// than by rewriting the log in place.
+const TagTombstone = 0x7fffff02
+
+// Tombstone returns a tombstone record deleting records with tag. The
+// value of the record is the tag as a 32-bit big-endian integer.
+func Tombstone(tag int) TLV {
+	var value [4]byte
+	binary.BigEndian.PutUint32(value[:], uint32(tag))
+	return newTLV(TagTombstone, value[:])
+}
+
+// IsTombstone reports whether rec is a tombstone, and if so, returns the
+// tag it deletes.
+func IsTombstone(rec TLV) (tag int, ok bool) {
+	if rec.Tag() != TagTombstone || rec.Length() != 4 {
+		return 0, false
+	}
+	return int(int32(binary.BigEndian.Uint32(rec.Value()))), true
+}
+
+// Compact applies the tombstones in the TLVList, returning a new list
+// holding the records that survive. Each tombstone removes the records
+// with its tag that precede it; records that follow it are kept. The
+// tombstones themselves are dropped, as are any records deleted by them.
+func (recs *TLVList) Compact() *TLVList {
+	live := New()
+	for _, tlv := range recs.records {
+		if tag, ok := IsTombstone(tlv); ok {
+			live.Remove(tag)
+			continue
+		}
+		live.AddRecord(tlv)
+	}
+	return live
+}
@@ -0,0 +1,49 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestTombstone(t *testing.T) {
+	log := New()
+	log.Add(TagTest1, []byte("foo bar"))
+	log.Add(TagTest2, []byte("baz quux"))
+	log.Add(TagTest1, []byte("goodbye, cruel world"))
+	log.AddRecord(Tombstone(TagTest1))
+	log.Add(TagTest1, []byte("hello again"))
+	log.AddRecord(Tombstone(TagTest3))
+
+	// Tombstones are ordinary records, so they survive replication.
+	buf := new(bytes.Buffer)
+	if err := log.Write(buf); err != nil {
+		FailWithError(t, "TestTombstone", err)
+	}
+	replica, err := Read(buf)
+	if err != nil {
+		FailWithError(t, "TestTombstone", err)
+	}
+
+	live := replica.Compact()
+	if live.Length() != 2 {
+		FailWithError(t, "TestTombstone",
+			fmt.Errorf("expected 2 live records, got %d", live.Length()))
+	}
+
+	tlvs := live.GetAll(TagTest1)
+	if len(tlvs) != 1 || string(tlvs[0].Value()) != "hello again" {
+		FailWithError(t, "TestTombstone", noMatch)
+	} else if _, err = live.Get(TagTombstone); err != ErrTagNotFound {
+		FailWithError(t, "TestTombstone",
+			fmt.Errorf("tombstones should be dropped"))
+	}
+
+	if tag, ok := IsTombstone(Tombstone(-2)); !ok || tag != -2 {
+		FailWithError(t, "TestTombstone",
+			fmt.Errorf("bad tombstone tag %d", tag))
+	} else if _, ok = IsTombstone(newTLV(TagTest1, nil)); ok {
+		FailWithError(t, "TestTombstone",
+			fmt.Errorf("ordinary record is not a tombstone"))
+	}
+}
@@ -0,0 +1,126 @@
+package tlv
+
+import (
+	"runtime"
+	"sync"
+)
+
+// TransformParallel decodes every record from src, applies fn to each,
+// and writes the results to dst in the order the records were read. The
+// calls to fn are spread over workers goroutines, or one per CPU if
+// workers is not positive, so fn must be safe for concurrent use; it is
+// meant for expensive per-record work, such as encrypting or compressing
+// the values of a large archive. If fn returns a nil record, the record
+// is dropped.
+//
+// The first error from decoding, from fn, or from encoding stops the
+// transformation and is returned. dst is not closed.
+func TransformParallel(dst *Encoder, src *Decoder, workers int, fn func(TLV) (TLV, error)) error {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	type result struct {
+		rec TLV
+		err error
+	}
+	type job struct {
+		rec TLV
+		out chan result
+	}
+
+	jobs := make(chan job)
+	order := make(chan chan result, 2*workers)
+	done := make(chan struct{})
+
+	// The reader hands each record to the workers and queues the
+	// channel its result will arrive on, so results are written in
+	// input order however long fn takes.
+	go func() {
+		defer close(order)
+		defer close(jobs)
+		for {
+			rec, err := src.Decode()
+			out := make(chan result, 1)
+			if err != nil {
+				if err = eof(err); err != nil {
+					out <- result{err: err}
+					select {
+					case order <- out:
+					case <-done:
+					}
+				}
+				return
+			}
+
+			select {
+			case order <- out:
+			case <-done:
+				return
+			}
+			select {
+			case jobs <- job{rec, out}:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				rec, err := fn(j.rec)
+				j.out <- result{rec, err}
+			}
+		}()
+	}
+
+	var err error
+	for out := range order {
+		res := <-out
+		if err = res.err; err == nil && res.rec != nil {
+			err = dst.Encode(res.rec)
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	close(done)
+	for range order {
+	}
+	wg.Wait()
+	return err
+}
+
+// Filter returns a new TLVList holding the records for which pred
+// returns true, in their original order. The records themselves are
+// shared with the original list, not copied.
+func (recs *TLVList) Filter(pred func(TLV) bool) *TLVList {
+	filtered := New()
+	for _, tlv := range recs.records {
+		if pred(tlv) {
+			filtered.records = append(filtered.records, tlv)
+		}
+	}
+	return filtered
+}
+
+// MapValues returns a new TLVList holding a record for each record in
+// the list, in order, with the same tag and the value fn returns for it.
+// The first error from fn stops the mapping and is returned.
+func (recs *TLVList) MapValues(fn func(TLV) ([]byte, error)) (*TLVList, error) {
+	mapped := New()
+	mapped.records = make([]TLV, 0, len(recs.records))
+	for _, tlv := range recs.records {
+		value, err := fn(tlv)
+		if err != nil {
+			return nil, err
+		}
+		mapped.records = append(mapped.records, newTLV(tlv.Tag(), value))
+	}
+	return mapped, nil
+}
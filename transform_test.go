@@ -0,0 +1,106 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestTransformParallel(t *testing.T) {
+	tlvl := New()
+	for i := 0; i < 100; i++ {
+		tlvl.Add(i, []byte(fmt.Sprintf("record %d", i)))
+	}
+	buf := new(bytes.Buffer)
+	if err := tlvl.Write(buf); err != nil {
+		FailWithError(t, "TestTransformParallel", err)
+	}
+	raw := buf.Bytes()
+
+	// Early records take longest, so they finish out of order.
+	out := new(bytes.Buffer)
+	err := TransformParallel(NewEncoder(out), NewDecoder(bytes.NewReader(raw)), 8,
+		func(rec TLV) (TLV, error) {
+			time.Sleep(time.Duration(100-rec.Tag()) * 10 * time.Microsecond)
+			if rec.Tag()%10 == 0 {
+				return nil, nil
+			}
+			return newTLV(rec.Tag(), bytes.ToUpper(rec.Value())), nil
+		})
+	if err != nil {
+		FailWithError(t, "TestTransformParallel", err)
+	}
+
+	dec := NewDecoder(out)
+	for i := 0; i < 100; i++ {
+		if i%10 == 0 {
+			continue
+		}
+		rec, err := dec.Decode()
+		if err != nil {
+			FailWithError(t, "TestTransformParallel", err)
+		} else if rec.Tag() != i || string(rec.Value()) != fmt.Sprintf("RECORD %d", i) {
+			FailWithError(t, "TestTransformParallel", noMatch)
+		}
+	}
+	if _, err = dec.Decode(); err != io.EOF {
+		FailWithError(t, "TestTransformParallel",
+			fmt.Errorf("expected EOF, got %v", err))
+	}
+
+	failed := fmt.Errorf("transform failed")
+	err = TransformParallel(NewEncoder(io.Discard), NewDecoder(bytes.NewReader(raw)), 4,
+		func(rec TLV) (TLV, error) {
+			if rec.Tag() == 50 {
+				return nil, failed
+			}
+			return rec, nil
+		})
+	if err != failed {
+		FailWithError(t, "TestTransformParallel",
+			fmt.Errorf("expected transform error, got %v", err))
+	}
+
+	err = TransformParallel(NewEncoder(io.Discard), NewDecoder(bytes.NewReader(raw[:20])), 4,
+		func(rec TLV) (TLV, error) { return rec, nil })
+	if err != ErrTLVRead {
+		FailWithError(t, "TestTransformParallel",
+			fmt.Errorf("expected ErrTLVRead, got %v", err))
+	}
+}
+
+func TestFilterMapValues(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(TagTest1, []byte("foo"))
+	tlvl.Add(TagTest2, []byte("bar"))
+	tlvl.Add(TagTest1, []byte("baz"))
+
+	odd := tlvl.Filter(func(rec TLV) bool { return rec.Tag() == TagTest1 })
+	want := New()
+	want.Add(TagTest1, []byte("foo"))
+	want.Add(TagTest1, []byte("baz"))
+	if !CompareLists(want, odd, CompareRules{}).Equal() || tlvl.Length() != 3 {
+		FailWithError(t, "TestFilterMapValues", noMatch)
+	}
+
+	upper, err := tlvl.MapValues(func(rec TLV) ([]byte, error) {
+		return bytes.ToUpper(rec.Value()), nil
+	})
+	if err != nil {
+		FailWithError(t, "TestFilterMapValues", err)
+	}
+	want = New()
+	want.Add(TagTest1, []byte("FOO"))
+	want.Add(TagTest2, []byte("BAR"))
+	want.Add(TagTest1, []byte("BAZ"))
+	if !CompareLists(want, upper, CompareRules{}).Equal() {
+		FailWithError(t, "TestFilterMapValues", noMatch)
+	}
+
+	stop := fmt.Errorf("stop")
+	if _, err = tlvl.MapValues(func(TLV) ([]byte, error) { return nil, stop }); err != stop {
+		FailWithError(t, "TestFilterMapValues", fmt.Errorf("expected stop, got %v", err))
+	}
+}
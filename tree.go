@@ -0,0 +1,200 @@
+package tlv
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Type Tree renders nested TLV structures, such as BER-TLV constructed
+// data objects, as a tree, either as indented text or as a Graphviz DOT
+// graph. Constructed records are always shown with their children.
+type Tree struct {
+	// Format is the format nested records are decoded in. If nil,
+	// Standard is used.
+	Format Format
+
+	// Nested reports whether a record's value holds further records.
+	// If nil, records are nested when Format is BER or DER and their
+	// tag is marked as constructed; in any other format, no records
+	// are nested. Values that fail to decode are shown as leaves.
+	Nested func(rec TLV) bool
+
+	// Registry, if set, supplies tag names and value renderers.
+	Registry *Registry
+
+	// MaxValue, if nonzero, is the longest rendered value shown in a
+	// text tree, in characters; longer values are cut short and end
+	// with an ellipsis.
+	MaxValue int
+
+	// Limits bounds the number of nested records decoded while
+	// rendering. If it is exceeded, rendering stops and the write
+	// returns ErrQueryLimit.
+	Limits QueryLimits
+}
+
+// berConstructed reports whether the first octet of a BER tag has the
+// constructed bit set.
+func berConstructed(tag int) bool {
+	for tag > 0xff {
+		tag >>= 8
+	}
+	return tag&berConstructedBit != 0
+}
+
+// children decodes the records nested in rec, or returns nil if rec is a
+// leaf.
+func (tr *Tree) children(rec TLV, budget *queryBudget) (*TLVList, error) {
+	if c, ok := rec.(Constructed); ok {
+		kids := c.Children()
+		return kids, budget.visit(kids.Length())
+	}
+
+	format := tr.Format
+	if format == nil {
+		format = Standard
+	}
+
+	nested := tr.Nested
+	if nested == nil {
+		if format != BER && format != DER {
+			return nil, nil
+		}
+		nested = func(rec TLV) bool { return berConstructed(rec.Tag()) }
+	}
+	if !nested(rec) {
+		return nil, nil
+	}
+
+	dec := NewDecoder(bytes.NewReader(rec.Value()))
+	dec.Format = format
+	if budget.limits.MaxVisited > 0 {
+		dec.MaxRecords = budget.limits.MaxVisited - budget.visited + 1
+	}
+	recs, err := dec.DecodeList()
+	if err == ErrTooLarge {
+		return nil, ErrQueryLimit
+	} else if err != nil {
+		return nil, nil
+	}
+	return recs, budget.visit(recs.Length())
+}
+
+func (tr *Tree) label(rec TLV) string {
+	label := fmt.Sprintf("0x%x", rec.Tag())
+	if tr.Registry != nil {
+		if name, ok := tr.Registry.Name(rec.Tag()); ok {
+			label += " " + name
+		}
+		if desc, ok := tr.Registry.Description(rec.Tag()); ok {
+			label += " (" + desc + ")"
+		}
+	}
+	return label
+}
+
+// WriteText writes the records in recs to w as an indented tree, one
+// record per line. Leaves are followed by their rendered values.
+func (tr *Tree) WriteText(w io.Writer, recs *TLVList) error {
+	bw := bufio.NewWriter(w)
+	budget := &queryBudget{limits: tr.Limits}
+	err := tr.writeText(bw, recs, "", budget)
+	if ferr := bw.Flush(); err == nil {
+		err = ferr
+	}
+	return err
+}
+
+func (tr *Tree) writeText(w *bufio.Writer, recs *TLVList, prefix string, budget *queryBudget) error {
+	for i, rec := range recs.records {
+		branch, indent := "├── ", "│   "
+		if i == recs.Length()-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		fmt.Fprintf(w, "%s%s%s (len %d)", prefix, branch, tr.label(rec), rec.Length())
+		kids, err := tr.children(rec, budget)
+		if err != nil {
+			return err
+		} else if kids != nil {
+			w.WriteByte('\n')
+			if err = tr.writeText(w, kids, prefix+indent, budget); err != nil {
+				return err
+			}
+			continue
+		}
+		fmt.Fprintf(w, ": %s\n", truncate(tr.Registry.Render(rec), tr.MaxValue))
+	}
+	return nil
+}
+
+// WriteDOT writes the records in recs to w as a Graphviz DOT graph, with
+// an edge from each constructed record to each of the records it holds.
+func (tr *Tree) WriteDOT(w io.Writer, recs *TLVList) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("digraph tlv {\n\tnode [shape=box];\n")
+	var next int
+	budget := &queryBudget{limits: tr.Limits}
+	if err := tr.writeDOT(bw, recs, -1, &next, budget); err != nil {
+		bw.Flush()
+		return err
+	}
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
+
+func (tr *Tree) writeDOT(w *bufio.Writer, recs *TLVList, parent int, next *int, budget *queryBudget) error {
+	for _, rec := range recs.records {
+		id := *next
+		*next++
+
+		label := fmt.Sprintf("%s\nlen %d", tr.label(rec), rec.Length())
+		kids, err := tr.children(rec, budget)
+		if err != nil {
+			return err
+		} else if kids == nil {
+			label += "\n" + truncate(tr.Registry.Render(rec), 32)
+		}
+		fmt.Fprintf(w, "\tn%d [label=%s];\n", id, strconv.Quote(label))
+		if parent >= 0 {
+			fmt.Fprintf(w, "\tn%d -> n%d;\n", parent, id)
+		}
+		if kids != nil {
+			if err = tr.writeDOT(w, kids, id, next, budget); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// truncate shortens s to at most max characters, ending it with an
+// ellipsis if anything was cut. A max of zero leaves s alone.
+func truncate(s string, max int) string {
+	if max <= 0 || utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	r := []rune(s)
+	if max <= 3 {
+		return string(r[:max])
+	}
+	return string(r[:max-3]) + "..."
+}
+
+// Dump writes recs to w as an indented tree, descending into
+// Constructed records, with hex values cut short after 32 characters.
+// It is meant for debugging.
+func (recs *TLVList) Dump(w io.Writer) error {
+	return (*Registry)(nil).Dump(w, recs)
+}
+
+// Dump is TLVList.Dump with tag names, descriptions and value renderers
+// taken from reg.
+func (reg *Registry) Dump(w io.Writer, recs *TLVList) error {
+	tr := &Tree{Registry: reg, MaxValue: 32}
+	return tr.WriteText(w, recs)
+}
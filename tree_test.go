@@ -0,0 +1,110 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTree(t *testing.T) {
+	fci := decodeFormat(t, "TestTree", BER, emvFCI)
+	reg := NewRegistry()
+	reg.Register(0x84, "df-name")
+	reg.SetRenderer(0x84, RenderString)
+
+	tr := &Tree{Format: BER, Registry: reg}
+	buf := new(bytes.Buffer)
+	if err := tr.WriteText(buf, fci); err != nil {
+		FailWithError(t, "TestTree", err)
+	}
+
+	want := strings.Join([]string{
+		"└── 0x6f (len 26)",
+		"    ├── 0x84 df-name (len 14): \"1PAY.SYS.DDF01\"",
+		"    └── 0xa5 (len 8)",
+		"        ├── 0x88 (len 1): 02",
+		"        └── 0x5f2d (len 2): 656e",
+		"",
+	}, "\n")
+	if buf.String() != want {
+		FailWithError(t, "TestTree",
+			fmt.Errorf("bad tree:\n%s", buf.String()))
+	}
+
+	buf.Reset()
+	if err := tr.WriteDOT(buf, fci); err != nil {
+		FailWithError(t, "TestTree", err)
+	}
+	dot := buf.String()
+	for _, edge := range []string{"n0 -> n1;", "n0 -> n2;", "n2 -> n3;", "n2 -> n4;"} {
+		if !strings.Contains(dot, edge) {
+			FailWithError(t, "TestTree",
+				fmt.Errorf("missing edge %s in:\n%s", edge, dot))
+		}
+	}
+
+	// Without a BER format, nothing is nested.
+	buf.Reset()
+	(&Tree{}).WriteText(buf, fci)
+	if strings.Count(buf.String(), "\n") != 1 {
+		FailWithError(t, "TestTree",
+			fmt.Errorf("expected a single leaf:\n%s", buf.String()))
+	}
+}
+
+func TestTreeLimits(t *testing.T) {
+	// Sixty SEQUENCEs, each holding the next.
+	value := []byte{0x04, 0x00}
+	for i := 0; i < 60; i++ {
+		value = append([]byte{0x30, byte(len(value))}, value...)
+	}
+	recs := decodeFormat(t, "TestTreeLimits", BER, value)
+
+	tr := &Tree{Format: BER, Limits: QueryLimits{MaxVisited: 10}}
+	if err := tr.WriteText(new(bytes.Buffer), recs); err != ErrQueryLimit {
+		FailWithError(t, "TestTreeLimits",
+			fmt.Errorf("expected ErrQueryLimit, got %v", err))
+	}
+	if err := tr.WriteDOT(new(bytes.Buffer), recs); err != ErrQueryLimit {
+		FailWithError(t, "TestTreeLimits",
+			fmt.Errorf("expected ErrQueryLimit, got %v", err))
+	}
+
+	tr.Limits = DefaultQueryLimits
+	if err := tr.WriteText(new(bytes.Buffer), recs); err != nil {
+		FailWithError(t, "TestTreeLimits", err)
+	}
+}
+
+func TestDump(t *testing.T) {
+	inner := New()
+	inner.Add(TagTest3, bytes.Repeat([]byte{0xab}, 20))
+	recs := New()
+	recs.Add(TagTest1, []byte{1, 2})
+	recs.AddList(TagTest2, inner)
+
+	buf := new(bytes.Buffer)
+	if err := recs.Dump(buf); err != nil {
+		FailWithError(t, "TestDump", err)
+	}
+	want := strings.Join([]string{
+		"├── 0x0 (len 2): 0102",
+		"└── 0x1 (len 28)",
+		"    └── 0x2 (len 20): ababababababababababababababa...",
+		"",
+	}, "\n")
+	if buf.String() != want {
+		FailWithError(t, "TestDump",
+			fmt.Errorf("bad dump:\n%s", buf.String()))
+	}
+
+	reg := NewRegistry()
+	reg.Register(TagTest1, "version")
+	buf.Reset()
+	if err := reg.Dump(buf, recs); err != nil {
+		FailWithError(t, "TestDump", err)
+	} else if !strings.HasPrefix(buf.String(), "├── 0x0 version (len 2)") {
+		FailWithError(t, "TestDump", noMatch)
+	}
+}
@@ -0,0 +1,87 @@
+package tlv
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// SetSize records that every value with the given tag is exactly size
+// bytes long, for use by the TV format. ErrLengthRange is returned if
+// size is negative.
+func (reg *Registry) SetSize(tag, size int) error {
+	if size < 0 {
+		return ErrLengthRange
+	}
+	if reg.sizes == nil {
+		reg.sizes = make(map[int]int)
+	}
+	reg.sizes[tag] = size
+	return nil
+}
+
+// Size returns the fixed size of values with the given tag, if one has
+// been set. A nil Registry has no sizes.
+func (reg *Registry) Size(tag int) (size int, ok bool) {
+	if reg == nil {
+		return 0, false
+	}
+	size, ok = reg.sizes[tag]
+	return
+}
+
+// Type TV is a tag-value format, in which a record's length is implied
+// by its tag and is never written, as in many firmware configuration
+// blobs. Each tag is written as a big-endian integer of TagSize bytes,
+// from one to four; if TagSize is zero, one-byte tags are used. The
+// length of each tag's values is looked up in Registry with Size.
+//
+// Records whose tags have no size are rejected with ErrUnknownTag, and
+// records whose length differs from their tag's size cannot be written
+// and are rejected with ErrLengthRange.
+type TV struct {
+	Registry *Registry
+	TagSize  int
+}
+
+func (f TV) tagSize() int {
+	if f.TagSize == 0 {
+		return 1
+	}
+	return f.TagSize
+}
+
+func (f TV) ReadHeader(r io.Reader) (tag, length int, err error) {
+	n := f.tagSize()
+	if n < 1 || n > 4 {
+		return 0, 0, ErrTagRange
+	}
+
+	var hdr [4]byte
+	if _, err = io.ReadFull(r, hdr[4-n:]); err != nil {
+		return
+	}
+	tag = int(binary.BigEndian.Uint32(hdr[:]))
+
+	length, ok := f.Registry.Size(tag)
+	if !ok {
+		return 0, 0, ErrUnknownTag
+	}
+	return
+}
+
+func (f TV) WriteHeader(w io.Writer, tag, length int) error {
+	n := f.tagSize()
+	if n < 1 || n > 4 || tag < 0 || uint64(tag) >= 1<<uint(8*n) {
+		return ErrTagRange
+	}
+	if size, ok := f.Registry.Size(tag); !ok {
+		return ErrUnknownTag
+	} else if size != length {
+		return ErrLengthRange
+	}
+
+	var hdr [4]byte
+	binary.BigEndian.PutUint32(hdr[:], uint32(tag))
+	_, err := w.Write(hdr[4-n:])
+	return err
+}
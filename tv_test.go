@@ -0,0 +1,50 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestTV(t *testing.T) {
+	reg := NewRegistry()
+	reg.SetSize(0x01, 2)
+	reg.SetSize(0x02, 0)
+	reg.SetSize(0x10, 4)
+
+	raw := []byte{0x10, 0xde, 0xad, 0xbe, 0xef, 0x02, 0x01, 0x12, 0x34}
+	recs := decodeFormat(t, "TestTV", TV{Registry: reg}, raw)
+	if recs.Spec() != "16=hex:deadbeef, 2=hex:, 1=hex:1234" {
+		FailWithError(t, "TestTV",
+			fmt.Errorf("decoded %s", recs.Spec()))
+	}
+	if out := encodeFormat(t, "TestTV", TV{Registry: reg}, recs); !bytes.Equal(out, raw) {
+		FailWithError(t, "TestTV", noMatch)
+	}
+
+	dec := NewDecoder(bytes.NewReader([]byte{0x03, 0x00}))
+	dec.Format = TV{Registry: reg}
+	if _, err := dec.Decode(); err != ErrUnknownTag {
+		FailWithError(t, "TestTV",
+			fmt.Errorf("expected ErrUnknownTag, got %v", err))
+	}
+
+	enc := NewEncoder(new(bytes.Buffer))
+	enc.Format = TV{Registry: reg, TagSize: 2}
+	if err := enc.Encode(newTLV(0x01, []byte{1, 2, 3})); err != ErrLengthRange {
+		FailWithError(t, "TestTV",
+			fmt.Errorf("expected ErrLengthRange, got %v", err))
+	}
+
+	// Sizes survive a round trip through a schema file.
+	buf := new(bytes.Buffer)
+	if err := reg.WriteSchema(buf); err != nil {
+		FailWithError(t, "TestTV", err)
+	}
+	reg2, err := ReadSchema(buf)
+	if err != nil {
+		FailWithError(t, "TestTV", err)
+	} else if size, ok := reg2.Size(0x02); !ok || size != 0 {
+		FailWithError(t, "TestTV", noMatch)
+	}
+}
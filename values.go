@@ -0,0 +1,94 @@
+package tlv
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"sort"
+)
+
+// Values and headers carry record values in unpadded, URL-safe base64 so
+// they can appear in query strings and header fields unescaped.
+var valueEncoding = base64.RawURLEncoding
+
+// Values converts the TLVList to url.Values, using reg to name each tag
+// and base64-encoding each value. Records sharing a tag keep their
+// relative order, but the order of records with different tags is not
+// preserved. If a record's tag has not been registered, ErrUnknownTag is
+// returned.
+func (recs *TLVList) Values(reg *Registry) (url.Values, error) {
+	v := make(url.Values)
+	for _, tlv := range recs.records {
+		name, ok := reg.Name(tlv.Tag())
+		if !ok {
+			return nil, ErrUnknownTag
+		}
+		v.Add(name, valueEncoding.EncodeToString(tlv.Value()))
+	}
+	return v, nil
+}
+
+// FromValues builds a TLVList from url.Values produced by Values. Keys
+// that have not been registered in reg are skipped. Records are ordered
+// by tag.
+func FromValues(v url.Values, reg *Registry) (*TLVList, error) {
+	fields := make(map[int][]string)
+	for name, values := range v {
+		if tag, ok := reg.Lookup(name); ok {
+			fields[tag] = values
+		}
+	}
+	return fromFields(fields)
+}
+
+// Header converts the TLVList to an http.Header in the same manner as
+// Values. Header names are canonicalized by http.Header, so registered
+// names should be valid header field names.
+func (recs *TLVList) Header(reg *Registry) (http.Header, error) {
+	v, err := recs.Values(reg)
+	if err != nil {
+		return nil, err
+	}
+
+	h := make(http.Header)
+	for name, values := range v {
+		for _, value := range values {
+			h.Add(name, value)
+		}
+	}
+	return h, nil
+}
+
+// FromHeader builds a TLVList from an http.Header produced by Header.
+// Registered names are matched against header names without regard to
+// case, and header fields that do not match a registered name are
+// skipped. Records are ordered by tag.
+func FromHeader(h http.Header, reg *Registry) (*TLVList, error) {
+	fields := make(map[int][]string)
+	for name, tag := range reg.tags {
+		if values := h[http.CanonicalHeaderKey(name)]; len(values) > 0 {
+			fields[tag] = values
+		}
+	}
+	return fromFields(fields)
+}
+
+func fromFields(fields map[int][]string) (*TLVList, error) {
+	tags := make([]int, 0, len(fields))
+	for tag := range fields {
+		tags = append(tags, tag)
+	}
+	sort.Ints(tags)
+
+	recs := New()
+	for _, tag := range tags {
+		for _, value := range fields[tag] {
+			b, err := valueEncoding.DecodeString(value)
+			if err != nil {
+				return nil, err
+			}
+			recs.Add(tag, b)
+		}
+	}
+	return recs, nil
+}
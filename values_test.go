@@ -0,0 +1,86 @@
+package tlv
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func valuesTestList() *TLVList {
+	tlvl := New()
+	tlvl.Add(TagTest2, []byte("baz quux"))
+	tlvl.Add(TagTest1, []byte("foo bar"))
+	tlvl.Add(TagTest1, []byte{0, 0xff, 0xfe})
+	return tlvl
+}
+
+func checkValuesList(t *testing.T, name string, tlvl *TLVList) {
+	if tlvl.Length() != 3 {
+		FailWithError(t, name,
+			fmt.Errorf("expected 3 records, got %d", tlvl.Length()))
+	}
+
+	tlvs := tlvl.GetAll(TagTest1)
+	if len(tlvs) != 2 {
+		FailWithError(t, name, noMatch)
+	} else if string(tlvs[0].Value()) != "foo bar" {
+		FailWithError(t, name, noMatch)
+	} else if !Equals(tlvs[1], newTLV(TagTest1, []byte{0, 0xff, 0xfe})) {
+		FailWithError(t, name, noMatch)
+	}
+}
+
+func TestValues(t *testing.T) {
+	reg := testRegistry()
+	v, err := valuesTestList().Values(reg)
+	if err != nil {
+		FailWithError(t, "TestValues", err)
+	} else if v.Get("test-two") != "YmF6IHF1dXg" {
+		FailWithError(t, "TestValues",
+			fmt.Errorf("bad encoded value %s", v.Get("test-two")))
+	}
+
+	v.Set("utm_source", "newsletter")
+	tlvl, err := FromValues(v, reg)
+	if err != nil {
+		FailWithError(t, "TestValues", err)
+	}
+	checkValuesList(t, "TestValues", tlvl)
+
+	v.Set("test-three", "not base64!")
+	if _, err = FromValues(v, reg); err == nil {
+		FailWithError(t, "TestValues",
+			fmt.Errorf("invalid value should fail"))
+	}
+}
+
+func TestHeader(t *testing.T) {
+	reg := testRegistry()
+	h, err := valuesTestList().Header(reg)
+	if err != nil {
+		FailWithError(t, "TestHeader", err)
+	} else if len(h["Test-One"]) != 2 {
+		FailWithError(t, "TestHeader",
+			fmt.Errorf("header names should be canonical"))
+	}
+
+	h.Set("Content-Type", "text/plain")
+	tlvl, err := FromHeader(h, reg)
+	if err != nil {
+		FailWithError(t, "TestHeader", err)
+	}
+	checkValuesList(t, "TestHeader", tlvl)
+
+	tlvl = New()
+	tlvl.Add(TagTest6, nil)
+	if _, err = tlvl.Header(reg); err != ErrUnknownTag {
+		FailWithError(t, "TestHeader",
+			fmt.Errorf("unregistered tag should fail"))
+	}
+
+	if tlvl, err = FromHeader(http.Header{}, reg); err != nil {
+		FailWithError(t, "TestHeader", err)
+	} else if tlvl.Length() != 0 {
+		FailWithError(t, "TestHeader", noMatch)
+	}
+}
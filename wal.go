@@ -0,0 +1,151 @@
+package tlv
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrLogClosed is returned when a record is appended to a closed
+// LogWriter.
+var ErrLogClosed = fmt.Errorf("TLV log is closed")
+
+// Type SyncWriter is a writer that can flush what has been written to
+// stable storage, such as an *os.File.
+type SyncWriter interface {
+	io.Writer
+	Sync() error
+}
+
+// Type LogWriter appends records to a write-ahead log with group commit:
+// rather than syncing after every record, a background goroutine syncs
+// the log whenever records are waiting, so every record appended while a
+// sync is in progress shares the next one. Each record may carry a
+// durability callback, invoked once the record is on stable storage, so
+// producers can acknowledge upstream only after the record is persisted.
+//
+// The first failed write or sync is permanent. Once a sync has failed,
+// it is unknown which records reached storage, and a later sync that
+// succeeds does not mean they did; so every later Append, callback and
+// Close reports the first error, and the log is not synced again.
+//
+// A LogWriter is safe for use from multiple goroutines.
+type LogWriter struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	w       SyncWriter
+	enc     *Encoder
+	pending []func(error)
+	closed  bool
+	err     error
+	done    chan error
+
+	// delay is how long the syncing goroutine waits for further
+	// records before syncing a batch.
+	delay time.Duration
+}
+
+// NewLogWriter returns a LogWriter appending records to w. If delay is
+// positive, each sync waits that long for more records to join the
+// batch, trading latency for fewer syncs.
+func NewLogWriter(w SyncWriter, delay time.Duration) *LogWriter {
+	lw := &LogWriter{w: w, enc: NewEncoder(w), done: make(chan error, 1), delay: delay}
+	lw.cond = sync.NewCond(&lw.mu)
+	go lw.syncLoop()
+	return lw
+}
+
+// OpenLog opens the log file at path for appending, creating it if
+// necessary, and returns a LogWriter for it. Closing the LogWriter
+// closes the file.
+func OpenLog(path string, delay time.Duration) (*LogWriter, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	lw := NewLogWriter(file, delay)
+	lw.enc.closer = file
+	return lw, nil
+}
+
+// Append writes a record to the log. If durable is not nil, it is called
+// from the syncing goroutine once the record has been synced, with the
+// error from the sync, if any. Callbacks are invoked in the order their
+// records were appended, and must not block for long.
+func (lw *LogWriter) Append(rec TLV, durable func(err error)) error {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	if lw.closed {
+		return ErrLogClosed
+	} else if lw.err != nil {
+		return lw.err
+	} else if err := lw.enc.Encode(rec); err != nil {
+		lw.err = err
+		return err
+	}
+
+	if durable == nil {
+		durable = func(error) {}
+	}
+	lw.pending = append(lw.pending, durable)
+	lw.cond.Signal()
+	return nil
+}
+
+func (lw *LogWriter) syncLoop() {
+	for {
+		lw.mu.Lock()
+		for len(lw.pending) == 0 && !lw.closed {
+			lw.cond.Wait()
+		}
+		if len(lw.pending) == 0 {
+			err := lw.err
+			lw.mu.Unlock()
+			lw.done <- err
+			return
+		}
+		if lw.delay > 0 && !lw.closed {
+			lw.mu.Unlock()
+			time.Sleep(lw.delay)
+			lw.mu.Lock()
+		}
+		batch := lw.pending
+		lw.pending = nil
+		err := lw.err
+		lw.mu.Unlock()
+
+		// Every record in the batch was written before it was taken,
+		// so this sync covers them all.
+		if err == nil {
+			if err = lw.w.Sync(); err != nil {
+				lw.mu.Lock()
+				lw.err = err
+				lw.mu.Unlock()
+			}
+		}
+		for _, durable := range batch {
+			durable(err)
+		}
+	}
+}
+
+// Close syncs any records still waiting, invokes their callbacks, and
+// closes the log. It returns the first write or sync error, if any.
+func (lw *LogWriter) Close() error {
+	lw.mu.Lock()
+	if lw.closed {
+		lw.mu.Unlock()
+		return ErrLogClosed
+	}
+	lw.closed = true
+	lw.cond.Signal()
+	lw.mu.Unlock()
+
+	err := <-lw.done
+	if cerr := lw.enc.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
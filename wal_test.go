@@ -0,0 +1,160 @@
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// countingSyncer is an in-memory SyncWriter that counts syncs, and
+// records how many bytes had been written at the last one.
+type countingSyncer struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	syncs  int
+	synced int
+}
+
+func (cs *countingSyncer) Write(p []byte) (int, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return cs.buf.Write(p)
+}
+
+func (cs *countingSyncer) Sync() error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.syncs++
+	cs.synced = cs.buf.Len()
+	return nil
+}
+
+func TestLogWriter(t *testing.T) {
+	cs := new(countingSyncer)
+	lw := NewLogWriter(cs, 0)
+
+	const n = 200
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var acked int
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			rec := newTLV(TagTest1, []byte(fmt.Sprint(i)))
+			err := lw.Append(rec, func(err error) {
+				if err != nil {
+					FailWithError(t, "TestLogWriter", err)
+				}
+				mu.Lock()
+				acked++
+				mu.Unlock()
+				wg.Done()
+			})
+			if err != nil {
+				FailWithError(t, "TestLogWriter", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if err := lw.Close(); err != nil {
+		FailWithError(t, "TestLogWriter", err)
+	} else if acked != n {
+		FailWithError(t, "TestLogWriter",
+			fmt.Errorf("expected %d acknowledgements, got %d", n, acked))
+	} else if cs.synced != cs.buf.Len() {
+		FailWithError(t, "TestLogWriter",
+			fmt.Errorf("records were acknowledged before being synced"))
+	} else if cs.syncs > n {
+		FailWithError(t, "TestLogWriter",
+			fmt.Errorf("%d syncs for %d records", cs.syncs, n))
+	}
+
+	recs, err := NewDecoder(&cs.buf).DecodeList()
+	if err != nil {
+		FailWithError(t, "TestLogWriter", err)
+	} else if recs.Length() != n {
+		FailWithError(t, "TestLogWriter", noMatch)
+	}
+
+	if err = lw.Append(newTLV(TagTest1, nil), nil); err != ErrLogClosed {
+		FailWithError(t, "TestLogWriter",
+			fmt.Errorf("expected ErrLogClosed, got %v", err))
+	}
+}
+
+func TestOpenLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wal.tlv")
+	for i := 0; i < 2; i++ {
+		lw, err := OpenLog(path, 0)
+		if err != nil {
+			FailWithError(t, "TestOpenLog", err)
+		}
+		durable := make(chan error, 1)
+		if err = lw.Append(newTLV(TagTest1+i, nil), func(err error) { durable <- err }); err != nil {
+			FailWithError(t, "TestOpenLog", err)
+		} else if err = <-durable; err != nil {
+			FailWithError(t, "TestOpenLog", err)
+		} else if err = lw.Close(); err != nil {
+			FailWithError(t, "TestOpenLog", err)
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		FailWithError(t, "TestOpenLog", err)
+	}
+	defer file.Close()
+	recs, err := NewDecoder(file).DecodeList()
+	if err != nil {
+		FailWithError(t, "TestOpenLog", err)
+	}
+	checkTags(t, "TestOpenLog", recs, TagTest1, TagTest2)
+}
+
+// failingSyncer is a SyncWriter whose first sync fails.
+type failingSyncer struct {
+	countingSyncer
+	failed bool
+}
+
+var errSyncFailed = fmt.Errorf("sync failed")
+
+func (fs *failingSyncer) Sync() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.syncs++
+	if !fs.failed {
+		fs.failed = true
+		return errSyncFailed
+	}
+	return nil
+}
+
+func TestLogWriterSyncError(t *testing.T) {
+	fs := new(failingSyncer)
+	lw := NewLogWriter(fs, 0)
+
+	durable := make(chan error, 1)
+	if err := lw.Append(newTLV(TagTest1, nil), func(err error) { durable <- err }); err != nil {
+		FailWithError(t, "TestLogWriterSyncError", err)
+	} else if err = <-durable; err != errSyncFailed {
+		FailWithError(t, "TestLogWriterSyncError",
+			fmt.Errorf("expected the sync error, got %v", err))
+	}
+
+	// The failure sticks, even though the next sync would succeed.
+	if err := lw.Append(newTLV(TagTest2, nil), nil); err != errSyncFailed {
+		FailWithError(t, "TestLogWriterSyncError",
+			fmt.Errorf("expected the sync error, got %v", err))
+	} else if err = lw.Close(); err != errSyncFailed {
+		FailWithError(t, "TestLogWriterSyncError",
+			fmt.Errorf("expected the sync error, got %v", err))
+	} else if fs.syncs != 1 {
+		FailWithError(t, "TestLogWriterSyncError",
+			fmt.Errorf("log was synced %d times", fs.syncs))
+	}
+}
@@ -0,0 +1,83 @@
+package tlv
+
+import "fmt"
+
+// SkipChildren may be returned by a pre-order walk function to prune
+// the subtree below the current record: its children are not visited,
+// and the walk carries on with the next sibling. It is not returned by
+// Walk.
+var SkipChildren = fmt.Errorf("skip children")
+
+// Type WalkOrder selects when a walk visits a record relative to its
+// children.
+type WalkOrder int
+
+const (
+	// PreOrder visits a record before its children.
+	PreOrder WalkOrder = iota
+
+	// PostOrder visits a record after its children. Subtrees cannot
+	// be pruned in a post-order walk.
+	PostOrder
+)
+
+// Type Walker walks nested records, calling a function for each one.
+// Only Constructed records are descended into; use NewConstructed or a
+// Decoder with Nested set to build them.
+type Walker struct {
+	// Order selects pre- or post-order traversal.
+	Order WalkOrder
+
+	// Limits bounds the number of records visited. If it is
+	// exceeded, the walk stops and returns ErrQueryLimit.
+	Limits QueryLimits
+}
+
+// Walk calls fn for every record in recs and, in turn, their children,
+// in pre-order. See Walker.Walk.
+func (recs *TLVList) Walk(fn func(path []int, rec TLV) error) error {
+	return Walker{}.Walk(recs, fn)
+}
+
+// Walk calls fn for every record in recs and, in turn, their children.
+// The path holds the position of each record on the way down from recs,
+// ending with rec's own position in its parent; it is reused between
+// calls, so fn must copy it to keep it. If fn returns SkipChildren in
+// a pre-order walk, rec's children are skipped; any other error stops
+// the walk and is returned.
+func (w Walker) Walk(recs *TLVList, fn func(path []int, rec TLV) error) error {
+	budget := &queryBudget{limits: w.Limits}
+	return w.walk(recs, make([]int, 0, 8), budget, fn)
+}
+
+// walk visits recs, whose position is given by path.
+func (w Walker) walk(recs *TLVList, path []int, budget *queryBudget, fn func([]int, TLV) error) error {
+	for i, rec := range recs.records {
+		if err := budget.visit(1); err != nil {
+			return err
+		}
+		here := append(path, i)
+
+		descend := true
+		if w.Order == PreOrder {
+			if err := fn(here, rec); err == SkipChildren {
+				descend = false
+			} else if err != nil {
+				return err
+			}
+		}
+
+		if c, ok := rec.(Constructed); ok && descend {
+			if err := w.walk(c.Children(), here, budget, fn); err != nil {
+				return err
+			}
+		}
+
+		if w.Order == PostOrder {
+			if err := fn(here, rec); err != nil && err != SkipChildren {
+				return err
+			}
+		}
+	}
+	return nil
+}
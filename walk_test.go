@@ -0,0 +1,70 @@
+package tlv
+
+import (
+	"fmt"
+	"testing"
+)
+
+func walkTree() *TLVList {
+	inner := New()
+	inner.Add(TagTest3, []byte{3})
+	inner.Add(TagTest4, []byte{4})
+	recs := New()
+	recs.Add(TagTest1, []byte{1})
+	recs.AddList(TagTest2, inner)
+	recs.Add(TagTest5, []byte{5})
+	return recs
+}
+
+func walkTrace(recs *TLVList, w Walker, prune int) ([]string, error) {
+	var trace []string
+	err := w.Walk(recs, func(path []int, rec TLV) error {
+		trace = append(trace, fmt.Sprintf("%v:%d", path, rec.Tag()))
+		if rec.Tag() == prune {
+			return SkipChildren
+		}
+		return nil
+	})
+	return trace, err
+}
+
+func TestWalk(t *testing.T) {
+	recs := walkTree()
+	tests := []struct {
+		order WalkOrder
+		prune int
+		want  string
+	}{
+		{PreOrder, -1, "[[0]:0 [1]:1 [1 0]:2 [1 1]:3 [2]:4]"},
+		{PostOrder, -1, "[[0]:0 [1 0]:2 [1 1]:3 [1]:1 [2]:4]"},
+		{PreOrder, TagTest2, "[[0]:0 [1]:1 [2]:4]"},
+		{PostOrder, TagTest2, "[[0]:0 [1 0]:2 [1 1]:3 [1]:1 [2]:4]"},
+	}
+	for _, test := range tests {
+		trace, err := walkTrace(recs, Walker{Order: test.order}, test.prune)
+		if err != nil {
+			FailWithError(t, "TestWalk", err)
+		} else if fmt.Sprint(trace) != test.want {
+			FailWithError(t, "TestWalk",
+				fmt.Errorf("order %d: got %v", test.order, trace))
+		}
+	}
+
+	stop := fmt.Errorf("stop")
+	n := 0
+	err := recs.Walk(func(path []int, rec TLV) error {
+		n++
+		if len(path) == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || n != 3 {
+		FailWithError(t, "TestWalk", fmt.Errorf("walk did not stop: %v after %d", err, n))
+	}
+
+	_, err = walkTrace(recs, Walker{Limits: QueryLimits{MaxVisited: 3}}, -1)
+	if err != ErrQueryLimit {
+		FailWithError(t, "TestWalk", fmt.Errorf("expected ErrQueryLimit, got %v", err))
+	}
+}
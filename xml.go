@@ -0,0 +1,69 @@
+package tlv
+
+import (
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// ErrXML is returned when an XML record's length attribute does not
+// match its value.
+var ErrXML = fmt.Errorf("invalid XML TLV record")
+
+// xmlRecord is the XML form of a record, with its value in hex.
+type xmlRecord struct {
+	XMLName xml.Name `xml:"record"`
+	Tag     int      `xml:"tag,attr"`
+	Length  int      `xml:"length,attr"`
+	Value   string   `xml:",chardata"`
+}
+
+func toXMLRecord(tlv TLV) xmlRecord {
+	return xmlRecord{Tag: tlv.Tag(), Length: tlv.Length(), Value: hex.EncodeToString(tlv.Value())}
+}
+
+// MarshalXML encodes a record as a record element, such as
+// <record tag="1" length="2">6869</record>.
+func (t *record) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.Encode(toXMLRecord(t))
+}
+
+// MarshalXML encodes the TLVList as an element holding a record element
+// for each record, in list order.
+func (recs *TLVList) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, tlv := range recs.records {
+		if err := e.Encode(toXMLRecord(tlv)); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML replaces the contents of the TLVList with the record
+// elements held by an element written by MarshalXML. Whitespace in the
+// hex values is ignored, and other elements are skipped.
+func (recs *TLVList) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var in struct {
+		Records []xmlRecord `xml:"record"`
+	}
+	if err := d.DecodeElement(&in, &start); err != nil {
+		return err
+	}
+
+	decoded := New()
+	for _, xr := range in.Records {
+		value, err := hex.DecodeString(strings.Join(strings.Fields(xr.Value), ""))
+		if err != nil {
+			return err
+		} else if len(value) != xr.Length {
+			return ErrXML
+		}
+		decoded.Add(xr.Tag, value)
+	}
+	*recs = *decoded
+	return nil
+}
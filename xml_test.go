@@ -0,0 +1,54 @@
+package tlv
+
+import (
+	"encoding/xml"
+	"fmt"
+	"testing"
+)
+
+func TestXML(t *testing.T) {
+	tlvl := New()
+	tlvl.Add(TagTest2, []byte("hi"))
+	tlvl.Add(TagTest3, nil)
+	tlvl.Add(TagTest2, []byte{0xff})
+
+	out, err := xml.Marshal(tlvl)
+	if err != nil {
+		FailWithError(t, "TestXML", err)
+	}
+	want := `<TLVList><record tag="1" length="2">6869</record>` +
+		`<record tag="2" length="0"></record><record tag="1" length="1">ff</record></TLVList>`
+	if string(out) != want {
+		FailWithError(t, "TestXML",
+			fmt.Errorf("bad XML %s", out))
+	}
+
+	rtlvl := New()
+	if err = xml.Unmarshal(out, rtlvl); err != nil {
+		FailWithError(t, "TestXML", err)
+	}
+	checkTags(t, "TestXML", rtlvl, TagTest2, TagTest3, TagTest2)
+	if rec, _ := rtlvl.Get(TagTest2); string(rec.Value()) != "hi" {
+		FailWithError(t, "TestXML", noMatch)
+	}
+
+	var config struct {
+		Payload *TLVList `xml:"payload"`
+	}
+	in := `<config><payload>
+		<record tag="5" length="4">
+			dead beef
+		</record>
+	</payload></config>`
+	if err = xml.Unmarshal([]byte(in), &config); err != nil {
+		FailWithError(t, "TestXML", err)
+	} else if rec, err := config.Payload.Get(5); err != nil || rec.Length() != 4 {
+		FailWithError(t, "TestXML", noMatch)
+	}
+
+	bad := `<list><record tag="1" length="3">6869</record></list>`
+	if err = xml.Unmarshal([]byte(bad), New()); err != ErrXML {
+		FailWithError(t, "TestXML",
+			fmt.Errorf("length mismatch should fail"))
+	}
+}